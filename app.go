@@ -8,8 +8,14 @@ import (
 
 	"spacebrowser/internal/fileicon"
 	"spacebrowser/internal/platform"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// eventEmitter sends a named event to the frontend, which is the Wails
+// window or the browsers of --serve.
+type eventEmitter func(name string, data ...any)
+
 type App struct {
 	ctx                 context.Context
 	initialScanPath     string
@@ -21,10 +27,21 @@ type App struct {
 	showFreeSpace       bool
 	profile             Profile
 	settingsPath        string
+	policy              systemPolicy
+	overrides           sessionOverrides
 	defaultSettingsPath string
 	settingsMu          sync.RWMutex
 	iconServiceOnce     sync.Once
 	iconService         *fileicon.Service
+	basketMu            sync.Mutex
+	undoMu              sync.Mutex
+	quarantineMu        sync.Mutex
+	auditMu             sync.Mutex
+	trashUndo           [][]TrashedItem
+	// trashInfo caches Trash metadata for Layout by item path until the
+	// tree or the Trash changes.
+	trashInfoMu sync.Mutex
+	trashInfo   map[string]trashItemInfo
 
 	scanMu         sync.RWMutex
 	scanGeneration uint64
@@ -33,18 +50,35 @@ type App struct {
 	scanCancel     context.CancelFunc
 	scanStartedAt  time.Time
 	scanScanner    *Scanner
+	// lastScan describes the scan that produced the current tree.
+	lastScan scanReportDetails
 }
 
 func NewApp() *App {
-	return newAppWithLogger(NewSeverityLogger(defaultVerbosity, os.Stderr))
+	return newAppWithLogger(NewSeverityLogger(defaultVerbosity, os.Stderr), "")
 }
 
-func newAppWithLogger(logger *SeverityLogger) *App {
+// newAppWithLogger loads the configured settings, or those at settingsPath
+// when it is not empty.
+func newAppWithLogger(logger *SeverityLogger, settingsPath string) *App {
 	defaultPath, err := defaultSettingsPath()
 	if err != nil {
 		logger.Warningf("could not determine the default settings location: %v", err)
 	}
-	return newAppWithPathsAndLogger(configuredSettingsPath(defaultPath), defaultPath, logger)
+	if settingsPath == "" {
+		settingsPath = configuredSettingsPath(defaultPath)
+	}
+	app := newAppWithPathsAndLogger(settingsPath, defaultPath, logger)
+	policyPath := systemPolicyPath(app.profile.PlatformSystem, os.Getenv)
+	policy, err := loadSystemPolicy(policyPath, platform.Impl)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warningf("%v; delete commands are disabled", err)
+	}
+	if policy.active() {
+		app.setSystemPolicy(policy)
+		logger.Infof("applied system policy %s", policyPath)
+	}
+	return app
 }
 
 func newApp(settingsPath string) *App {
@@ -77,7 +111,7 @@ func newAppWithDependencies(settingsPath, defaultPath string, logger *SeverityLo
 			logger.Warningf("could not load settings from %s: %v; using defaults", settingsPath, err)
 		}
 	}
-	return &App{
+	app := &App{
 		showFreeSpace:       true,
 		profile:             profile,
 		settingsPath:        settingsPath,
@@ -87,11 +121,16 @@ func newAppWithDependencies(settingsPath, defaultPath string, logger *SeverityLo
 		desktop:             desktop,
 		locations:           locations,
 	}
+	app.store.SetProtection(newPathProtection(profile))
+	return app
 }
 
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 	a.logger.Debugf("application runtime initialized")
+	go a.watchSettingsFile(ctx, settingsWatchInterval, func(name string, data ...any) {
+		runtime.EventsEmit(ctx, name, data...)
+	})
 }
 
 func (a *App) Shutdown(context.Context) {
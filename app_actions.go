@@ -15,8 +15,8 @@ import (
 
 func (a *App) DeleteNode(nodeID int) (DeleteResult, error) {
 	profile := a.GetProfile()
-	if !profile.AllowDelete {
-		return DeleteResult{}, fmt.Errorf("delete commands are disabled; enable Allow delete command in Settings")
+	if err := a.deleteAllowed(profile); err != nil {
+		return DeleteResult{}, err
 	}
 
 	a.scanMu.RLock()
@@ -28,8 +28,19 @@ func (a *App) DeleteNode(nodeID int) (DeleteResult, error) {
 	var result DeleteResult
 	var err error
 	if a.store.NodePathMatches(nodeID, a.desktop.IsTrashRoot) {
-		result, err = a.store.EmptyTrashNode(nodeID, a.desktop.IsTrashRoot, a.desktop.EmptyTrash)
+		if profile.DeleteStrategy == deleteStrategyQuarantine {
+			return DeleteResult{}, errQuarantineForbidsPermanentDeletion
+		}
+		sizes := a.store.NodeSizes([]int{nodeID})
+		result, err = a.store.EmptyTrashNode(nodeID, a.desktop.IsTrashRoot, func(path string) error {
+			err := a.desktop.EmptyTrash(path)
+			a.audit(auditEntry(auditActionEmptyTrash, path, sizes[path], "", err))
+			return err
+		})
 	} else if a.store.NodePathMatches(nodeID, a.desktop.IsInTrash) {
+		if profile.DeleteStrategy == deleteStrategyQuarantine {
+			return DeleteResult{}, errQuarantineForbidsPermanentDeletion
+		}
 		if !profile.AllowPermanentDelete {
 			return DeleteResult{}, fmt.Errorf("permanent deletion is disabled; enable Allow permanent deletion in Settings")
 		}
@@ -37,17 +48,103 @@ func (a *App) DeleteNode(nodeID int) (DeleteResult, error) {
 		if pathErr != nil {
 			return DeleteResult{}, pathErr
 		}
-		if err = a.desktop.DeleteTrashItemPermanently(path); err == nil {
+		if a.store.Protects(path) {
+			return DeleteResult{}, errProtectedPath
+		}
+		size := a.auditedSize(path)
+		err = a.desktop.DeleteTrashItemPermanently(path)
+		a.audit(auditEntry(auditActionDeletePermanently, path, size, "", err))
+		if err == nil {
 			files, dirs := a.store.Counts()
 			result = DeleteResult{FileCount: files, DirCount: dirs, RescanRequired: true}
 		}
 	} else {
-		result, err = a.store.DeleteNode(nodeID, a.desktop.IsTrashRoot, a.desktop.IsInTrash, a.desktop.MoveToTrash)
+		remove, removalErr := a.beginRemoval(profile, a.store.NodeSizes([]int{nodeID}))
+		if removalErr != nil {
+			return DeleteResult{}, removalErr
+		}
+		result, err = a.store.DeleteNode(nodeID, remove.isTrashRoot, remove.isInTrash, remove.move)
+		remove.finish(&result)
 	}
 	if err != nil {
 		return DeleteResult{}, err
 	}
+	return a.completeDeletion(profile, result), nil
+}
+
+// DeleteNodes moves a multi-selection to Trash in one batch. Items inside
+// Trash are rejected; permanent deletion stays a single-item action.
+func (a *App) DeleteNodes(nodeIDs []int) (DeleteResult, error) {
+	profile := a.GetProfile()
+	if err := a.deleteAllowed(profile); err != nil {
+		return DeleteResult{}, err
+	}
+
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be deleted while a scan is running")
+	}
+
+	remove, err := a.beginRemoval(profile, a.store.NodeSizes(nodeIDs))
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	result, err := a.store.DeleteNodes(nodeIDs, remove.isTrashRoot, remove.isInTrash, remove.move)
+	remove.finish(&result)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	return a.completeDeletion(profile, result), nil
+}
+
+// GetSelectionInfo validates a multi-selection for DeleteNodes and returns
+// its item count, total size and paths for the confirmation dialog.
+func (a *App) GetSelectionInfo(nodeIDs []int) (SelectionInfo, error) {
+	return a.store.Selection(nodeIDs, a.desktop.IsTrashRoot, a.desktop.IsInTrash)
+}
+
+// DeleteSmallFile moves one file recorded in the [Small Files] aggregate of
+// folderID to Trash.
+func (a *App) DeleteSmallFile(folderID int, name string) (DeleteResult, error) {
+	profile := a.GetProfile()
+	if err := a.deleteAllowed(profile); err != nil {
+		return DeleteResult{}, err
+	}
+
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be deleted while a scan is running")
+	}
+	sizes := make(map[string]int64)
+	if entries, err := a.store.SmallFiles(folderID); err == nil {
+		for _, entry := range entries {
+			sizes[entry.Path] = entry.Size
+		}
+	}
+	remove, err := a.beginRemoval(profile, sizes)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	result, err := a.store.DeleteSmallFile(folderID, name, remove.isTrashRoot, remove.isInTrash, remove.move)
+	remove.finish(&result)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	return a.completeDeletion(profile, result), nil
+}
+
+// GetSmallFiles expands the [Small Files] aggregate of folderID.
+func (a *App) GetSmallFiles(folderID int) ([]SmallFileInfo, error) {
+	return a.store.SmallFiles(folderID)
+}
+
+// completeDeletion refreshes displayed Trash folders and the free-space node
+// after a successful deletion has been applied to the tree store.
+func (a *App) completeDeletion(profile Profile, result DeleteResult) DeleteResult {
 	if len(result.trashRefreshes) > 0 {
+		a.forgetTrashInfo()
 		if profile.RescanOnDelete || result.RescanRequired {
 			// The frontend will perform a full scan, so avoid scanning displayed
 			// Trash subtrees only to discard those results immediately afterward.
@@ -57,12 +154,13 @@ func (a *App) DeleteNode(nodeID int) (DeleteResult, error) {
 		}
 	}
 	a.refreshDiskUsageAfterFilesystemChange(&result)
-	return result, nil
+	result.UndoCount = a.trashUndoCount()
+	return result
 }
 
 func (a *App) refreshDisplayedTrash(result DeleteResult) DeleteResult {
 	requiresFullRescan := result.RescanRequired
-	profile := a.GetProfile()
+	profile := a.treeScanSettings()
 	ctx := a.ctx
 	if ctx == nil {
 		ctx = context.Background()
@@ -132,7 +230,14 @@ func (a *App) RestoreNode(nodeID int) (DeleteResult, error) {
 	if !a.desktop.IsInTrash(path) || a.desktop.IsTrashRoot(path) {
 		return DeleteResult{}, fmt.Errorf("the selected item is not restorable Trash content")
 	}
-	if err := a.desktop.RestoreTrashItem(path); err != nil {
+	originalPath := path
+	if info, err := a.desktop.TrashRestoreInfo(path); err == nil && info.OriginalPath != "" {
+		originalPath = info.OriginalPath
+	}
+	size := a.auditedSize(path)
+	err = a.desktop.RestoreTrashItem(path)
+	a.audit(auditEntry(auditActionRestore, originalPath, size, path, err))
+	if err != nil {
 		return DeleteResult{}, err
 	}
 	files, dirs := a.store.Counts()
@@ -147,10 +252,10 @@ func (a *App) refreshDiskUsageAfterFilesystemChange(result *DeleteResult) {
 		if usageErr != nil {
 			result.RescanRequired = true
 			if a.logger != nil {
-				a.logger.Warningf("could not refresh disk usage after deletion: %v", usageErr)
+				a.logger.Warningf("could not refresh disk usage: %v", usageErr)
 			}
 		} else {
-			a.store.UpdateDiskUsage(int64(usage.Total), int64(usage.Free))
+			a.store.UpdateDiskUsage(int64(usage.Total), int64(usage.Free), diskReservedSpace(usage))
 		}
 	}
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+func (a *App) cleanupBasketFile() (string, error) {
+	settingsPath := a.GetSettingsPath()
+	if settingsPath == "" {
+		return "", fmt.Errorf("the cleanup basket needs a settings location")
+	}
+	return cleanupBasketPath(settingsPath), nil
+}
+
+// GetCleanupBasket returns the queued cleanup items with sizes refreshed from
+// the current scan where possible.
+func (a *App) GetCleanupBasket() (CleanupBasket, error) {
+	a.basketMu.Lock()
+	defer a.basketMu.Unlock()
+	path, err := a.cleanupBasketFile()
+	if err != nil {
+		return CleanupBasket{}, err
+	}
+	items, err := loadCleanupBasket(path)
+	if err != nil {
+		return CleanupBasket{}, err
+	}
+	return newCleanupBasket(a.store.RefreshCleanupItems(items)), nil
+}
+
+// AddToCleanupBasket queues the selected items for a reviewed cleanup.
+func (a *App) AddToCleanupBasket(nodeIDs []int) (CleanupBasket, error) {
+	added, err := a.store.CleanupItems(nodeIDs, a.desktop.IsTrashRoot, time.Now())
+	if err != nil {
+		return CleanupBasket{}, err
+	}
+	return a.updateCleanupBasket(func(items []CleanupBasketItem) ([]CleanupBasketItem, error) {
+		return addCleanupBasketItems(items, added)
+	})
+}
+
+// RemoveFromCleanupBasket drops the given paths from the cleanup basket.
+func (a *App) RemoveFromCleanupBasket(paths []string) (CleanupBasket, error) {
+	return a.updateCleanupBasket(func(items []CleanupBasketItem) ([]CleanupBasketItem, error) {
+		return removeCleanupBasketItems(items, paths), nil
+	})
+}
+
+// ClearCleanupBasket empties the cleanup basket without touching any file.
+func (a *App) ClearCleanupBasket() (CleanupBasket, error) {
+	return a.updateCleanupBasket(func([]CleanupBasketItem) ([]CleanupBasketItem, error) {
+		return nil, nil
+	})
+}
+
+func (a *App) updateCleanupBasket(update func([]CleanupBasketItem) ([]CleanupBasketItem, error)) (CleanupBasket, error) {
+	a.basketMu.Lock()
+	defer a.basketMu.Unlock()
+	path, err := a.cleanupBasketFile()
+	if err != nil {
+		return CleanupBasket{}, err
+	}
+	items, err := loadCleanupBasket(path)
+	if err != nil {
+		return CleanupBasket{}, err
+	}
+	if items, err = update(items); err != nil {
+		return CleanupBasket{}, err
+	}
+	if err := saveCleanupBasket(path, items); err != nil {
+		return CleanupBasket{}, err
+	}
+	return newCleanupBasket(a.store.RefreshCleanupItems(items)), nil
+}
+
+// cleanupBasketExport renders the cleanup basket as a "script" or "json"
+// plan and returns it with the default file name for the save dialog.
+func (a *App) cleanupBasketExport(format string, now time.Time) ([]byte, string, error) {
+	basket, err := a.GetCleanupBasket()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(basket.Items) == 0 {
+		return nil, "", fmt.Errorf("the cleanup basket is empty")
+	}
+	plan := newCleanupPlan(basket.Items, a.GetProfile(), a.desktop.IsInTrash, now)
+	stamp := now.Format("20060102-150405")
+	switch format {
+	case "script":
+		return []byte(renderCleanupScript(plan)), "cleanup-" + stamp + ".sh", nil
+	case "json":
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("encode cleanup plan: %w", err)
+		}
+		return append(data, '\n'), "cleanup-" + stamp + ".json", nil
+	default:
+		return nil, "", fmt.Errorf("unknown cleanup plan format %q", format)
+	}
+}
+
+// ExportCleanupBasket saves the cleanup basket as a shell script or JSON plan
+// chosen by the user and returns the written path, or "" when cancelled.
+func (a *App) ExportCleanupBasket(format string) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	data, filename, err := a.cleanupBasketExport(format, time.Now())
+	if err != nil {
+		return "", err
+	}
+	filter := runtime.FileFilter{DisplayName: "Shell scripts (*.sh)", Pattern: "*.sh"}
+	if format == "json" {
+		filter = runtime.FileFilter{DisplayName: "JSON files (*.json)", Pattern: "*.json"}
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Export cleanup plan",
+		DefaultFilename:      filename,
+		CanCreateDirectories: true,
+		Filters:              []runtime.FileFilter{filter},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("write cleanup plan: %w", err)
+	}
+	return filepath.Clean(path), nil
+}
+
+// ExecuteCleanupBasket applies the cleanup basket under the same policies as
+// the delete commands: items are moved to Trash, and items already in Trash
+// are deleted permanently only when that is allowed. Items that succeed leave
+// the basket; failed items stay queued and are reported in the result.
+func (a *App) ExecuteCleanupBasket() (DeleteResult, error) {
+	profile := a.GetProfile()
+	if err := a.deleteAllowed(profile); err != nil {
+		return DeleteResult{}, err
+	}
+
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be deleted while a scan is running")
+	}
+
+	a.basketMu.Lock()
+	defer a.basketMu.Unlock()
+	path, err := a.cleanupBasketFile()
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	items, err := loadCleanupBasket(path)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	if len(items) == 0 {
+		return DeleteResult{}, fmt.Errorf("the cleanup basket is empty")
+	}
+
+	outcomes := make(map[string]DeleteItemResult, len(items))
+	var trashIDs []int
+	var permanentPaths []string
+	for _, item := range items {
+		nodeID, err := a.store.CleanupNodeID(item.Path)
+		switch {
+		case err != nil:
+			outcomes[item.Path] = DeleteItemResult{NodeID: -1, Path: item.Path, Error: err.Error()}
+		case a.store.Protects(item.Path):
+			outcomes[item.Path] = DeleteItemResult{NodeID: nodeID, Path: item.Path, Error: errProtectedPath.Error()}
+		case a.desktop.IsInTrash(item.Path):
+			if profile.DeleteStrategy == deleteStrategyQuarantine {
+				outcomes[item.Path] = DeleteItemResult{NodeID: nodeID, Path: item.Path, Error: errQuarantineForbidsPermanentDeletion.Error()}
+			} else if !profile.AllowPermanentDelete {
+				outcomes[item.Path] = DeleteItemResult{NodeID: nodeID, Path: item.Path, Error: "permanent deletion is disabled; enable Allow permanent deletion in Settings"}
+			} else {
+				outcomes[item.Path] = DeleteItemResult{NodeID: nodeID, Path: item.Path}
+				permanentPaths = append(permanentPaths, item.Path)
+			}
+		default:
+			trashIDs = append(trashIDs, nodeID)
+		}
+	}
+
+	var result DeleteResult
+	if len(trashIDs) > 0 {
+		remove, err := a.beginRemoval(profile, a.store.NodeSizes(trashIDs))
+		if err != nil {
+			return DeleteResult{}, err
+		}
+		result, err = a.store.DeleteNodes(trashIDs, remove.isTrashRoot, remove.isInTrash, remove.move)
+		remove.finish(&result)
+		if err != nil {
+			return DeleteResult{}, err
+		}
+		for _, outcome := range result.Items {
+			outcomes[outcome.Path] = outcome
+		}
+	} else {
+		result.FileCount, result.DirCount = a.store.Counts()
+	}
+	for _, itemPath := range permanentPaths {
+		outcome := outcomes[itemPath]
+		size := a.auditedSize(itemPath)
+		err := a.desktop.DeleteTrashItemPermanently(itemPath)
+		a.audit(auditEntry(auditActionDeletePermanently, itemPath, size, "", err))
+		if err != nil {
+			outcome.Error = err.Error()
+		} else {
+			result.RescanRequired = true
+		}
+		outcomes[itemPath] = outcome
+	}
+
+	result.Items = make([]DeleteItemResult, len(items))
+	var remaining []CleanupBasketItem
+	for index, item := range items {
+		result.Items[index] = outcomes[item.Path]
+		if result.Items[index].Error != "" {
+			remaining = append(remaining, item)
+		}
+	}
+	if err := saveCleanupBasket(path, remaining); err != nil && a.logger != nil {
+		a.logger.Warningf("could not update the cleanup basket after cleanup: %v", err)
+	}
+	return a.completeDeletion(profile, result), nil
+}
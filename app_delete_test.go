@@ -541,7 +541,7 @@ func TestTreeStoreUpdatesFreeSpaceAccounting(t *testing.T) {
 	if !ok || path != root.FullPath {
 		t.Fatalf("DiskUsageRootPath() = %q, %t; want %q, true", path, ok, root.FullPath)
 	}
-	if !store.UpdateDiskUsage(1400, 800) {
+	if !store.UpdateDiskUsage(1400, 800, 0) {
 		t.Fatal("UpdateDiskUsage() did not find the free-space node")
 	}
 	if root.DiskTotal != 1400 || root.DiskFree != 800 || free.DiskTotal != 1400 || free.Size != 800 {
@@ -552,6 +552,31 @@ func TestTreeStoreUpdatesFreeSpaceAccounting(t *testing.T) {
 	}
 }
 
+func TestTreeStoreRecomputesUnaccountedSpace(t *testing.T) {
+	root := &Node{ID: 0, ParentID: -1, FullPath: t.TempDir(), Size: 600, IsFolder: true, DiskTotal: 1000, DiskFree: 300}
+	used := &Node{ID: 1, ParentID: 0, Name: "used", FullPath: filepath.Join(root.FullPath, "used"), Size: 600}
+	free := &Node{ID: -1, ParentID: 0, Name: "free", Size: 300, IsFreeSpace: true, DiskTotal: 1000}
+	unaccounted := &Node{ID: -1, ParentID: 0, Name: "unaccounted", Size: 100, IsUnaccounted: true, ReservedSpace: 40}
+	root.Children = []*Node{used, free, unaccounted}
+	store := &TreeStore{root: root, nodes: []*Node{root, used}, fileCount: 1, dirCount: 1}
+
+	if err := os.WriteFile(used.FullPath, []byte("used"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.DeleteNode(used.ID, nil, nil, func(string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !store.UpdateDiskUsage(1000, 700, 60) {
+		t.Fatal("UpdateDiskUsage() did not find the free-space node")
+	}
+	if unaccounted.Size != 300 || unaccounted.ReservedSpace != 60 {
+		t.Fatalf("unaccounted space = %d bytes with %d reserved, want 300 with 60", unaccounted.Size, unaccounted.ReservedSpace)
+	}
+	if !store.UpdateDiskUsage(1000, 1200, 0) || unaccounted.Size != 0 {
+		t.Fatalf("unaccounted space with free above total = %d, want 0", unaccounted.Size)
+	}
+}
+
 func TestAppsOwnIndependentTreeStores(t *testing.T) {
 	root := &Node{ID: 0, ParentID: -1, Name: "first", IsFolder: true}
 	first := &App{}
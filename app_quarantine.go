@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+var errQuarantineForbidsPermanentDeletion = errors.New("emptying Trash and permanent deletion are disabled by the quarantine delete strategy")
+
+// removal describes how delete commands classify protected folders and remove
+// items under the profile's delete strategy. finish must be called with the
+// result once the delete command has completed; it also writes an audit entry
+// for every attempted move, using sizes to report item sizes by path.
+type removal struct {
+	isTrashRoot func(string) bool
+	isInTrash   func(string) bool
+	move        func(string) error
+	finish      func(*DeleteResult)
+}
+
+func (a *App) beginRemoval(profile Profile, sizes map[string]int64) (removal, error) {
+	var audited []AuditEntry
+	if profile.DeleteStrategy != deleteStrategyQuarantine {
+		moveToTrash, trashed := a.recordingMoveToTrash()
+		return removal{
+			isTrashRoot: a.desktop.IsTrashRoot,
+			isInTrash:   a.desktop.IsInTrash,
+			move: func(path string) error {
+				recorded := len(*trashed)
+				err := moveToTrash(path)
+				trashPath := ""
+				if len(*trashed) > recorded {
+					trashPath = (*trashed)[recorded].TrashPath
+				}
+				audited = append(audited, auditEntry(auditActionTrash, path, sizes[path], trashPath, err))
+				return err
+			},
+			finish: func(*DeleteResult) {
+				a.pushTrashUndo(*trashed)
+				a.audit(audited...)
+			},
+		}, nil
+	}
+	q, err := a.openQuarantine(profile)
+	if err != nil {
+		return removal{}, err
+	}
+	a.purgeExpiredQuarantine(q)
+	// The quarantine folder is protected like a Trash root, and refreshed like
+	// one when it is part of the scanned tree.
+	return removal{
+		isTrashRoot: func(path string) bool { return filepath.Clean(path) == q.dir || a.desktop.IsTrashRoot(path) },
+		isInTrash:   func(path string) bool { return pathWithin(filepath.Clean(path), q.dir) || a.desktop.IsInTrash(path) },
+		move: func(path string) error {
+			a.quarantineMu.Lock()
+			entry, err := q.move(path)
+			a.quarantineMu.Unlock()
+			storedPath := ""
+			if err == nil {
+				storedPath = q.storedPath(entry)
+			}
+			audited = append(audited, auditEntry(auditActionQuarantine, path, sizes[path], storedPath, err))
+			return err
+		},
+		finish: func(result *DeleteResult) {
+			a.audit(audited...)
+			for _, target := range result.trashRefreshes {
+				if target.Path == q.dir {
+					return
+				}
+			}
+			result.trashRefreshes = append(result.trashRefreshes, a.store.DisplayedFolder(q.dir)...)
+		},
+	}, nil
+}
+
+// openQuarantine opens the quarantine folder of profile with the retention
+// set by the system policy.
+func (a *App) openQuarantine(profile Profile) (quarantine, error) {
+	a.settingsMu.RLock()
+	policy := a.policy
+	a.settingsMu.RUnlock()
+	return newQuarantine(profile, policy, a.filesystem)
+}
+
+func (a *App) purgeExpiredQuarantine(q quarantine) {
+	a.quarantineMu.Lock()
+	purged, err := q.purgeExpired()
+	a.quarantineMu.Unlock()
+	expired := make([]AuditEntry, len(purged))
+	for index, entry := range purged {
+		expired[index] = auditEntry(auditActionExpire, entry.OriginalPath, entry.Size, q.storedPath(entry), nil)
+	}
+	a.audit(expired...)
+	if a.logger == nil {
+		return
+	}
+	for _, entry := range purged {
+		a.logger.Infof("removed %s from quarantine after %d days", entry.OriginalPath, q.retentionDays)
+	}
+	if err != nil {
+		a.logger.Warningf("could not enforce quarantine retention: %v", err)
+	}
+}
+
+// QuarantineItem is one quarantined item as listed by GetQuarantine.
+// ExpiresAt is a Unix time, or zero when items are kept until restored.
+type QuarantineItem struct {
+	QuarantineEntry
+	StoredPath string `json:"storedPath"`
+	ExpiresAt  int64  `json:"expiresAt,omitempty"`
+}
+
+// GetQuarantine lists the quarantined items after removing those whose
+// retention period has elapsed.
+func (a *App) GetQuarantine() ([]QuarantineItem, error) {
+	q, err := a.openQuarantine(a.GetProfile())
+	if err != nil {
+		return nil, err
+	}
+	a.purgeExpiredQuarantine(q)
+	a.quarantineMu.Lock()
+	entries, err := q.load()
+	a.quarantineMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]QuarantineItem, len(entries))
+	for index, entry := range entries {
+		items[index] = QuarantineItem{QuarantineEntry: entry, StoredPath: q.storedPath(entry)}
+		if expires := q.expiresAt(entry); !expires.IsZero() {
+			items[index].ExpiresAt = expires.Unix()
+		}
+	}
+	return items, nil
+}
+
+// RestoreQuarantinedItems moves quarantined items back to their original
+// locations and inserts them into the tree without a full rescan.
+func (a *App) RestoreQuarantinedItems(ids []string) (DeleteResult, error) {
+	if len(ids) == 0 {
+		return DeleteResult{}, fmt.Errorf("no quarantined items were selected")
+	}
+	profile := a.GetProfile()
+	q, err := a.openQuarantine(profile)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be restored while a scan is running")
+	}
+
+	var result DeleteResult
+	result.FileCount, result.DirCount = a.store.Counts()
+	result.Items = make([]DeleteItemResult, len(ids))
+	restored := 0
+	for index, id := range ids {
+		result.Items[index] = DeleteItemResult{NodeID: -1, Path: id}
+		a.quarantineMu.Lock()
+		entry, err := q.restore(id)
+		a.quarantineMu.Unlock()
+		if entry.OriginalPath != "" {
+			result.Items[index].Path = entry.OriginalPath
+		}
+		if err != nil && entry.OriginalPath == "" {
+			result.Items[index].Error = err.Error()
+			a.audit(auditEntry(auditActionRestore, id, 0, "", err))
+			continue
+		}
+		a.audit(auditEntry(auditActionRestore, entry.OriginalPath, entry.Size, q.storedPath(entry), nil))
+		if err != nil && a.logger != nil {
+			a.logger.Warningf("restored %s but could not update the quarantine manifest: %v", entry.OriginalPath, err)
+		}
+		restored++
+		inserted, err := a.insertRestoredPath(entry.OriginalPath)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warningf("targeted refresh failed for restored %s: %v", entry.OriginalPath, err)
+			}
+			result.RescanRequired = true
+			continue
+		}
+		result.FileCount, result.DirCount = inserted.FileCount, inserted.DirCount
+		result.RescanRequired = result.RescanRequired || inserted.RescanRequired
+	}
+	if restored == 0 {
+		return DeleteResult{}, fmt.Errorf("%s: %s", result.Items[0].Path, result.Items[0].Error)
+	}
+	result.trashRefreshes = a.store.DisplayedFolder(q.dir)
+	return a.completeDeletion(profile, result), nil
+}
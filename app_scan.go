@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -15,10 +16,12 @@ import (
 )
 
 type TreeInfo struct {
-	RootID     int             `json:"rootId"`
-	FileCount  int             `json:"fileCount"`
-	DirCount   int             `json:"dirCount"`
-	ScanReport *ScanReportInfo `json:"scanReport,omitempty"`
+	RootID    int `json:"rootId"`
+	FileCount int `json:"fileCount"`
+	DirCount  int `json:"dirCount"`
+	// ScanProfile names the scan profile selected for the root.
+	ScanProfile string          `json:"scanProfile,omitempty"`
+	ScanReport  *ScanReportInfo `json:"scanReport,omitempty"`
 }
 
 type ScanProgress struct {
@@ -64,7 +67,7 @@ func (a *App) validateScanPath(path string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if a.GetProfile().SkipNetworkFS && a.filesystem.IsLikelyNetworkFS(path) {
+	if profile, _ := a.scanSettingsFor(path); profile.SkipNetworkFS && a.filesystem.IsLikelyNetworkFS(path) {
 		return "", errors.New(networkFilesystemScanDisabledMessage)
 	}
 	return path, nil
@@ -183,6 +186,7 @@ func (a *App) publishScanResult(
 	}
 
 	a.store.Replace(root, nodes, files, dirs)
+	a.forgetTrashInfo()
 	a.scanMu.Unlock()
 	return persistReport(), nil
 }
@@ -196,9 +200,16 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 	startedAt := time.Now()
 	a.logger.Infof("scan started: %s", path)
 
+	profile, scanProfile := a.scanSettingsFor(path)
 	var volumeUsage *disk.UsageStat
+	volumePath := ""
 	if a.filesystem.IsMountRoot(path) {
-		if fs, usageErr := disk.Usage(path); usageErr == nil {
+		volumePath = path
+	} else if profile.ShowVolumeContext {
+		volumePath = containingMountRoot(path, a.filesystem)
+	}
+	if volumePath != "" {
+		if fs, usageErr := disk.Usage(volumePath); usageErr == nil {
 			volumeUsage = fs
 		}
 	}
@@ -206,7 +217,7 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 	ctx, generation := a.beginScan(path)
 	defer a.finishScan(generation)
 
-	profile := a.GetProfile()
+	a.logger.Infof("scan profile: %s", scanProfile)
 	a.logger.Debugf("scan settings: skipHidden=%t minFileSize=%d followSymlinks=%t skipNetworkFS=%t", profile.SkipHidden, profile.MinFileSize, profile.FollowSymlinks, profile.SkipNetworkFS)
 	var files, dirs int64
 	scanner := NewScannerWithFilesystem(&profile, 0, a.filesystem)
@@ -229,6 +240,7 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 		return &TreeInfo{RootID: -1, FileCount: -1, DirCount: -1}, err
 	}
 
+	report := scanner.Report()
 	if volumeUsage != nil {
 		fs := volumeUsage
 		free := &Node{
@@ -241,7 +253,25 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 			IsFreeSpace: true,
 			Depth:       1,
 		}
-		root.Children = append(root.Children, free)
+		unaccounted := &Node{
+			ID:             -1,
+			ParentID:       root.ID,
+			Name:           "[Unaccounted Space]",
+			Size:           unaccountedSpace(int64(fs.Total), int64(fs.Free), volumeScannedSize(root)),
+			IsUnaccounted:  true,
+			Depth:          1,
+			ReservedSpace:  diskReservedSpace(fs),
+			ScanErrorCount: report.TotalErrors(),
+			SkippedCount:   report.TotalSkipped(),
+		}
+		if volumePath != path {
+			// Outside a mount root the remaining used space is mostly the rest
+			// of the volume rather than space the scan failed to attribute.
+			unaccounted.Name = "[Rest of Volume]"
+			unaccounted.DiskPath = volumePath
+			root.DiskPath = volumePath
+		}
+		root.Children = append(root.Children, free, unaccounted)
 
 		root.DiskTotal = int64(fs.Total)
 		root.DiskFree = int64(fs.Free)
@@ -251,10 +281,23 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 		return root.Children[i].Size > root.Children[j].Size
 	})
 
-	report := scanner.Report()
 	duration := time.Since(startedAt)
 	reportInfo, err := a.publishScanResult(ctx, generation, root, scanner.Nodes(), int(files), int(dirs), func() *ScanReportInfo {
-		return a.persistScanReport(path, startedAt, duration, profile, report, files, dirs, root.Size)
+		a.scanMu.Lock()
+		a.lastScan = scanReportDetails{
+			RootPath:    path,
+			StartedAt:   startedAt,
+			Duration:    duration,
+			Profile:     profile,
+			ScanProfile: scanProfile,
+			Overrides:   a.sessionOverrideSources(),
+			Report:      report,
+			Files:       files,
+			Folders:     dirs,
+			Bytes:       root.Size,
+		}
+		a.scanMu.Unlock()
+		return a.persistScanReport(path, startedAt, duration, profile, scanProfile, report, files, dirs, root.Size)
 	})
 	if err != nil {
 		if errors.Is(err, errScanSuperseded) {
@@ -266,7 +309,29 @@ func (a *App) GetFullTree(path string) (*TreeInfo, error) {
 	}
 	a.logScanReport(report)
 	a.logger.Infof("scan completed in %s: %s (%d files, %d folders, %d bytes)", duration.Round(time.Millisecond), path, files, dirs, root.Size)
-	return &TreeInfo{RootID: root.ID, FileCount: int(files), DirCount: int(dirs), ScanReport: reportInfo}, nil
+	return &TreeInfo{RootID: root.ID, FileCount: int(files), DirCount: int(dirs), ScanProfile: scanProfile, ScanReport: reportInfo}, nil
+}
+
+// containingMountRoot walks up from path to the nearest mount root. Paths
+// without a detectable mount root are treated as their own volume.
+func containingMountRoot(path string, filesystem platform.ScannerFilesystem) string {
+	for current := filepath.Clean(path); ; {
+		if filesystem.IsMountRoot(current) {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		current = parent
+	}
+}
+
+// diskReservedSpace returns the blocks statfs reports as free but reserved
+// for privileged use: gopsutil derives Used from f_bfree and Free from
+// f_bavail, so the remainder is exactly the reserved area.
+func diskReservedSpace(usage *disk.UsageStat) int64 {
+	return max(0, int64(usage.Total)-int64(usage.Free)-int64(usage.Used))
 }
 
 func (a *App) logScanReport(report ScanReportSnapshot) {
@@ -318,5 +383,128 @@ func (a *App) Layout(nodeID, width, height int, scale float64) ([]Rect, error) {
 		}
 		inTrashByNodeID[rect.NodeID] = rect.IsInTrash
 	}
+	if a.desktop != nil {
+		a.annotateTrashRects(rects)
+	}
 	return rects, nil
 }
+
+// annotateTrashRects attaches the original path and deletion date recorded by
+// the Trash to top-level Trash items, and the deletion date to everything
+// inside them so Trash contents can be colored by age. A top-level item is
+// one whose grandparent is a Trash root, such as Trash/files/<item> or
+// $Recycle.Bin/<SID>/<item>.
+func (a *App) annotateTrashRects(rects []Rect) {
+	trashRoots := make(map[string]bool)
+	isTrashRoot := func(path string) bool {
+		root, ok := trashRoots[path]
+		if !ok {
+			root = a.desktop.IsTrashRoot(path)
+			trashRoots[path] = root
+		}
+		return root
+	}
+	// Deletion dates by node ID for rects inside a top-level Trash item; zero
+	// when the item's metadata could not be read.
+	deletedByNodeID := make(map[int]int64)
+	for index := range rects {
+		rect := &rects[index]
+		if !rect.IsInTrash || rect.IsTrashRoot || rect.FullPath == "" {
+			continue
+		}
+		if rect.ParentID != nil {
+			if deleted, ok := deletedByNodeID[*rect.ParentID]; ok {
+				rect.TrashDeletedAt = deleted
+				deletedByNodeID[rect.NodeID] = deleted
+				continue
+			}
+		}
+		item := ""
+		for current := rect.FullPath; ; current = filepath.Dir(current) {
+			parent := filepath.Dir(current)
+			if filepath.Dir(parent) == parent {
+				break
+			}
+			if isTrashRoot(filepath.Dir(parent)) {
+				item = current
+				break
+			}
+		}
+		if item == "" {
+			continue
+		}
+		info := a.trashItemInfo(item)
+		rect.TrashDeletedAt = info.deletedAt
+		if item == rect.FullPath {
+			rect.TrashOriginalPath = info.originalPath
+		}
+		deletedByNodeID[rect.NodeID] = rect.TrashDeletedAt
+	}
+}
+
+// trashItemInfo is the Trash metadata annotateTrashRects shows for one item;
+// it is empty when the metadata could not be read.
+type trashItemInfo struct {
+	originalPath string
+	deletedAt    int64
+}
+
+// trashItemInfo reads the metadata of the Trash item at path once and serves
+// later layouts from the cache.
+func (a *App) trashItemInfo(path string) trashItemInfo {
+	a.trashInfoMu.Lock()
+	defer a.trashInfoMu.Unlock()
+	if cached, ok := a.trashInfo[path]; ok {
+		return cached
+	}
+	var item trashItemInfo
+	if info, err := a.desktop.TrashRestoreInfo(path); err == nil {
+		item.originalPath = info.OriginalPath
+		if !info.DeletedAt.IsZero() {
+			item.deletedAt = info.DeletedAt.Unix()
+		}
+	}
+	if a.trashInfo == nil {
+		a.trashInfo = make(map[string]trashItemInfo)
+	}
+	a.trashInfo[path] = item
+	return item
+}
+
+// forgetTrashInfo drops the cached Trash metadata after a scan or a change
+// to the Trash.
+func (a *App) forgetTrashInfo() {
+	a.trashInfoMu.Lock()
+	a.trashInfo = nil
+	a.trashInfoMu.Unlock()
+}
+
+// GetLargestItems reports the largest files or folders below nodeID. kind is
+// "files" or "folders".
+func (a *App) GetLargestItems(nodeID, limit int, kind string) ([]LargestItem, error) {
+	return a.store.Largest(nodeID, limit, kind)
+}
+
+// SetMountCollapsed collapses or expands the filesystem mounted at nodeID.
+func (a *App) SetMountCollapsed(nodeID int, collapsed bool) error {
+	return a.store.SetMountCollapsed(nodeID, collapsed)
+}
+
+// ExcludeMount drops the filesystem mounted at nodeID from the current tree
+// without rescanning.
+func (a *App) ExcludeMount(nodeID int) (DeleteResult, error) {
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("mounts cannot be excluded while a scan is running")
+	}
+	result, err := a.store.ExcludeMount(nodeID)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	// The excluded filesystem was never counted toward this volume, so the
+	// rest of the volume keeps its size. Free space is refreshed as after
+	// other tree changes, since it may have moved while the tree was shown.
+	a.refreshDiskUsageAfterFilesystemChange(&result)
+	return result, nil
+}
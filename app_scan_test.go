@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"spacebrowser/internal/platform"
+)
+
+// writeFixtureFiles creates files of the given sizes below dir. Names use
+// forward slashes and may include folders.
+func writeFixtureFiles(t *testing.T, dir string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scanFixture scans a temporary folder holding files with a fresh App and
+// returns the App, the scanned tree and the folder.
+func scanFixture(t *testing.T, files map[string]int, minFileSize int64) (*App, *TreeInfo, string) {
+	t.Helper()
+	scanPath := t.TempDir()
+	writeFixtureFiles(t, scanPath, files)
+	app := newApp(filepath.Join(t.TempDir(), "settings.json"))
+	app.profile.MinFileSize = minFileSize
+	tree, err := app.GetFullTree(scanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, tree, scanPath
+}
+
+type fixedMountPlatform struct {
+	platform.API
+	mountRoot string
+}
+
+func (p fixedMountPlatform) IsMountRoot(path string) bool {
+	return filepath.Clean(path) == filepath.Clean(p.mountRoot)
+}
+
+func TestContainingMountRootWalksUpToNearestMount(t *testing.T) {
+	volume := t.TempDir()
+	filesystem := fixedMountPlatform{API: platform.Impl, mountRoot: volume}
+	if got := containingMountRoot(filepath.Join(volume, "a", "b"), filesystem); got != volume {
+		t.Fatalf("containingMountRoot() = %q, want %q", got, volume)
+	}
+	unmounted := fixedMountPlatform{API: platform.Impl}
+	path := filepath.Join(volume, "a")
+	if got := containingMountRoot(path, unmounted); got != path {
+		t.Fatalf("containingMountRoot() without a mount = %q, want %q", got, path)
+	}
+}
+
+func TestFolderScanShowsContainingVolumeContext(t *testing.T) {
+	volume := t.TempDir()
+	scanPath := filepath.Join(volume, "projects")
+	if err := os.MkdirAll(scanPath, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scanPath, "data.bin"), make([]byte, 8192), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	filesystem := fixedMountPlatform{API: platform.Impl, mountRoot: volume}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), filesystem, nil, nil)
+	app.profile.SkipNetworkFS = false
+
+	tree, err := app.GetFullTree(scanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootPath, ok := app.store.DiskUsageRootPath(); ok {
+		t.Fatalf("folder scan without volume context reported disk usage root %q", rootPath)
+	}
+
+	app.profile.ShowVolumeContext = true
+	if tree, err = app.GetFullTree(scanPath); err != nil {
+		t.Fatal(err)
+	}
+	if rootPath, ok := app.store.DiskUsageRootPath(); !ok || rootPath != volume {
+		t.Fatalf("DiskUsageRootPath() = %q, %t; want %q, true", rootPath, ok, volume)
+	}
+	var free, rest *Node
+	for _, child := range app.store.root.Children {
+		if child.IsFreeSpace {
+			free = child
+		} else if child.IsUnaccounted {
+			rest = child
+		}
+	}
+	if free == nil || rest == nil || rest.Name != "[Rest of Volume]" || rest.DiskPath != volume {
+		t.Fatalf("volume context nodes = free %+v, rest %+v", free, rest)
+	}
+
+	rects, err := app.store.Layout(tree.RootID, 400, 300, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rect := range rects {
+		if rect.IsFree || rect.IsUnaccounted {
+			t.Fatalf("hidden free space still laid out %q", rect.Name)
+		}
+	}
+}
+
+func TestExcludeMountRefreshesDiskUsage(t *testing.T) {
+	rootPath := t.TempDir()
+	root := &Node{ID: 0, ParentID: -1, FullPath: rootPath, Size: 1000, IsFolder: true, DiskTotal: 1, DiskFree: 1}
+	home := &Node{ID: 1, ParentID: 0, Name: "home", FullPath: filepath.Join(rootPath, "home"), Size: 200}
+	srv := &Node{ID: 2, ParentID: 0, Name: "srv", FullPath: filepath.Join(rootPath, "srv"), Size: 800, IsFolder: true, Mount: &platform.MountInfo{MountPoint: filepath.Join(rootPath, "srv")}}
+	free := &Node{ID: -1, ParentID: 0, Name: "free", Size: 1, IsFreeSpace: true, DiskTotal: 1}
+	unaccounted := &Node{ID: -1, ParentID: 0, Name: "unaccounted", IsUnaccounted: true}
+	root.Children = []*Node{srv, home, free, unaccounted}
+	app := newApp(filepath.Join(t.TempDir(), "settings.json"))
+	app.store.Replace(root, []*Node{root, home, srv}, 1, 2)
+
+	if _, err := app.ExcludeMount(srv.ID); err != nil {
+		t.Fatal(err)
+	}
+	if root.DiskTotal <= 1 || free.DiskTotal != root.DiskTotal || free.Size != root.DiskFree {
+		t.Fatalf("disk usage after excluding a mount = root(%d total, %d free), free node(%d total, %d bytes)", root.DiskTotal, root.DiskFree, free.DiskTotal, free.Size)
+	}
+	if want := unaccountedSpace(root.DiskTotal, root.DiskFree, volumeScannedSize(root)); unaccounted.Size != want {
+		t.Fatalf("rest of volume = %d bytes, want %d", unaccounted.Size, want)
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
@@ -23,14 +27,34 @@ func (a *App) GetProfile() Profile {
 	defer a.settingsMu.RUnlock()
 	profile := a.profile
 	profile.ExcludedPaths = append([]string(nil), a.profile.ExcludedPaths...)
+	profile.ProtectedPaths = append([]string(nil), a.profile.ProtectedPaths...)
+	profile.Appearance.CustomPalettes = nil
+	for _, palette := range a.profile.Appearance.CustomPalettes {
+		palette.Colors = append([]string(nil), palette.Colors...)
+		profile.Appearance.CustomPalettes = append(profile.Appearance.CustomPalettes, palette)
+	}
+	profile.ScanProfiles = nil
+	for _, scan := range a.profile.ScanProfiles {
+		scan.Roots = append([]string(nil), scan.Roots...)
+		scan.ExcludedPaths = append([]string(nil), scan.ExcludedPaths...)
+		profile.ScanProfiles = append(profile.ScanProfiles, scan)
+	}
+	profile.Policy = a.policy.report()
 	return profile
 }
 
+// GetDefaultProfile returns the defaults with the system policy applied, so
+// restoring defaults never conflicts with locked settings.
 func (a *App) GetDefaultProfile() Profile {
-	return *defaultProfile()
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	profile := a.policy.apply(*defaultProfile())
+	profile.Policy = a.policy.report()
+	return profile
 }
 
 func (a *App) SetProfile(profile Profile) error {
+	profile.Policy = nil
 	profile, err := normalizeProfileWithFilesystem(profile, a.filesystem)
 	if err != nil {
 		return err
@@ -38,12 +62,16 @@ func (a *App) SetProfile(profile Profile) error {
 
 	a.settingsMu.Lock()
 	defer a.settingsMu.Unlock()
+	if err := a.policy.check(profile); err != nil {
+		return err
+	}
 	if a.settingsPath != "" {
 		if err := saveSettings(a.settingsPath, profile); err != nil {
 			return fmt.Errorf("save settings: %w", err)
 		}
 	}
 	a.profile = profile
+	a.store.SetProtection(newPathProtection(profile))
 	return nil
 }
 
@@ -118,6 +146,107 @@ func (a *App) PickSettingsPath() (string, error) {
 	return filepath.Clean(absPath), nil
 }
 
+// ExportSettingsBundle asks where to save the chosen settings sections, or
+// all of them when sections is empty, and returns the written path.
+func (a *App) ExportSettingsBundle(sections []string) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Export settings",
+		DefaultFilename:      "spacebrowser-settings.json",
+		CanCreateDirectories: true,
+		Filters:              []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	path = filepath.Clean(path)
+	return path, a.writeSettingsBundle(path, sections, time.Now())
+}
+
+func (a *App) writeSettingsBundle(path string, sections []string, now time.Time) error {
+	sections, err := validBundleSections(sections)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(newSettingsBundle(a.GetProfile(), sections, now), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode settings bundle: %w", err)
+	}
+	if err := writeSettingsFile(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write settings bundle: %w", err)
+	}
+	return nil
+}
+
+// PickSettingsBundle asks for a settings bundle to import.
+func (a *App) PickSettingsBundle() (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import settings",
+		Filters: []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
+
+// PreviewSettingsBundle validates an import and lists what it would change
+// without applying it.
+func (a *App) PreviewSettingsBundle(request SettingsBundleImport) (SettingsBundlePreview, error) {
+	preview, _, err := a.prepareSettingsImport(request)
+	return preview, err
+}
+
+// ImportSettingsBundle applies the import that PreviewSettingsBundle
+// describes for the same request.
+func (a *App) ImportSettingsBundle(request SettingsBundleImport) error {
+	_, imported, err := a.prepareSettingsImport(request)
+	if err != nil {
+		return err
+	}
+	return a.SetProfile(imported)
+}
+
+// prepareSettingsImport returns the normalized profile an import would
+// produce and its differences from the current one. Settings the system
+// policy enforces keep their enforced values.
+func (a *App) prepareSettingsImport(request SettingsBundleImport) (SettingsBundlePreview, Profile, error) {
+	bundle, err := readSettingsBundle(strings.TrimSpace(request.Path))
+	if err != nil {
+		return SettingsBundlePreview{}, Profile{}, err
+	}
+	sections := request.Sections
+	if len(sections) == 0 {
+		sections = bundle.Sections
+	}
+	if sections, err = validBundleSections(sections); err != nil {
+		return SettingsBundlePreview{}, Profile{}, err
+	}
+	current := a.GetProfile()
+	current.Policy = nil
+	imported, err := applySettingsBundle(current, bundle, sections, request.PathMappings)
+	if err != nil {
+		return SettingsBundlePreview{}, Profile{}, err
+	}
+	if imported, err = normalizeProfileWithFilesystem(imported, a.filesystem); err != nil {
+		return SettingsBundlePreview{}, Profile{}, fmt.Errorf("invalid settings bundle: %w", err)
+	}
+	a.settingsMu.RLock()
+	imported = a.policy.apply(imported)
+	a.settingsMu.RUnlock()
+	return SettingsBundlePreview{
+		Platform:   bundle.Platform,
+		ExportedAt: bundle.ExportedAt,
+		Sections:   sections,
+		Changes:    settingsChanges(current, imported),
+	}, imported, nil
+}
+
 func normalizeProfile(profile Profile) (Profile, error) {
 	return normalizeProfileWithFilesystem(profile, platform.Impl)
 }
@@ -131,21 +260,51 @@ func normalizeProfileWithFilesystem(profile Profile, filesystem platform.Scanner
 	}
 
 	profile.PlatformSystem = defaultProfile().PlatformSystem
-	cleaned := make([]string, 0, len(profile.ExcludedPaths))
-	seen := make(map[string]struct{}, len(profile.ExcludedPaths))
-	for _, path := range profile.ExcludedPaths {
+	profile.ExcludedPaths = cleanProfilePaths(profile.ExcludedPaths, filesystem)
+	scanProfiles, err := normalizeScanProfiles(profile.ScanProfiles, filesystem)
+	if err != nil {
+		return Profile{}, err
+	}
+	profile.ScanProfiles = scanProfiles
+
+	protected := make([]string, 0, len(profile.ProtectedPaths))
+	seen := make(map[string]struct{}, len(profile.ProtectedPaths))
+	for _, path := range profile.ProtectedPaths {
 		path = strings.TrimSpace(path)
 		if path == "" {
 			continue
 		}
+		if !filepath.IsAbs(path) {
+			return Profile{}, fmt.Errorf("protected path %q must be absolute", path)
+		}
 		path = filesystem.Canonicalize(path)
 		if _, exists := seen[path]; exists {
 			continue
 		}
 		seen[path] = struct{}{}
-		cleaned = append(cleaned, path)
+		protected = append(protected, path)
+	}
+	profile.ProtectedPaths = protected
+
+	switch profile.DeleteStrategy {
+	case "":
+		profile.DeleteStrategy = deleteStrategyTrash
+	case deleteStrategyTrash:
+	case deleteStrategyQuarantine:
+		if strings.TrimSpace(profile.QuarantineDir) == "" {
+			return Profile{}, fmt.Errorf("the quarantine delete strategy needs a quarantine folder")
+		}
+	default:
+		return Profile{}, fmt.Errorf("unknown delete strategy %q", profile.DeleteStrategy)
+	}
+	if dir := strings.TrimSpace(profile.QuarantineDir); dir != "" {
+		if !filepath.IsAbs(dir) {
+			return Profile{}, fmt.Errorf("the quarantine folder must be an absolute path")
+		}
+		profile.QuarantineDir = filesystem.Canonicalize(dir)
+	} else {
+		profile.QuarantineDir = ""
 	}
-	profile.ExcludedPaths = cleaned
 
 	appearance, err := normalizeAppearance(profile.Appearance)
 	if err != nil {
@@ -169,15 +328,16 @@ func normalizeControlSettings(controls ControlSettings) ControlSettings {
 }
 
 func normalizeAppearance(appearance AppearanceSettings) (AppearanceSettings, error) {
-	if appearance == (AppearanceSettings{}) {
+	if reflect.DeepEqual(appearance, AppearanceSettings{}) {
 		return defaultAppearanceSettings(), nil
 	}
-	validPalettes := map[string]bool{
-		"default": true, "legacy": true, "single": true, "duotone": true,
-		"tricolor": true, "playful": true, "monochrome": true,
-		"earth": true, "ocean": true, "retro": true,
+	customPalettes, err := normalizeCustomPalettes(appearance.CustomPalettes)
+	if err != nil {
+		return AppearanceSettings{}, err
 	}
-	if !validPalettes[appearance.Palette] {
+	appearance.CustomPalettes = customPalettes
+	_, builtin := builtinPalette(appearance.Palette)
+	if !builtin && !slices.ContainsFunc(customPalettes, func(palette Palette) bool { return palette.ID == appearance.Palette }) {
 		return AppearanceSettings{}, fmt.Errorf("unknown colour palette %q", appearance.Palette)
 	}
 	if math.IsNaN(appearance.ZoomFactor) || math.IsInf(appearance.ZoomFactor, 0) || appearance.ZoomFactor < 0.5 || appearance.ZoomFactor > 5 {
@@ -194,3 +354,10 @@ func normalizeAppearance(appearance AppearanceSettings) (AppearanceSettings, err
 	}
 	return appearance, nil
 }
+
+// GetBuiltinProtectedPaths lists the system locations that are protected in
+// addition to Profile.ProtectedPaths, for display in Settings.
+func (a *App) GetBuiltinProtectedPaths() []string {
+	protection := newPathProtection(Profile{PlatformSystem: a.GetProfile().PlatformSystem})
+	return append(protection.subtrees, protection.exact...)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// TrashBrowserItem is one item listed by the Trash browser. DeletedAt is a
+// Unix time and is zero, like OriginalPath is empty, when the Trash metadata
+// is missing.
+type TrashBrowserItem struct {
+	Path         string `json:"path"`
+	TrashRoot    string `json:"trashRoot"`
+	OriginalPath string `json:"originalPath,omitempty"`
+	DeletedAt    int64  `json:"deletedAt,omitempty"`
+	IsFolder     bool   `json:"isFolder"`
+	Size         int64  `json:"size"`
+}
+
+func trashBrowserItems(items []platform.TrashItem) []TrashBrowserItem {
+	listed := make([]TrashBrowserItem, len(items))
+	for index, item := range items {
+		listed[index] = TrashBrowserItem{
+			Path:         item.Path,
+			TrashRoot:    item.TrashRoot,
+			OriginalPath: item.OriginalPath,
+			IsFolder:     item.IsFolder,
+			Size:         item.AllocatedSize,
+		}
+		if !item.DeletedAt.IsZero() {
+			listed[index].DeletedAt = item.DeletedAt.Unix()
+		}
+	}
+	// Most recently deleted first; items without a deletion date last.
+	sort.SliceStable(listed, func(i, j int) bool {
+		if listed[i].DeletedAt != listed[j].DeletedAt {
+			return listed[i].DeletedAt > listed[j].DeletedAt
+		}
+		return listed[i].Path < listed[j].Path
+	})
+	return listed
+}
+
+// ListTrashItems lists every Trash this user can access, including Trash
+// folders on other volumes that are not part of the scanned tree.
+func (a *App) ListTrashItems() ([]TrashBrowserItem, error) {
+	items, err := platform.ListTrash(a.desktop)
+	if err != nil {
+		return nil, err
+	}
+	return trashBrowserItems(items), nil
+}
+
+func (a *App) validateTrashItem(path string) error {
+	if !a.desktop.IsInTrash(path) || a.desktop.IsTrashRoot(path) {
+		return fmt.Errorf("the item is not restorable Trash content")
+	}
+	return nil
+}
+
+// RestoreTrashItems restores Trash items to their original locations and
+// inserts those that belong to the scanned tree without a full rescan.
+func (a *App) RestoreTrashItems(paths []string) (DeleteResult, error) {
+	if len(paths) == 0 {
+		return DeleteResult{}, fmt.Errorf("no Trash items were selected")
+	}
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be restored while a scan is running")
+	}
+
+	var result DeleteResult
+	result.FileCount, result.DirCount = a.store.Counts()
+	result.Items = make([]DeleteItemResult, len(paths))
+	restored := 0
+	for index, path := range paths {
+		result.Items[index] = DeleteItemResult{NodeID: -1, Path: path}
+		err := a.validateTrashItem(path)
+		var info platform.TrashRestoreInfo
+		if err == nil {
+			info, err = a.desktop.TrashRestoreInfo(path)
+		}
+		if err == nil {
+			err = a.restoreTrashItem(info.TargetPath, info.OriginalPath, &result)
+		}
+		if err != nil {
+			result.Items[index].Error = err.Error()
+			continue
+		}
+		restored++
+	}
+	if restored == 0 {
+		return DeleteResult{}, fmt.Errorf("%s: %s", result.Items[0].Path, result.Items[0].Error)
+	}
+	result.trashRefreshes = a.store.DisplayedTrash(a.desktop.IsTrashRoot)
+	return a.completeDeletion(a.GetProfile(), result), nil
+}
+
+// DeleteTrashItems permanently deletes Trash items under the same settings as
+// permanent deletion from the treemap.
+func (a *App) DeleteTrashItems(paths []string) (DeleteResult, error) {
+	if len(paths) == 0 {
+		return DeleteResult{}, fmt.Errorf("no Trash items were selected")
+	}
+	return a.deleteTrashItems(paths)
+}
+
+// PurgeTrashOlderThan permanently deletes every Trash item deleted more than
+// days days ago. Items without a recorded deletion date are kept.
+func (a *App) PurgeTrashOlderThan(days int) (DeleteResult, error) {
+	return a.purgeTrashOlderThan(days, time.Now())
+}
+
+func (a *App) purgeTrashOlderThan(days int, now time.Time) (DeleteResult, error) {
+	if days < 0 {
+		return DeleteResult{}, fmt.Errorf("the purge age must not be negative")
+	}
+	items, err := platform.ListTrash(a.desktop)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	var paths []string
+	for _, item := range items {
+		if !item.DeletedAt.IsZero() && item.DeletedAt.Before(cutoff) {
+			paths = append(paths, item.Path)
+		}
+	}
+	return a.deleteTrashItems(paths)
+}
+
+func (a *App) deleteTrashItems(paths []string) (DeleteResult, error) {
+	profile := a.GetProfile()
+	if err := a.deleteAllowed(profile); err != nil {
+		return DeleteResult{}, err
+	}
+	if profile.DeleteStrategy == deleteStrategyQuarantine {
+		return DeleteResult{}, errQuarantineForbidsPermanentDeletion
+	}
+	if !profile.AllowPermanentDelete {
+		return DeleteResult{}, fmt.Errorf("permanent deletion is disabled; enable Allow permanent deletion in Settings")
+	}
+
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be deleted while a scan is running")
+	}
+
+	var result DeleteResult
+	result.FileCount, result.DirCount = a.store.Counts()
+	result.Items = make([]DeleteItemResult, len(paths))
+	deleted := 0
+	for index, path := range paths {
+		result.Items[index] = DeleteItemResult{NodeID: -1, Path: path}
+		err := a.validateTrashItem(path)
+		if err == nil && a.store.Protects(path) {
+			err = errProtectedPath
+		}
+		if err == nil {
+			size := a.auditedSize(path)
+			err = a.desktop.DeleteTrashItemPermanently(path)
+			a.audit(auditEntry(auditActionDeletePermanently, path, size, "", err))
+		}
+		if err != nil {
+			result.Items[index].Error = err.Error()
+			continue
+		}
+		deleted++
+	}
+	if deleted == 0 && len(paths) > 0 {
+		return DeleteResult{}, fmt.Errorf("%s: %s", result.Items[0].Path, result.Items[0].Error)
+	}
+	if deleted > 0 {
+		result.trashRefreshes = a.store.DisplayedTrash(a.desktop.IsTrashRoot)
+	}
+	return a.completeDeletion(profile, result), nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// listedTrashDesktop is a Trash folder outside the scanned tree whose items
+// remember their original paths and deletion dates.
+type listedTrashDesktop struct {
+	platform.DesktopActions
+	root    string
+	items   map[string]platform.TrashItem
+	deleted *[]string
+}
+
+func (desktop listedTrashDesktop) ListTrash() ([]platform.TrashItem, error) {
+	var items []platform.TrashItem
+	for _, item := range desktop.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (desktop listedTrashDesktop) IsTrashRoot(path string) bool { return path == desktop.root }
+
+func (desktop listedTrashDesktop) IsInTrash(path string) bool {
+	return strings.HasPrefix(path, desktop.root+string(os.PathSeparator))
+}
+
+func (desktop listedTrashDesktop) TrashRestoreInfo(path string) (platform.TrashRestoreInfo, error) {
+	item, ok := desktop.items[path]
+	if !ok {
+		return platform.TrashRestoreInfo{}, errors.New("not in Trash")
+	}
+	return platform.TrashRestoreInfo{TargetPath: path, OriginalPath: item.OriginalPath, DeletedAt: item.DeletedAt}, nil
+}
+
+func (desktop listedTrashDesktop) RestoreTrashItem(path string) error {
+	item := desktop.items[path]
+	delete(desktop.items, path)
+	return os.Rename(path, item.OriginalPath)
+}
+
+func (desktop listedTrashDesktop) DeleteTrashItemPermanently(path string) error {
+	delete(desktop.items, path)
+	*desktop.deleted = append(*desktop.deleted, path)
+	return os.RemoveAll(path)
+}
+
+func TestTrashBrowserRestoresDeletesAndPurges(t *testing.T) {
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "kept.bin"), make([]byte, 8192), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	trashRoot := t.TempDir()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	desktop := listedTrashDesktop{root: trashRoot, items: make(map[string]platform.TrashItem), deleted: new([]string)}
+	for name, age := range map[string]int{"old.iso": 40, "recent.txt": 5, "unknown": -1, "report.pdf": 2} {
+		path := filepath.Join(trashRoot, name)
+		if err := os.WriteFile(path, make([]byte, 8192), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		item := platform.TrashItem{Path: path, TrashRoot: trashRoot, OriginalPath: filepath.Join(base, name), AllocatedSize: 8192}
+		if age >= 0 {
+			item.DeletedAt = now.AddDate(0, 0, -age)
+		}
+		desktop.items[path] = item
+	}
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.MinFileSize = 0
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := app.ListTrashItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 4 || filepath.Base(listed[0].Path) != "report.pdf" || listed[3].DeletedAt != 0 {
+		t.Fatalf("Trash listing order = %+v", listed)
+	}
+
+	result, err := app.RestoreTrashItems([]string{filepath.Join(trashRoot, "report.pdf"), filepath.Join(base, "kept.bin")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RescanRequired || result.Items[0].Error != "" || result.Items[1].Error == "" {
+		t.Fatalf("restore result = %+v", result)
+	}
+	if app.store.nodeByPath(filepath.Join(base, "report.pdf")) == nil {
+		t.Fatal("the restored item was not inserted into the tree")
+	}
+
+	if _, err := app.purgeTrashOlderThan(30, now); err == nil {
+		t.Fatal("Trash was purged while delete commands are disabled")
+	}
+	app.profile.AllowDelete = true
+	app.profile.AllowPermanentDelete = true
+	if _, err := app.purgeTrashOlderThan(-1, now); err == nil {
+		t.Fatal("a negative purge age was accepted")
+	}
+	if _, err := app.purgeTrashOlderThan(30, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(*desktop.deleted) != 1 || filepath.Base((*desktop.deleted)[0]) != "old.iso" {
+		t.Fatalf("purge deleted %v; want only the item older than 30 days", *desktop.deleted)
+	}
+	if result, err := app.purgeTrashOlderThan(30, now); err != nil || len(result.Items) != 0 {
+		t.Fatalf("purge with nothing to delete = %+v, %v", result, err)
+	}
+
+	result, err = app.DeleteTrashItems([]string{filepath.Join(trashRoot, "unknown")})
+	if err != nil || result.Items[0].Error != "" {
+		t.Fatalf("permanent deletion = %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(trashRoot, "unknown")); !os.IsNotExist(err) {
+		t.Fatal("the permanently deleted item is still on disk")
+	}
+
+	// The Trash is outside the scanned tree, so sizes come from the disk.
+	entries, err := app.GetAuditLog(AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("audit entries = %+v, want a restore and two permanent deletions", entries)
+	}
+	for _, entry := range entries {
+		if entry.Size < 8192 {
+			t.Errorf("%s of %s was logged with %d bytes", entry.Action, entry.Path, entry.Size)
+		}
+	}
+}
+
+func TestLayoutAnnotatesTrashItems(t *testing.T) {
+	base := t.TempDir()
+	trashRoot := filepath.Join(base, "Trash")
+	for file, size := range map[string]int{"Trash/files/old/inner.bin": 1 << 20, "Trash/files/new.txt": 1 << 20, "Trash/files/orphan.bin": 1 << 20, "kept.bin": 1 << 20} {
+		path := filepath.Join(base, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	desktop := listedTrashDesktop{root: trashRoot, items: map[string]platform.TrashItem{
+		filepath.Join(trashRoot, "files", "old"):     {OriginalPath: "/home/user/old", DeletedAt: deleted},
+		filepath.Join(trashRoot, "files", "new.txt"): {OriginalPath: "/home/user/new.txt"},
+	}}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.MinFileSize = 0
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+	rects, err := app.Layout(app.store.root.ID, 1200, 900, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := make(map[string]Rect)
+	for _, rect := range rects {
+		byPath[rect.FullPath] = rect
+	}
+	checks := []struct {
+		path     string
+		original string
+		deleted  int64
+	}{
+		{"Trash/files/old", "/home/user/old", deleted.Unix()},
+		{"Trash/files/old/inner.bin", "", deleted.Unix()},
+		{"Trash/files/new.txt", "/home/user/new.txt", 0},
+		{"Trash/files/orphan.bin", "", 0},
+		{"Trash/files", "", 0},
+		{"kept.bin", "", 0},
+	}
+	for _, check := range checks {
+		rect, ok := byPath[filepath.Join(base, check.path)]
+		if !ok {
+			t.Fatalf("no rect for %s", check.path)
+		}
+		if rect.TrashOriginalPath != check.original || rect.TrashDeletedAt != check.deleted {
+			t.Fatalf("%s Trash metadata = (%q, %d), want (%q, %d)", check.path, rect.TrashOriginalPath, rect.TrashDeletedAt, check.original, check.deleted)
+		}
+	}
+
+	// A layout rooted inside a Trash item inherits the item's deletion date.
+	rects, err = app.Layout(byPath[filepath.Join(base, "Trash/files/old")].NodeID, 600, 400, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rect := range rects {
+		if rect.TrashDeletedAt != deleted.Unix() {
+			t.Fatalf("%s deletion date = %d inside a zoomed Trash item", rect.FullPath, rect.TrashDeletedAt)
+		}
+	}
+}
+
+// countingTrashDesktop counts how often Trash metadata is read.
+type countingTrashDesktop struct {
+	listedTrashDesktop
+	reads *int
+}
+
+func (desktop countingTrashDesktop) TrashRestoreInfo(path string) (platform.TrashRestoreInfo, error) {
+	*desktop.reads++
+	return desktop.listedTrashDesktop.TrashRestoreInfo(path)
+}
+
+func TestLayoutCachesTrashMetadataUntilTheNextScan(t *testing.T) {
+	base := t.TempDir()
+	trashRoot := filepath.Join(base, "Trash")
+	item := filepath.Join(trashRoot, "files", "old.bin")
+	if err := os.MkdirAll(filepath.Dir(item), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(item, make([]byte, 1<<20), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reads := 0
+	desktop := countingTrashDesktop{listedTrashDesktop{root: trashRoot, items: map[string]platform.TrashItem{
+		item: {OriginalPath: "/home/user/old.bin", DeletedAt: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)},
+	}}, &reads}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.MinFileSize = 0
+
+	for scan := range 2 {
+		if _, err := app.GetFullTree(base); err != nil {
+			t.Fatal(err)
+		}
+		for range 3 {
+			rects, err := app.Layout(app.store.root.ID, 800, 600, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, rect := range rects {
+				found = found || rect.FullPath == item && rect.TrashOriginalPath == "/home/user/old.bin"
+			}
+			if !found {
+				t.Fatal("the Trash item lost its original path")
+			}
+		}
+		if reads != scan+1 {
+			t.Fatalf("the Trash metadata was read %d times after %d scans", reads, scan+1)
+		}
+	}
+}
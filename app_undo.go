@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// maxTrashUndoEntries bounds how many moves to Trash can be undone.
+const maxTrashUndoEntries = 20
+
+// TrashedItem records where MoveToTrash placed one item.
+type TrashedItem struct {
+	OriginalPath string `json:"originalPath"`
+	TrashPath    string `json:"trashPath"`
+}
+
+// recordingMoveToTrash wraps MoveToTrash so that the location of every trashed
+// item is recorded for undo. Platforms that do not report the location are
+// searched for the item afterwards; items that cannot be located are still
+// moved, but cannot be undone from the app.
+func (a *App) recordingMoveToTrash() (func(string) error, *[]TrashedItem) {
+	var items []TrashedItem
+	move := func(path string) error {
+		started := time.Now()
+		trashPath, err := platform.MoveToTrash(a.desktop, path)
+		if err != nil {
+			var partial *platform.PartialTrashError
+			if errors.As(err, &partial) && a.logger != nil {
+				a.logger.Warningf("%s is in Trash as %s, but part of the original remains: %v", partial.Path, partial.TrashPath, partial.Err)
+			}
+			return err
+		}
+		if trashPath == "" {
+			if trashPath, err = platform.TrashedItemPath(a.desktop, path, started); err != nil {
+				if a.logger != nil {
+					a.logger.Debugf("move to Trash of %s cannot be undone: %v", path, err)
+				}
+				return nil
+			}
+		}
+		items = append(items, TrashedItem{OriginalPath: path, TrashPath: trashPath})
+		return nil
+	}
+	return move, &items
+}
+
+// pushTrashUndo records one undoable step; a batch is undone as a whole.
+func (a *App) pushTrashUndo(items []TrashedItem) {
+	if len(items) == 0 {
+		return
+	}
+	a.undoMu.Lock()
+	defer a.undoMu.Unlock()
+	a.trashUndo = append(a.trashUndo, items)
+	if len(a.trashUndo) > maxTrashUndoEntries {
+		a.trashUndo = a.trashUndo[len(a.trashUndo)-maxTrashUndoEntries:]
+	}
+}
+
+func (a *App) trashUndoCount() int {
+	a.undoMu.Lock()
+	defer a.undoMu.Unlock()
+	return len(a.trashUndo)
+}
+
+// GetTrashUndo returns the original paths restored by the next undo.
+func (a *App) GetTrashUndo() []string {
+	a.undoMu.Lock()
+	defer a.undoMu.Unlock()
+	if len(a.trashUndo) == 0 {
+		return []string{}
+	}
+	last := a.trashUndo[len(a.trashUndo)-1]
+	paths := make([]string, len(last))
+	for index, item := range last {
+		paths[index] = item.OriginalPath
+	}
+	return paths
+}
+
+// UndoMoveToTrash restores the most recent move to Trash made by the app and
+// puts the restored items back into the tree with a targeted scan instead of
+// a full rescan. The step is consumed even when some items fail to restore;
+// those are reported in the result.
+func (a *App) UndoMoveToTrash() (DeleteResult, error) {
+	a.scanMu.RLock()
+	defer a.scanMu.RUnlock()
+	if a.scanActive {
+		return DeleteResult{}, fmt.Errorf("items cannot be restored while a scan is running")
+	}
+
+	a.undoMu.Lock()
+	if len(a.trashUndo) == 0 {
+		a.undoMu.Unlock()
+		return DeleteResult{}, fmt.Errorf("there is no move to Trash to undo")
+	}
+	items := a.trashUndo[len(a.trashUndo)-1]
+	a.trashUndo = a.trashUndo[:len(a.trashUndo)-1]
+	a.undoMu.Unlock()
+
+	var result DeleteResult
+	result.FileCount, result.DirCount = a.store.Counts()
+	result.Items = make([]DeleteItemResult, len(items))
+	restored := 0
+	for index, item := range items {
+		result.Items[index] = DeleteItemResult{NodeID: -1, Path: item.OriginalPath}
+		if err := a.restoreTrashItem(item.TrashPath, item.OriginalPath, &result); err != nil {
+			result.Items[index].Error = err.Error()
+			continue
+		}
+		restored++
+	}
+	if restored == 0 {
+		return DeleteResult{}, fmt.Errorf("%s: %s", result.Items[0].Path, result.Items[0].Error)
+	}
+	result.trashRefreshes = a.store.DisplayedTrash(a.desktop.IsTrashRoot)
+	return a.completeDeletion(a.GetProfile(), result), nil
+}
+
+// restoreTrashItem restores trashPath and inserts the item restored at
+// originalPath into the tree, falling back to a rescan when that fails.
+func (a *App) restoreTrashItem(trashPath, originalPath string, result *DeleteResult) error {
+	size := a.auditedSize(trashPath)
+	err := a.desktop.RestoreTrashItem(trashPath)
+	a.audit(auditEntry(auditActionRestore, originalPath, size, trashPath, err))
+	if err != nil {
+		return err
+	}
+	inserted, err := a.insertRestoredPath(originalPath)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warningf("targeted refresh failed for restored %s: %v", originalPath, err)
+		}
+		result.RescanRequired = true
+		return nil
+	}
+	result.FileCount, result.DirCount = inserted.FileCount, inserted.DirCount
+	result.RescanRequired = result.RescanRequired || inserted.RescanRequired
+	return nil
+}
+
+// insertRestoredPath scans only the restored item and inserts it into the
+// tree, applying the same exclusions a full scan would.
+func (a *App) insertRestoredPath(path string) (DeleteResult, error) {
+	profile := a.treeScanSettings()
+	files, dirs := a.store.Counts()
+	unchanged := DeleteResult{FileCount: files, DirCount: dirs}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return DeleteResult{}, fmt.Errorf("inspect restored item: %w", err)
+	}
+	if shouldExclude(&profile, path) || (profile.SkipHidden && a.filesystem.IsHidden(path)) {
+		return unchanged, nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 && !profile.FollowSymlinks {
+		return unchanged, nil
+	}
+
+	if info.IsDir() {
+		ctx := a.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var scannedFiles, scannedDirs int64
+		scanner := NewScannerWithFilesystem(&profile, 0, a.filesystem)
+		scanner.SetContext(ctx, nil)
+		root, err := scanner.buildTree(path, 0, -1, &scannedFiles, &scannedDirs)
+		if err != nil {
+			return DeleteResult{}, err
+		}
+		if root == nil {
+			return unchanged, nil
+		}
+		return a.store.InsertSubtree(path, root, int(scannedFiles), int(scannedDirs))
+	}
+	if !info.Mode().IsRegular() {
+		return unchanged, nil
+	}
+
+	usage := a.filesystem.UsageFor(path, info)
+	if profile.MinFileSize > 0 && info.Size() < profile.MinFileSize {
+		aggregate := &Node{
+			Name:           "[Small Files]",
+			Size:           usage.AllocatedSize,
+			ApparentSize:   info.Size(),
+			IsSmallFiles:   true,
+			SmallFileCount: 1,
+			SmallFileLimit: profile.MinFileSize,
+		}
+		if profile.KeepSmallFileDetails {
+			aggregate.SmallFiles = []SmallFileEntry{{Name: a.filesystem.BaseName(path), Size: usage.AllocatedSize, ApparentSize: info.Size(), ModTime: info.ModTime().Unix(), LinkCount: usage.LinkCount}}
+		}
+		return a.store.InsertSubtree(path, aggregate, 1, 0)
+	}
+	node := &Node{
+		Name:         a.filesystem.BaseName(path),
+		FullPath:     path,
+		Size:         usage.AllocatedSize,
+		ApparentSize: info.Size(),
+		ModTime:      info.ModTime().Unix(),
+		LinkCount:    usage.LinkCount,
+	}
+	return a.store.InsertSubtree(path, node, 1, 0)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// recordingTrashDesktop moves items into a Trash folder and remembers where
+// each one went, like a platform whose Trash records original locations.
+type recordingTrashDesktop struct {
+	platform.DesktopActions
+	trash    string
+	original map[string]string
+}
+
+func (desktop recordingTrashDesktop) MoveToTrash(path string) error {
+	target := filepath.Join(desktop.trash, filepath.Base(path))
+	if err := os.Rename(path, target); err != nil {
+		return err
+	}
+	desktop.original[target] = path
+	return nil
+}
+
+func (desktop recordingTrashDesktop) TrashedItemPath(originalPath string, since time.Time) (string, error) {
+	for target, original := range desktop.original {
+		if original == originalPath {
+			return target, nil
+		}
+	}
+	return "", errors.New("not in Trash")
+}
+
+func (desktop recordingTrashDesktop) RestoreTrashItem(path string) error {
+	original, ok := desktop.original[path]
+	if !ok {
+		return errors.New("not in Trash")
+	}
+	delete(desktop.original, path)
+	return os.Rename(path, original)
+}
+
+func (recordingTrashDesktop) IsTrashRoot(string) bool { return false }
+
+func (recordingTrashDesktop) IsInTrash(string) bool { return false }
+
+// pathReportingTrashDesktop reports where each item went, like the Linux
+// desktop, and refuses to search Trash for it.
+type pathReportingTrashDesktop struct {
+	recordingTrashDesktop
+}
+
+func (desktop pathReportingTrashDesktop) MoveToTrashWithPath(path string) (string, error) {
+	if err := desktop.MoveToTrash(path); err != nil {
+		return "", err
+	}
+	return filepath.Join(desktop.trash, filepath.Base(path)), nil
+}
+
+func (pathReportingTrashDesktop) TrashedItemPath(string, time.Time) (string, error) {
+	return "", errors.New("Trash was searched for an item whose location was reported")
+}
+
+func TestUndoMoveToTrashReinsertsRestoredItems(t *testing.T) {
+	base := t.TempDir()
+	for _, file := range []string{"project/main.go", "project/data.bin", "movie.mkv"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(base, file)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, file), make([]byte, 16384), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	desktop := recordingTrashDesktop{trash: t.TempDir(), original: make(map[string]string)}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.AllowDelete = true
+	app.profile.MinFileSize = 0
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+	sizeBefore := app.store.root.Size
+	filesBefore, dirsBefore := app.store.Counts()
+
+	project := app.store.nodeByPath(filepath.Join(base, "project"))
+	movie := app.store.nodeByPath(filepath.Join(base, "movie.mkv"))
+	result, err := app.DeleteNodes([]int{project.ID, movie.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UndoCount != 1 || len(app.GetTrashUndo()) != 2 {
+		t.Fatalf("undo after batch = count %d, paths %v", result.UndoCount, app.GetTrashUndo())
+	}
+
+	result, err = app.UndoMoveToTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RescanRequired || result.UndoCount != 0 {
+		t.Fatalf("undo result = %+v", result)
+	}
+	for _, item := range result.Items {
+		if item.Error != "" {
+			t.Fatalf("undo item failed: %+v", item)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "project", "main.go")); err != nil {
+		t.Fatalf("restored folder is missing on disk: %v", err)
+	}
+	if app.store.root.Size != sizeBefore || result.FileCount != filesBefore || result.DirCount != dirsBefore {
+		t.Fatalf("tree after undo = %d bytes, %d files, %d dirs; want %d, %d, %d", app.store.root.Size, result.FileCount, result.DirCount, sizeBefore, filesBefore, dirsBefore)
+	}
+	if app.store.nodeByPath(filepath.Join(base, "project", "data.bin")) == nil || app.store.nodeByPath(filepath.Join(base, "movie.mkv")) == nil {
+		t.Fatal("restored items were not inserted into the tree")
+	}
+	if _, err := app.UndoMoveToTrash(); err == nil {
+		t.Fatal("an empty undo stack was undone")
+	}
+}
+
+func TestUndoMoveToTrashUsesReportedTrashPath(t *testing.T) {
+	base := t.TempDir()
+	movie := filepath.Join(base, "movie.mkv")
+	if err := os.WriteFile(movie, make([]byte, 16384), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	desktop := pathReportingTrashDesktop{recordingTrashDesktop{trash: t.TempDir(), original: make(map[string]string)}}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.AllowDelete = true
+	app.profile.MinFileSize = 0
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.DeleteNodes([]int{app.store.nodeByPath(movie).ID}); err != nil {
+		t.Fatal(err)
+	}
+	if undo := app.GetTrashUndo(); len(undo) != 1 {
+		t.Fatalf("undo after move = %v", undo)
+	}
+	result, err := app.UndoMoveToTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Error != "" {
+		t.Fatalf("undo result = %+v", result)
+	}
+	if _, err := os.Stat(movie); err != nil {
+		t.Fatalf("restored file is missing on disk: %v", err)
+	}
+}
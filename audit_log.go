@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// Audited actions.
+const (
+	auditActionTrash             = "trash"
+	auditActionQuarantine        = "quarantine"
+	auditActionEmptyTrash        = "empty-trash"
+	auditActionDeletePermanently = "delete-permanently"
+	auditActionRestore           = "restore"
+	auditActionExpire            = "expire-quarantine"
+)
+
+const (
+	auditOutcomeSucceeded = "succeeded"
+	auditOutcomeFailed    = "failed"
+)
+
+const (
+	auditLogName          = "audit.jsonl"
+	auditLogMaxBytes      = 4 << 20
+	retainedAuditLogs     = 5
+	defaultAuditLogLimit  = 500
+	auditLogScannerBuffer = 1 << 20
+)
+
+// AuditEntry is one line of the audit log. TrashPath is where a removed item
+// went, or where a restored item came from.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Action    string    `json:"action"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size,omitempty"`
+	TrashPath string    `json:"trashPath,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditQuery filters GetAuditLog. Path matches a case-insensitive substring,
+// Since and Until are Unix times, and zero values do not filter.
+type AuditQuery struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Since  int64  `json:"since"`
+	Until  int64  `json:"until"`
+	Limit  int    `json:"limit"`
+}
+
+func (query AuditQuery) matches(entry AuditEntry) bool {
+	if query.Action != "" && entry.Action != query.Action {
+		return false
+	}
+	if query.Path != "" && !strings.Contains(strings.ToLower(entry.Path), strings.ToLower(query.Path)) &&
+		!strings.Contains(strings.ToLower(entry.TrashPath), strings.ToLower(query.Path)) {
+		return false
+	}
+	if query.Since != 0 && entry.Time.Unix() < query.Since {
+		return false
+	}
+	if query.Until != 0 && entry.Time.Unix() > query.Until {
+		return false
+	}
+	return true
+}
+
+func auditEntry(action, path string, size int64, trashPath string, err error) AuditEntry {
+	entry := AuditEntry{Action: action, Path: path, Size: size, TrashPath: trashPath, Outcome: auditOutcomeSucceeded}
+	if err != nil {
+		entry.Outcome = auditOutcomeFailed
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// auditUser names the account running the app for audit entries.
+func auditUser() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	for _, key := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// auditLog appends entries to dir/audit.jsonl and rotates it into
+// audit.1.jsonl ... audit.N.jsonl once it grows past maxBytes.
+type auditLog struct {
+	dir      string
+	maxBytes int64
+	retained int
+}
+
+func newAuditLog(defaultSettingsPath string) (auditLog, error) {
+	if defaultSettingsPath == "" {
+		return auditLog{}, fmt.Errorf("default configuration location is unavailable")
+	}
+	return auditLog{
+		dir:      filepath.Join(filepath.Dir(defaultSettingsPath), "logs"),
+		maxBytes: auditLogMaxBytes,
+		retained: retainedAuditLogs,
+	}, nil
+}
+
+func (l auditLog) path(generation int) string {
+	if generation == 0 {
+		return filepath.Join(l.dir, auditLogName)
+	}
+	return filepath.Join(l.dir, fmt.Sprintf("audit.%d.jsonl", generation))
+}
+
+// open opens the current log for appending, rotating it first when full.
+func (l auditLog) open() (*os.File, error) {
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	if info, err := os.Stat(l.path(0)); err == nil && info.Size() >= l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(l.path(0), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return file, nil
+}
+
+func (l auditLog) rotate() error {
+	oldest := l.path(l.retained - 1)
+	if err := os.Remove(oldest); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove oldest audit log: %w", err)
+	}
+	for generation := l.retained - 2; generation >= 0; generation-- {
+		if err := os.Rename(l.path(generation), l.path(generation+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotate audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l auditLog) append(entries []AuditEntry) error {
+	file, err := l.open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("encode audit entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("flush audit log: %w", err)
+	}
+	return nil
+}
+
+// query returns matching entries from every retained file, newest first.
+func (l auditLog) query(query AuditQuery) ([]AuditEntry, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAuditLogLimit
+	}
+	var matched []AuditEntry
+	for generation := 0; generation < l.retained && len(matched) < limit; generation++ {
+		entries, err := readAuditLogFile(l.path(generation))
+		if err != nil {
+			return nil, err
+		}
+		for index := len(entries) - 1; index >= 0 && len(matched) < limit; index-- {
+			if query.matches(entries[index]) {
+				matched = append(matched, entries[index])
+			}
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Time.After(matched[j].Time) })
+	return matched, nil
+}
+
+func readAuditLogFile(path string) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), auditLogScannerBuffer)
+	for scanner.Scan() {
+		var entry AuditEntry
+		// A line torn by a crash is skipped rather than hiding the rest.
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// auditedSize returns the size to log for path: its scanned size when it is
+// part of the tree, otherwise what it occupies on disk. Call it before the
+// item is moved or removed.
+func (a *App) auditedSize(path string) int64 {
+	if size := a.store.PathSize(path); size > 0 {
+		return size
+	}
+	return allocatedTreeSize(a.filesystem, path)
+}
+
+// allocatedTreeSize sums the allocated size of path and everything below it.
+func allocatedTreeSize(filesystem platform.ScannerFilesystem, path string) int64 {
+	var total int64
+	_ = filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total += filesystem.UsageFor(current, info).AllocatedSize
+		}
+		return nil
+	})
+	return total
+}
+
+// audit appends entries to the audit log. Failures are logged; when the
+// profile requires the audit log, deleteAllowed has already checked that it
+// can be written.
+func (a *App) audit(entries ...AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	log, err := newAuditLog(a.GetDefaultSettingsPath())
+	if err == nil {
+		now, name := time.Now(), auditUser()
+		for index := range entries {
+			if entries[index].Time.IsZero() {
+				entries[index].Time = now
+			}
+			entries[index].User = name
+		}
+		a.auditMu.Lock()
+		err = log.append(entries)
+		a.auditMu.Unlock()
+	}
+	if err != nil && a.logger != nil {
+		a.logger.Warningf("could not write %d audit entries: %v", len(entries), err)
+	}
+}
+
+// deleteAllowed reports why delete commands are unavailable under profile:
+// they are disabled, or the profile requires an audit log that cannot be
+// written.
+func (a *App) deleteAllowed(profile Profile) error {
+	if !profile.AllowDelete {
+		return fmt.Errorf("delete commands are disabled; enable Allow delete command in Settings")
+	}
+	if !profile.RequireAuditLog {
+		return nil
+	}
+	log, err := newAuditLog(a.GetDefaultSettingsPath())
+	if err == nil {
+		a.auditMu.Lock()
+		var file *os.File
+		if file, err = log.open(); err == nil {
+			err = file.Close()
+		}
+		a.auditMu.Unlock()
+	}
+	if err != nil {
+		return fmt.Errorf("delete commands require the audit log, which is unavailable: %w", err)
+	}
+	return nil
+}
+
+// GetAuditLog returns audit entries matching query, newest first.
+func (a *App) GetAuditLog(query AuditQuery) ([]AuditEntry, error) {
+	log, err := newAuditLog(a.GetDefaultSettingsPath())
+	if err != nil {
+		return nil, err
+	}
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+	entries, err := log.query(query)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	return entries, nil
+}
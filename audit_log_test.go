@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func TestAuditLogQueriesNewestFirstAcrossRotations(t *testing.T) {
+	log := auditLog{dir: filepath.Join(t.TempDir(), "logs"), maxBytes: 200, retained: 3}
+	start := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	for index, path := range []string{"/srv/a.iso", "/srv/b.iso", "/home/c.iso", "/srv/d.iso", "/srv/e.iso"} {
+		entry := auditEntry(auditActionTrash, path, 4096, "/trash/"+filepath.Base(path), nil)
+		entry.Time = start.Add(time.Duration(index) * time.Minute)
+		if index == 3 {
+			entry = auditEntry(auditActionDeletePermanently, path, 4096, "", errors.New("permission denied"))
+			entry.Time = start.Add(time.Duration(index) * time.Minute)
+		}
+		if err := log.append([]AuditEntry{entry}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(log.path(1)); err != nil {
+		t.Fatalf("the audit log was not rotated: %v", err)
+	}
+	if _, err := os.Stat(log.path(3)); !os.IsNotExist(err) {
+		t.Fatal("more audit logs were kept than retained")
+	}
+
+	entries, err := log.query(AuditQuery{Path: "SRV"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index := 1; index < len(entries); index++ {
+		if entries[index].Time.After(entries[index-1].Time) {
+			t.Fatalf("entries are not newest first: %+v", entries)
+		}
+	}
+	if len(entries) == 0 || entries[0].Path != "/srv/e.iso" {
+		t.Fatalf("newest srv entry = %+v", entries)
+	}
+	failed, err := log.query(AuditQuery{Action: auditActionDeletePermanently})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Outcome != auditOutcomeFailed || failed[0].Error != "permission denied" {
+		t.Fatalf("permanent deletions = %+v", failed)
+	}
+	window, err := log.query(AuditQuery{Since: start.Add(time.Minute).Unix(), Until: start.Add(2 * time.Minute).Unix(), Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(window) != 1 || window[0].Path != "/home/c.iso" {
+		t.Fatalf("entries in the time window = %+v", window)
+	}
+}
+
+func TestAppAuditsDeletesAndCanRequireTheLog(t *testing.T) {
+	base := t.TempDir()
+	target := filepath.Join(base, "old.iso")
+	if err := os.WriteFile(target, make([]byte, 1<<20), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configDir := t.TempDir()
+	settingsPath := filepath.Join(configDir, "settings.json")
+	trash := filepath.Join(t.TempDir(), "Trash")
+	desktop := trashActionDesktop{path: trash, moveDestination: filepath.Join(trash, "old.iso")}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	profile := app.GetProfile()
+	profile.SkipNetworkFS = false
+	profile.MinFileSize = 0
+	profile.AllowDelete = true
+	profile.RescanOnDelete = false
+	profile.RequireAuditLog = true
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.DeleteNode(app.store.nodeByPath(target).ID); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := app.GetAuditLog(AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != auditActionTrash || entries[0].Path != target ||
+		entries[0].Size < 1<<20 || entries[0].Outcome != auditOutcomeSucceeded || entries[0].User == "" {
+		t.Fatalf("audit entries = %+v", entries)
+	}
+
+	// A file where the logs folder should be makes the log unwritable.
+	logsDir := filepath.Join(configDir, "logs")
+	if err := os.RemoveAll(logsDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logsDir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.deleteAllowed(app.GetProfile()); err == nil {
+		t.Fatal("delete commands stayed enabled without a writable audit log")
+	}
+	profile.RequireAuditLog = false
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.deleteAllowed(app.GetProfile()); err != nil {
+		t.Fatalf("an optional audit log blocked delete commands: %v", err)
+	}
+}
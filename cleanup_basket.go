@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cleanupBasketFileVersion = 1
+
+// CleanupBasketItem is one path queued for review. Sizes are recorded when the
+// item is queued and refreshed whenever the path is part of the current scan.
+type CleanupBasketItem struct {
+	Path            string `json:"path"`
+	IsFolder        bool   `json:"isFolder"`
+	Size            int64  `json:"size"`
+	ReclaimableSize int64  `json:"reclaimableSize"`
+	HardLinkedSize  int64  `json:"hardLinkedSize"`
+	AddedAt         int64  `json:"addedAt"`
+	InCurrentScan   bool   `json:"inCurrentScan"`
+}
+
+// CleanupBasket is the queued cleanup plan with its running totals.
+// HardLinkedSize counts files that have hard links outside the basket; their
+// space is only reclaimed when every link is deleted, so it is not part of
+// ReclaimableSize.
+type CleanupBasket struct {
+	Items           []CleanupBasketItem `json:"items"`
+	Size            int64               `json:"size"`
+	ReclaimableSize int64               `json:"reclaimableSize"`
+	HardLinkedSize  int64               `json:"hardLinkedSize"`
+}
+
+type persistedCleanupBasket struct {
+	Version int                 `json:"version"`
+	Items   []CleanupBasketItem `json:"items"`
+}
+
+// Cleanup plan actions.
+const (
+	cleanupActionTrash     = "trash"
+	cleanupActionPermanent = "delete-permanently"
+	cleanupActionSkip      = "skip"
+)
+
+// CleanupPlanItem is one reviewed step of an exported cleanup plan.
+type CleanupPlanItem struct {
+	CleanupBasketItem
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// CleanupPlan is the exported form of the cleanup basket. Actions follow the
+// delete policies of the profile at export time.
+type CleanupPlan struct {
+	Version              int               `json:"version"`
+	GeneratedAt          string            `json:"generatedAt"`
+	AllowDelete          bool              `json:"allowDelete"`
+	AllowPermanentDelete bool              `json:"allowPermanentDelete"`
+	Size                 int64             `json:"size"`
+	ReclaimableSize      int64             `json:"reclaimableSize"`
+	HardLinkedSize       int64             `json:"hardLinkedSize"`
+	Items                []CleanupPlanItem `json:"items"`
+}
+
+func cleanupBasketPath(settingsPath string) string {
+	return filepath.Join(filepath.Dir(settingsPath), "cleanup-basket.json")
+}
+
+func loadCleanupBasket(path string) ([]CleanupBasketItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read cleanup basket: %w", err)
+	}
+	var saved persistedCleanupBasket
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("decode cleanup basket: %w", err)
+	}
+	if saved.Version > cleanupBasketFileVersion {
+		return nil, fmt.Errorf("cleanup basket version %d is newer than supported version %d", saved.Version, cleanupBasketFileVersion)
+	}
+	return saved.Items, nil
+}
+
+func saveCleanupBasket(path string, items []CleanupBasketItem) error {
+	saved := persistedCleanupBasket{Version: cleanupBasketFileVersion, Items: items}
+	if saved.Items == nil {
+		saved.Items = []CleanupBasketItem{}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cleanup basket: %w", err)
+	}
+	data = append(data, '\n')
+	return writeSettingsFile(path, data)
+}
+
+// pathWithin reports whether path is parent or lies below it.
+func pathWithin(path, parent string) bool {
+	if path == parent {
+		return true
+	}
+	parent = strings.TrimSuffix(parent, string(filepath.Separator))
+	return strings.HasPrefix(path, parent+string(filepath.Separator))
+}
+
+// addCleanupBasketItems queues added behind items. A path already covered by
+// a queued folder is rejected; queued paths inside an added folder are
+// replaced by it so no byte is counted twice.
+func addCleanupBasketItems(items, added []CleanupBasketItem) ([]CleanupBasketItem, error) {
+	for _, item := range added {
+		for _, queued := range items {
+			if pathWithin(item.Path, queued.Path) {
+				if item.Path == queued.Path {
+					return nil, fmt.Errorf("%s is already in the cleanup basket", item.Path)
+				}
+				return nil, fmt.Errorf("%s is already covered by %s in the cleanup basket", item.Path, queued.Path)
+			}
+		}
+		kept := items[:0:0]
+		for _, queued := range items {
+			if !pathWithin(queued.Path, item.Path) {
+				kept = append(kept, queued)
+			}
+		}
+		items = append(kept, item)
+	}
+	return items, nil
+}
+
+func removeCleanupBasketItems(items []CleanupBasketItem, paths []string) []CleanupBasketItem {
+	removed := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		removed[path] = struct{}{}
+	}
+	kept := make([]CleanupBasketItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := removed[item.Path]; !ok {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func newCleanupBasket(items []CleanupBasketItem) CleanupBasket {
+	basket := CleanupBasket{Items: items}
+	if basket.Items == nil {
+		basket.Items = []CleanupBasketItem{}
+	}
+	for _, item := range items {
+		basket.Size += item.Size
+		basket.ReclaimableSize += item.ReclaimableSize
+		basket.HardLinkedSize += item.HardLinkedSize
+	}
+	return basket
+}
+
+// newCleanupPlan decides the action for every queued item the same way
+// ExecuteCleanupBasket does: protected paths are skipped, items in Trash need
+// permanent deletion, and everything else is moved to Trash.
+func newCleanupPlan(items []CleanupBasketItem, profile Profile, isInTrash func(string) bool, now time.Time) CleanupPlan {
+	basket := newCleanupBasket(items)
+	plan := CleanupPlan{
+		Version:              cleanupBasketFileVersion,
+		GeneratedAt:          now.UTC().Format(time.RFC3339),
+		AllowDelete:          profile.AllowDelete,
+		AllowPermanentDelete: profile.AllowPermanentDelete,
+		Size:                 basket.Size,
+		ReclaimableSize:      basket.ReclaimableSize,
+		HardLinkedSize:       basket.HardLinkedSize,
+		Items:                make([]CleanupPlanItem, len(items)),
+	}
+	protection := newPathProtection(profile)
+	for index, item := range items {
+		step := CleanupPlanItem{CleanupBasketItem: item, Action: cleanupActionTrash}
+		switch {
+		case !profile.AllowDelete:
+			step.Action, step.Reason = cleanupActionSkip, "delete commands are disabled"
+		case profile.DeleteStrategy == deleteStrategyQuarantine:
+			// Only the app keeps the quarantine manifest up to date.
+			step.Action, step.Reason = cleanupActionSkip, "items are quarantined from the app under the quarantine delete strategy"
+		case protection.protects(item.Path):
+			step.Action, step.Reason = cleanupActionSkip, "the path is protected"
+		case isInTrash != nil && isInTrash(item.Path):
+			if profile.AllowPermanentDelete {
+				step.Action = cleanupActionPermanent
+			} else {
+				step.Action, step.Reason = cleanupActionSkip, "permanent deletion is disabled"
+			}
+		}
+		plan.Items[index] = step
+	}
+	return plan
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// renderCleanupScript writes plan as a POSIX shell script meant to be read
+// before it is run. Skipped items are kept as comments.
+func renderCleanupScript(plan CleanupPlan) string {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# SpaceBrowser cleanup plan generated " + plan.GeneratedAt + "\n")
+	script.WriteString("# Review every command below before running this script.\n")
+	fmt.Fprintf(&script, "# %d items, %s queued, %s estimated reclaimable", len(plan.Items), formatByteSize(plan.Size), formatByteSize(plan.ReclaimableSize))
+	if plan.HardLinkedSize > 0 {
+		fmt.Fprintf(&script, ", %s hard-linked outside the basket", formatByteSize(plan.HardLinkedSize))
+	}
+	script.WriteString(".\nset -u\n\n")
+	script.WriteString(`move_to_trash() {
+	if command -v gio >/dev/null 2>&1; then
+		gio trash -- "$1"
+	elif command -v trash-put >/dev/null 2>&1; then
+		trash-put -- "$1"
+	elif command -v trash >/dev/null 2>&1; then
+		trash "$1"
+	else
+		echo "no Trash command found for $1" >&2
+		return 1
+	fi
+}
+
+delete_from_trash() {
+	rm -rf -- "$1" || return
+	dir=${1%/*}
+	case $dir in
+	*/files) rm -f -- "${dir%/files}/info/${1##*/}.trashinfo" ;;
+	esac
+}
+`)
+	for _, step := range plan.Items {
+		fmt.Fprintf(&script, "\n# %s", formatByteSize(step.Size))
+		if step.HardLinkedSize > 0 {
+			fmt.Fprintf(&script, ", %s hard-linked outside the basket", formatByteSize(step.HardLinkedSize))
+		}
+		script.WriteString("\n")
+		quoted := shellQuote(step.Path)
+		switch step.Action {
+		case cleanupActionTrash:
+			script.WriteString("move_to_trash " + quoted + "\n")
+		case cleanupActionPermanent:
+			script.WriteString("delete_from_trash " + quoted + "\n")
+		default:
+			// strconv.Quote keeps a newline in the path from ending the comment.
+			script.WriteString("# skipped (" + step.Reason + "): " + strconv.Quote(step.Path) + "\n")
+		}
+	}
+	return script.String()
+}
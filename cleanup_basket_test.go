@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func TestCleanupBasketRejectsCoveredPathsAndReplacesNestedOnes(t *testing.T) {
+	items, err := addCleanupBasketItems(nil, []CleanupBasketItem{
+		{Path: "/data/vms/old.qcow2", Size: 500},
+		{Path: "/data/notes.txt", Size: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addCleanupBasketItems(items, []CleanupBasketItem{{Path: "/data/notes.txt"}}); err == nil {
+		t.Fatal("a queued path was queued twice")
+	}
+
+	items, err = addCleanupBasketItems(items, []CleanupBasketItem{{Path: "/data/vms", IsFolder: true, Size: 900}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Path != "/data/notes.txt" || items[1].Path != "/data/vms" {
+		t.Fatalf("items after queueing the parent folder = %+v", items)
+	}
+	if _, err := addCleanupBasketItems(items, []CleanupBasketItem{{Path: "/data/vms/keep.qcow2"}}); err == nil || !strings.Contains(err.Error(), "covered by /data/vms") {
+		t.Fatalf("queueing a path inside a queued folder error = %v", err)
+	}
+	if _, err := addCleanupBasketItems(items, []CleanupBasketItem{{Path: "/data/vms2"}}); err != nil {
+		t.Fatalf("a sibling with a common name prefix was rejected: %v", err)
+	}
+
+	if basket := newCleanupBasket(removeCleanupBasketItems(items, []string{"/data/vms"})); len(basket.Items) != 1 || basket.Size != 100 {
+		t.Fatalf("basket after removal = %+v", basket)
+	}
+}
+
+func TestCleanupScriptFollowsDeletePolicies(t *testing.T) {
+	items := []CleanupBasketItem{
+		{Path: "/home/ann/it's here", Size: 2048, ReclaimableSize: 2048},
+		{Path: "/home/ann/.local/share/Trash/files/old\nrm -rf ~", Size: 1024, ReclaimableSize: 1024},
+		{Path: "/home/ann/linked.iso", Size: 4096, HardLinkedSize: 4096},
+	}
+	isInTrash := func(path string) bool { return strings.Contains(path, "/Trash/") }
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	plan := newCleanupPlan(items, Profile{AllowDelete: true}, isInTrash, now)
+	if plan.ReclaimableSize != 3072 || plan.HardLinkedSize != 4096 {
+		t.Fatalf("plan totals = %d reclaimable, %d hard-linked", plan.ReclaimableSize, plan.HardLinkedSize)
+	}
+	if plan.Items[0].Action != cleanupActionTrash || plan.Items[1].Action != cleanupActionSkip || plan.Items[2].Action != cleanupActionTrash {
+		t.Fatalf("plan actions = %+v", plan.Items)
+	}
+	script := renderCleanupScript(plan)
+	if !strings.Contains(script, `move_to_trash '/home/ann/it'\''s here'`) {
+		t.Fatalf("script does not quote the path:\n%s", script)
+	}
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, "rm -rf ~") {
+			t.Fatalf("a skipped path escaped its comment:\n%s", script)
+		}
+	}
+
+	plan = newCleanupPlan(items, Profile{AllowDelete: true, AllowPermanentDelete: true}, isInTrash, now)
+	if plan.Items[1].Action != cleanupActionPermanent || !strings.Contains(renderCleanupScript(plan), "delete_from_trash '/home/ann/.local/share/Trash/files/old\nrm -rf ~'") {
+		t.Fatalf("permanent deletion was not planned for the Trash item: %+v", plan.Items[1])
+	}
+	plan = newCleanupPlan(items, Profile{}, isInTrash, now)
+	for _, step := range plan.Items {
+		if step.Action != cleanupActionSkip {
+			t.Fatalf("disabled delete commands still planned %+v", step)
+		}
+	}
+}
+
+func TestCleanupScriptRemovesTrashMetadata(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no POSIX shell")
+	}
+	trash := filepath.Join(t.TempDir(), "Trash")
+	files := map[string]int{
+		"files/old.iso":             4,
+		"info/old.iso.trashinfo":    4,
+		"files/kept.iso":            4,
+		"info/kept.iso.trashinfo":   4,
+		"files/folder/nested.bin":   4,
+		"info/folder.trashinfo":     4,
+		"info/nested.bin.trashinfo": 4,
+	}
+	writeFixtureFiles(t, trash, files)
+	items := []CleanupBasketItem{
+		{Path: filepath.Join(trash, "files", "old.iso")},
+		{Path: filepath.Join(trash, "files", "folder", "nested.bin")},
+	}
+	plan := newCleanupPlan(items, Profile{AllowDelete: true, AllowPermanentDelete: true}, func(string) bool { return true }, time.Now())
+	if output, err := exec.Command("sh", "-c", renderCleanupScript(plan)).CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, output)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(trash, name))
+		want := name == "files/old.iso" || name == "info/old.iso.trashinfo" || name == "files/folder/nested.bin"
+		if removed := errors.Is(err, fs.ErrNotExist); removed != want {
+			t.Errorf("%s removed = %v, want %v", name, removed, want)
+		}
+	}
+}
+
+func TestExecuteCleanupBasketKeepsFailedItems(t *testing.T) {
+	base := t.TempDir()
+	trashPath := filepath.Join(base, "Trash")
+	for _, file := range []string{"big.bin", "Trash/old.bin"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(base, file)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, file), make([]byte, 8192), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	desktop := trashActionDesktop{path: trashPath, moveDestination: filepath.Join(t.TempDir(), "trashed")}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, desktop, nil)
+	app.profile.SkipNetworkFS = false
+	app.profile.AllowDelete = true
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+
+	big := app.store.nodeByPath(filepath.Join(base, "big.bin"))
+	old := app.store.nodeByPath(filepath.Join(trashPath, "old.bin"))
+	if big == nil || old == nil {
+		t.Fatal("scanned files are not addressable by path")
+	}
+	basket, err := app.AddToCleanupBasket([]int{big.ID, old.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(basket.Items) != 2 || basket.ReclaimableSize != big.Size+old.Size {
+		t.Fatalf("basket = %+v", basket)
+	}
+	if _, err := os.Stat(cleanupBasketPath(settingsPath)); err != nil {
+		t.Fatalf("the cleanup basket was not persisted: %v", err)
+	}
+
+	data, _, err := app.cleanupBasketExport("json", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan CleanupPlan
+	if err := json.Unmarshal(data, &plan); err != nil || len(plan.Items) != 2 || plan.Items[1].Action != cleanupActionSkip {
+		t.Fatalf("exported plan = %+v, %v", plan, err)
+	}
+
+	result, err := app.ExecuteCleanupBasket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 2 || result.Items[0].Error != "" || !strings.Contains(result.Items[1].Error, "permanent deletion is disabled") {
+		t.Fatalf("cleanup items = %+v", result.Items)
+	}
+	if _, err := os.Stat(desktop.moveDestination); err != nil {
+		t.Fatalf("the queued file was not moved to Trash: %v", err)
+	}
+	basket, err = app.GetCleanupBasket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(basket.Items) != 1 || basket.Items[0].Path != old.FullPath {
+		t.Fatalf("basket after cleanup = %+v; want only the refused Trash item", basket.Items)
+	}
+}
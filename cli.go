@@ -2,19 +2,60 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+const (
+	defaultRenderWidth  = 1920
+	defaultRenderHeight = 1080
+	maximumRenderScale  = 8
+)
+
 type commandLineOptions struct {
 	initialPath string
 	verbosity   int
 	showHelp    bool
 	showVersion bool
+	topFiles    int
+	topFolders  int
+	// renderPath receives a PNG or SVG treemap of the scanned path.
+	renderPath   string
+	renderWidth  int
+	renderHeight int
+	renderScale  float64
+	// htmlReportPath receives a self-contained HTML report.
+	htmlReportPath string
+	htmlReport     HTMLReportOptions
+	// sizesPath receives folder sizes as CSV or JSON Lines.
+	sizesPath string
+	sizes     FolderSizeOptions
+	// serveAddress, when set, serves the web UI to browsers instead of
+	// opening the desktop window.
+	serveAddress     string
+	serveAllowDelete bool
+	serveToken       string
+	// settingsPath replaces the configured settings file for this run.
+	settingsPath string
+	overrides    sessionOverrides
+}
+
+// headless reports whether the options request a terminal report instead of
+// the desktop window.
+func (o commandLineOptions) headless() bool {
+	return o.topFiles > 0 || o.topFolders > 0 || o.renderPath != "" || o.htmlReportPath != "" || o.sizesPath != ""
 }
 
 func parseCommandLine(args []string) (commandLineOptions, error) {
-	options := commandLineOptions{verbosity: defaultVerbosity}
+	options := commandLineOptions{
+		verbosity:    defaultVerbosity,
+		renderWidth:  defaultRenderWidth,
+		renderHeight: defaultRenderHeight,
+		renderScale:  1,
+		htmlReport:   defaultHTMLReportOptions(),
+	}
 	positionalOnly := false
 
 	for i := 0; i < len(args); i++ {
@@ -49,6 +90,163 @@ func parseCommandLine(args []string) (commandLineOptions, error) {
 					return options, err
 				}
 				continue
+			case isLongOption(argument, "--top-files"):
+				value, err := commandLineValue(args, &i, "--top-files", "an item count")
+				if err != nil {
+					return options, err
+				}
+				if options.topFiles, err = parseItemCount("--top-files", value); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--top-folders"):
+				value, err := commandLineValue(args, &i, "--top-folders", "an item count")
+				if err != nil {
+					return options, err
+				}
+				if options.topFolders, err = parseItemCount("--top-folders", value); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--render"):
+				value, err := commandLineValue(args, &i, "--render", "an image file")
+				if err != nil {
+					return options, err
+				}
+				if _, err := treemapImageFormat(value); err != nil {
+					return options, fmt.Errorf("--render: %w", err)
+				}
+				options.renderPath = value
+				continue
+			case isLongOption(argument, "--render-size"):
+				value, err := commandLineValue(args, &i, "--render-size", "a size such as 1920x1080")
+				if err != nil {
+					return options, err
+				}
+				if options.renderWidth, options.renderHeight, err = parseImageSize(value); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--render-scale"):
+				value, err := commandLineValue(args, &i, "--render-scale", "a scale factor")
+				if err != nil {
+					return options, err
+				}
+				scale, err := strconv.ParseFloat(value, 64)
+				if err != nil || scale < 0.5 || scale > maximumRenderScale {
+					return options, fmt.Errorf("--render-scale must be a number from 0.5 to %d", maximumRenderScale)
+				}
+				options.renderScale = scale
+				continue
+			case isLongOption(argument, "--html-report"):
+				value, err := commandLineValue(args, &i, "--html-report", "an HTML file")
+				if err != nil {
+					return options, err
+				}
+				if strings.TrimSpace(value) == "" {
+					return options, fmt.Errorf("--html-report requires an HTML file")
+				}
+				options.htmlReportPath = value
+				continue
+			case isLongOption(argument, "--html-depth"):
+				value, err := commandLineValue(args, &i, "--html-depth", "a folder depth")
+				if err != nil {
+					return options, err
+				}
+				if options.htmlReport.Depth, err = parseBoundedCount("--html-depth", value, maximumPrunedDepth); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--html-items"):
+				value, err := commandLineValue(args, &i, "--html-items", "an item count")
+				if err != nil {
+					return options, err
+				}
+				if options.htmlReport.ItemsPerFolder, err = parseBoundedCount("--html-items", value, maximumPrunedChildren); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--export-sizes"):
+				value, err := commandLineValue(args, &i, "--export-sizes", "a .csv or .jsonl file")
+				if err != nil {
+					return options, err
+				}
+				if _, err := folderSizesFormat(value); err != nil {
+					return options, fmt.Errorf("--export-sizes: %w", err)
+				}
+				options.sizesPath = value
+				continue
+			case isLongOption(argument, "--export-depth"):
+				value, err := commandLineValue(args, &i, "--export-depth", "a folder depth")
+				if err != nil {
+					return options, err
+				}
+				if options.sizes.MaxDepth, err = parseBoundedCount("--export-depth", value, maximumFolderSizeDepth); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--export-min-size"):
+				value, err := commandLineValue(args, &i, "--export-min-size", "a size")
+				if err != nil {
+					return options, err
+				}
+				if options.sizes.MinSize, err = parseByteSize(value); err != nil {
+					return options, fmt.Errorf("--export-min-size: %w", err)
+				}
+				continue
+			case argument == "--export-files":
+				options.sizes.IncludeFiles = true
+				continue
+			case isLongOption(argument, "--export-subtree"):
+				value, err := commandLineValue(args, &i, "--export-subtree", "a folder")
+				if err != nil {
+					return options, err
+				}
+				if strings.TrimSpace(value) == "" {
+					return options, fmt.Errorf("--export-subtree requires a folder")
+				}
+				options.sizes.Path = value
+				continue
+			case isLongOption(argument, "--serve"):
+				options.serveAddress = defaultServeAddress
+				if value, found := strings.CutPrefix(argument, "--serve="); found {
+					if _, _, err := net.SplitHostPort(value); err != nil {
+						return options, fmt.Errorf("--serve needs an address such as %s: %w", defaultServeAddress, err)
+					}
+					options.serveAddress = value
+				}
+				continue
+			case argument == "--serve-allow-delete":
+				options.serveAllowDelete = true
+				continue
+			case isLongOption(argument, "--settings"):
+				value, err := commandLineValue(args, &i, "--settings", "a settings file")
+				if err != nil {
+					return options, err
+				}
+				if options.settingsPath, err = settingsFileArgument(value); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--profile"), isLongOption(argument, "--exclude"), isLongOption(argument, "--min-file-size"):
+				name, _, _ := strings.Cut(argument, "=")
+				value, err := commandLineValue(args, &i, name, "a value")
+				if err != nil {
+					return options, err
+				}
+				if err := options.overrides.set(name, name, value); err != nil {
+					return options, err
+				}
+				continue
+			case isLongOption(argument, "--follow-symlinks"), isLongOption(argument, "--include-hidden"), isLongOption(argument, "--include-network"):
+				name, value, found := strings.Cut(argument, "=")
+				if !found {
+					value = "true"
+				}
+				if err := options.overrides.set(name, name, value); err != nil {
+					return options, err
+				}
+				continue
 			case strings.HasPrefix(argument, "-"):
 				return options, fmt.Errorf("unknown option %q", argument)
 			}
@@ -60,9 +258,95 @@ func parseCommandLine(args []string) (commandLineOptions, error) {
 		options.initialPath = argument
 	}
 
+	if options.serveAddress != "" && options.headless() {
+		return options, fmt.Errorf("--serve cannot be combined with report options")
+	}
+	if options.serveAllowDelete && options.serveAddress == "" {
+		return options, fmt.Errorf("--serve-allow-delete needs --serve")
+	}
+	if options.sizesPath == "" && (options.sizes != FolderSizeOptions{}) {
+		return options, fmt.Errorf("the --export options need --export-sizes")
+	}
+	if options.headless() && options.initialPath == "" {
+		return options, fmt.Errorf("a scan path is required for terminal reports")
+	}
 	return options, nil
 }
 
+// applyEnvironment takes the settings file and scan overrides that the
+// command line did not set from SPACEBROWSER_* environment variables. The
+// --serve access token only comes from the environment, which other users
+// cannot read the way they can read command lines.
+func (o *commandLineOptions) applyEnvironment(getenv func(string) string) error {
+	if path := getenv("SPACEBROWSER_SETTINGS"); path != "" && o.settingsPath == "" {
+		var err error
+		if o.settingsPath, err = settingsFileArgument(path); err != nil {
+			return fmt.Errorf("SPACEBROWSER_SETTINGS: %w", err)
+		}
+	}
+	if o.serveAddress != "" {
+		o.serveToken = getenv("SPACEBROWSER_SERVE_TOKEN")
+		if o.serveToken != "" && len(o.serveToken) < minimumServeToken {
+			return fmt.Errorf("SPACEBROWSER_SERVE_TOKEN needs at least %d characters", minimumServeToken)
+		}
+	}
+	return o.overrides.applyEnvironment(getenv)
+}
+
+func settingsFileArgument(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("the settings file path cannot be empty")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve settings path: %w", err)
+	}
+	return filepath.Clean(absPath), nil
+}
+
+func isLongOption(argument, name string) bool {
+	return argument == name || strings.HasPrefix(argument, name+"=")
+}
+
+// commandLineValue returns the value of a long option given either as
+// "--name value" or "--name=value", advancing index past a separate value.
+func commandLineValue(args []string, index *int, name, requirement string) (string, error) {
+	if value, found := strings.CutPrefix(args[*index], name+"="); found {
+		return value, nil
+	}
+	if *index+1 >= len(args) {
+		return "", fmt.Errorf("%s requires %s", name, requirement)
+	}
+	*index++
+	return args[*index], nil
+}
+
+func parseItemCount(name, value string) (int, error) {
+	return parseBoundedCount(name, value, maximumLargestItems)
+}
+
+func parseBoundedCount(name, value string, maximum int) (int, error) {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 || count > maximum {
+		return 0, fmt.Errorf("%s must be a number from 1 to %d", name, maximum)
+	}
+	return count, nil
+}
+
+// parseImageSize reads a WIDTHxHEIGHT image size in pixels.
+func parseImageSize(value string) (int, int, error) {
+	widthText, heightText, found := strings.Cut(strings.ToLower(value), "x")
+	width, widthErr := strconv.Atoi(widthText)
+	height, heightErr := strconv.Atoi(heightText)
+	if !found || widthErr != nil || heightErr != nil {
+		return 0, 0, fmt.Errorf("--render-size must look like 1920x1080, not %q", value)
+	}
+	if err := validTreemapImageSize(width, height); err != nil {
+		return 0, 0, fmt.Errorf("--render-size: %w", err)
+	}
+	return width, height, nil
+}
+
 func setVerbosity(options *commandLineOptions, value string) error {
 	verbosity, err := strconv.Atoi(value)
 	if err != nil || verbosity < verbosityCritical || verbosity > maximumVerbosity {
@@ -73,13 +357,63 @@ func setVerbosity(options *commandLineOptions, value string) error {
 }
 
 func commandLineUsage(executable string) string {
-	return fmt.Sprintf(`Usage: %s [path] [-v level]
+	return fmt.Sprintf(`Usage: %s [path] [-v level] [scan options] [report options]
+       %[1]s [path] --serve[=address] [--serve-allow-delete] [scan options]
 
-Launch SpaceBrowser and optionally begin scanning path.
+Launch SpaceBrowser and optionally begin scanning path. Report options scan
+path without opening a window and print the result to standard output or
+save it to a file. --serve shows SpaceBrowser in a web browser instead of a
+window, for computers without a desktop.
 
 Options:
   -v, --verbosity level  Logging verbosity: 0=critical, 1=error,
                          2=warning, 3=info, 4=debug, 5=trace (default 3)
+      --settings file    Use this settings file for this run
   -h, --help             Show this help
-      --version          Show the SpaceBrowser version`, executable)
+      --version          Show the SpaceBrowser version
+
+Scan options apply to this run only and are never saved:
+      --profile name     Use this scan profile for every scanned path
+      --exclude path     Also exclude path; may be repeated
+      --min-file-size n  Small-file threshold, in bytes or with a K, M, G
+                         or T suffix
+      --follow-symlinks  Follow symbolic links
+      --include-hidden   Scan hidden files and folders
+      --include-network  Scan network filesystems
+Boolean scan options accept =false. Each option can also be set with an
+environment variable named after it, such as SPACEBROWSER_MIN_FILE_SIZE or
+SPACEBROWSER_SETTINGS; SPACEBROWSER_EXCLUDE holds a list of paths separated
+like PATH. Command-line options win over the environment.
+
+Report options:
+      --top-files n      List the n largest files
+      --top-folders n    List the n largest folders
+      --render file      Save the treemap as a .png or .svg image
+      --render-size WxH  Image size in pixels (default %dx%d)
+      --render-scale n   Scale borders and labels, like a HiDPI screen
+                         (default 1)
+      --html-report file Save an interactive HTML report that works offline
+      --html-depth n     Folder levels in the HTML report (default %d)
+      --html-items n     Largest items kept per folder; the rest are
+                         grouped (default %d)
+      --export-sizes file
+                         Save folder sizes as .csv or .jsonl (JSON Lines)
+      --export-depth n   Only list items n levels below the scanned path
+                         (default unlimited)
+      --export-min-size n
+                         Only list items of at least n bytes, with an
+                         optional K, M, G or T suffix
+      --export-files     List files as well as folders
+      --export-subtree path
+                         Only list path and the items below it
+
+Browser options:
+      --serve[=address]  Serve the web UI at address and print a link
+                         with the access token (default %s).
+                         Tunnel the port, for example over SSH, to reach
+                         it from elsewhere. SPACEBROWSER_SERVE_TOKEN sets
+                         the token instead of a random one.
+      --serve-allow-delete
+                         Allow deleting files and saving settings from the
+                         browser, which are read-only by default`, executable, defaultRenderWidth, defaultRenderHeight, defaultPrunedDepth, defaultPrunedChildren, defaultServeAddress)
 }
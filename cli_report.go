@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// runCommandLineReport scans the requested path without starting Wails and
+// writes the requested reports to output.
+func runCommandLineReport(app *App, options commandLineOptions, output io.Writer) error {
+	tree, err := app.GetFullTree(options.initialPath)
+	if err != nil {
+		return err
+	}
+	if options.topFiles > 0 {
+		items, err := app.GetLargestItems(tree.RootID, options.topFiles, largestItemsFiles)
+		if err != nil {
+			return err
+		}
+		writeLargestItems(output, "Largest files", items)
+	}
+	if options.topFolders > 0 {
+		if options.topFiles > 0 {
+			fmt.Fprintln(output)
+		}
+		items, err := app.GetLargestItems(tree.RootID, options.topFolders, largestItemsFolders)
+		if err != nil {
+			return err
+		}
+		writeLargestItems(output, "Largest folders", items)
+	}
+	if options.renderPath != "" {
+		if err := app.saveTreemapImage(options.renderPath, tree.RootID, options.renderWidth, options.renderHeight, options.renderScale); err != nil {
+			return err
+		}
+		if options.topFiles > 0 || options.topFolders > 0 {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "Saved a %dx%d treemap to %s\n", options.renderWidth, options.renderHeight, options.renderPath)
+	}
+	if options.htmlReportPath != "" {
+		if err := app.saveHTMLReport(options.htmlReportPath, tree.RootID, options.htmlReport); err != nil {
+			return err
+		}
+		if (options.topFiles > 0 || options.topFolders > 0) && options.renderPath == "" {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "Saved the HTML report to %s\n", options.htmlReportPath)
+	}
+	if options.sizesPath != "" {
+		sizes := options.sizes
+		sizes.NodeID = tree.RootID
+		rows, err := app.saveFolderSizes(options.sizesPath, sizes)
+		if err != nil {
+			return err
+		}
+		if (options.topFiles > 0 || options.topFolders > 0) && options.renderPath == "" && options.htmlReportPath == "" {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "Saved %s rows of folder sizes to %s\n", formatCount(rows), options.sizesPath)
+	}
+	return nil
+}
+
+func writeLargestItems(output io.Writer, heading string, items []LargestItem) {
+	fmt.Fprintf(output, "%s\n", heading)
+	if len(items) == 0 {
+		fmt.Fprintln(output, "  none")
+		return
+	}
+	for index, item := range items {
+		fmt.Fprintf(output, "%5d  %10s  %s\n", index+1, formatByteSize(item.Size), item.Path)
+	}
+}
+
+// formatByteSize renders a byte count with binary units for terminal output.
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	value := float64(size)
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	index := -1
+	for value >= unit && index < len(suffixes)-1 {
+		value /= unit
+		index++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[index])
+}
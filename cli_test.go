@@ -1,6 +1,7 @@
 package main
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -58,3 +59,185 @@ func TestSeverityLoggerFiltersByVerbosity(t *testing.T) {
 		t.Fatalf("unexpected filtered message in %q", text)
 	}
 }
+
+func TestParseCommandLineReportOptions(t *testing.T) {
+	options, err := parseCommandLine([]string{"--top-files", "25", "--top-folders=10", "/data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !options.headless() || options.topFiles != 25 || options.topFolders != 10 || options.initialPath != "/data" {
+		t.Fatalf("unexpected report options: %+v", options)
+	}
+	for _, args := range [][]string{
+		{"--top-files", "10"},
+		{"/data", "--top-files", "0"},
+		{"/data", "--top-folders"},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestParseCommandLineRenderOptions(t *testing.T) {
+	options, err := parseCommandLine([]string{"/data", "--render", "usage.svg", "--render-size", "3840X2160", "--render-scale=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !options.headless() || options.renderPath != "usage.svg" || options.renderWidth != 3840 || options.renderHeight != 2160 || options.renderScale != 2 {
+		t.Fatalf("unexpected render options: %+v", options)
+	}
+	if options, _ = parseCommandLine([]string{"/data", "--render=usage.png"}); options.renderWidth != defaultRenderWidth || options.renderScale != 1 {
+		t.Fatalf("unexpected render defaults: %+v", options)
+	}
+	for _, args := range [][]string{
+		{"--render", "usage.png"},
+		{"/data", "--render", "usage.jpg"},
+		{"/data", "--render", "usage.png", "--render-size", "1920"},
+		{"/data", "--render", "usage.png", "--render-size", "0x100"},
+		{"/data", "--render", "usage.png", "--render-size", "16384x16384"},
+		{"/data", "--render", "usage.png", "--render-scale", "20"},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestParseCommandLineHTMLReportOptions(t *testing.T) {
+	options, err := parseCommandLine([]string{"/data", "--html-report=usage.html", "--html-depth", "3", "--html-items=200"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !options.headless() || options.htmlReportPath != "usage.html" || options.htmlReport != (HTMLReportOptions{Depth: 3, ItemsPerFolder: 200}) {
+		t.Fatalf("unexpected HTML report options: %+v", options)
+	}
+	for _, args := range [][]string{
+		{"--html-report", "usage.html"},
+		{"/data", "--html-report", "usage.html", "--html-depth", "0"},
+		{"/data", "--html-report", "usage.html", "--html-items", "5000"},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestFormatByteSizeUsesBinaryUnits(t *testing.T) {
+	for size, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 3 << 30: "3.0 GiB"} {
+		if got := formatByteSize(size); got != want {
+			t.Errorf("formatByteSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestParseCommandLineScanOverrides(t *testing.T) {
+	options, err := parseCommandLine([]string{
+		"--exclude", "/data/cache", "--exclude=/data/tmp", "--min-file-size", "1.5M",
+		"--follow-symlinks", "--include-hidden=false", "--profile=NAS", "--settings", "/tmp/other.json", "/data",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides := options.overrides
+	if len(overrides.ExcludedPaths) != 2 || *overrides.MinFileSize != 3<<19 || !*overrides.FollowSymlinks || !*overrides.SkipHidden {
+		t.Fatalf("unexpected overrides: %+v", overrides)
+	}
+	if overrides.SkipNetworkFS != nil || overrides.ScanProfile != "NAS" || options.settingsPath != filepath.Clean("/tmp/other.json") {
+		t.Fatalf("unexpected options: %+v", options)
+	}
+	for _, args := range [][]string{
+		{"--min-file-size", "-1"},
+		{"--min-file-size", "12 parsecs"},
+		{"--include-network=sometimes"},
+		{"--exclude"},
+		{"--profile="},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestCommandLineOverridesWinOverEnvironment(t *testing.T) {
+	environment := map[string]string{
+		"SPACEBROWSER_MIN_FILE_SIZE":   "4K",
+		"SPACEBROWSER_INCLUDE_NETWORK": "1",
+		"SPACEBROWSER_EXCLUDE":         strings.Join([]string{"/srv/a", "/srv/b"}, string(filepath.ListSeparator)),
+		"SPACEBROWSER_SETTINGS":        "/etc/spacebrowser/shared.json",
+	}
+	options, err := parseCommandLine([]string{"--min-file-size=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := options.applyEnvironment(func(name string) string { return environment[name] }); err != nil {
+		t.Fatal(err)
+	}
+	overrides := options.overrides
+	if *overrides.MinFileSize != 0 || *overrides.SkipNetworkFS || len(overrides.ExcludedPaths) != 2 {
+		t.Fatalf("unexpected overrides: %+v", overrides)
+	}
+	if options.settingsPath != filepath.Clean("/etc/spacebrowser/shared.json") {
+		t.Fatalf("settings path = %q", options.settingsPath)
+	}
+	want := []string{"Excluded paths (SPACEBROWSER_EXCLUDE)", "Minimum file size (--min-file-size)", "Skip network filesystems (SPACEBROWSER_INCLUDE_NETWORK)"}
+	if got := overrides.describe(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("describe() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCommandLineFolderSizeOptions(t *testing.T) {
+	options, err := parseCommandLine([]string{"/data", "--export-sizes=sizes.csv", "--export-depth", "2", "--export-min-size=1M", "--export-files", "--export-subtree", "/data/home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FolderSizeOptions{Path: "/data/home", MaxDepth: 2, MinSize: 1 << 20, IncludeFiles: true}
+	if !options.headless() || options.sizesPath != "sizes.csv" || options.sizes != want {
+		t.Fatalf("unexpected folder size options: %+v", options)
+	}
+	for _, args := range [][]string{
+		{"--export-sizes", "sizes.csv"},
+		{"/data", "--export-sizes", "sizes.xlsx"},
+		{"/data", "--export-sizes", "sizes.csv", "--export-depth", "0"},
+		{"/data", "--export-sizes", "sizes.csv", "--export-min-size", "lots"},
+		{"/data", "--export-depth", "2"},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestParseCommandLineServeOptions(t *testing.T) {
+	options, err := parseCommandLine([]string{"--serve", "/data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.headless() || options.serveAddress != defaultServeAddress || options.initialPath != "/data" || options.serveAllowDelete {
+		t.Fatalf("unexpected serve options: %+v", options)
+	}
+	options, err = parseCommandLine([]string{"--serve=0.0.0.0:9000", "--serve-allow-delete"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.serveAddress != "0.0.0.0:9000" || !options.serveAllowDelete {
+		t.Fatalf("unexpected serve options: %+v", options)
+	}
+	if err := options.applyEnvironment(func(name string) string {
+		if name == "SPACEBROWSER_SERVE_TOKEN" {
+			return "short"
+		}
+		return ""
+	}); err == nil {
+		t.Fatal("a short access token was accepted")
+	}
+	for _, args := range [][]string{
+		{"--serve=9000"},
+		{"--serve-allow-delete", "/data"},
+		{"--serve", "/data", "--top-files", "3"},
+	} {
+		if _, err := parseCommandLine(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
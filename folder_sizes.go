@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	folderSizesCSV   = "csv"
+	folderSizesJSONL = "jsonl"
+)
+
+// folderSizesFormat picks the export format from the extension of path.
+func folderSizesFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return folderSizesCSV, nil
+	case ".jsonl", ".ndjson":
+		return folderSizesJSONL, nil
+	}
+	return "", fmt.Errorf("folder size exports must be .csv or .jsonl files, not %q", filepath.Base(path))
+}
+
+func exportTime(seconds int64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
+
+// writeFolderSizes writes rows as CSV with a header line, or as JSON Lines
+// with one object per row. Times are RFC 3339 in UTC.
+func writeFolderSizes(w io.Writer, format string, rows []FolderSizeRow) error {
+	switch format {
+	case folderSizesCSV:
+		output := csv.NewWriter(w)
+		output.Write([]string{"path", "type", "depth", "allocated_bytes", "apparent_bytes", "files", "folders", "mtime", "link_count"})
+		for _, row := range rows {
+			kind := "file"
+			if row.IsFolder {
+				kind = "folder"
+			}
+			output.Write([]string{
+				row.Path,
+				kind,
+				strconv.Itoa(row.Depth),
+				strconv.FormatInt(row.AllocatedSize, 10),
+				strconv.FormatInt(row.ApparentSize, 10),
+				strconv.Itoa(row.Files),
+				strconv.Itoa(row.Folders),
+				exportTime(row.ModTime),
+				strconv.FormatUint(row.LinkCount, 10),
+			})
+		}
+		output.Flush()
+		if err := output.Error(); err != nil {
+			return fmt.Errorf("write CSV: %w", err)
+		}
+		return nil
+	case folderSizesJSONL:
+		type jsonRow struct {
+			FolderSizeRow
+			ModTime string `json:"mtime,omitempty"`
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, row := range rows {
+			if err := encoder.Encode(jsonRow{FolderSizeRow: row, ModTime: exportTime(row.ModTime)}); err != nil {
+				return fmt.Errorf("write JSON Lines: %w", err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported folder size format %q", format)
+}
+
+// saveFolderSizes exports the folder sizes selected by options to path, in
+// the format its extension names.
+func (a *App) saveFolderSizes(path string, options FolderSizeOptions) (int, error) {
+	format, err := folderSizesFormat(path)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := a.store.FolderSizes(options)
+	if err != nil {
+		return 0, err
+	}
+	var data bytes.Buffer
+	if err := writeFolderSizes(&data, format, rows); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data.Bytes(), 0o600); err != nil {
+		return 0, fmt.Errorf("write folder sizes: %w", err)
+	}
+	return len(rows), nil
+}
+
+// ExportFolderSizes asks where to save the folder sizes selected by options
+// and returns the written path. options.Format picks the suggested file type.
+func (a *App) ExportFolderSizes(options FolderSizeOptions) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	filter := runtime.FileFilter{DisplayName: "CSV files (*.csv)", Pattern: "*.csv"}
+	if options.Format == folderSizesJSONL {
+		filter = runtime.FileFilter{DisplayName: "JSON Lines files (*.jsonl)", Pattern: "*.jsonl;*.ndjson"}
+	} else if options.Format != folderSizesCSV {
+		return "", fmt.Errorf("unsupported folder size format %q", options.Format)
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Export folder sizes",
+		DefaultFilename:      "spacebrowser-folder-sizes." + options.Format,
+		CanCreateDirectories: true,
+		Filters:              []runtime.FileFilter{filter},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if filepath.Ext(path) == "" {
+		path += "." + options.Format
+	}
+	if _, err := a.saveFolderSizes(path, options); err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
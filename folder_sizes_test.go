@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func scanFolderSizesFixture(t *testing.T, minFileSize int64) (*App, *TreeInfo, string) {
+	t.Helper()
+	return scanFixture(t, map[string]int{"top.bin": 5000, "photos/a.jpg": 3000, "photos/b.jpg": 1000, "photos/raw/c.raw": 7000, "empty/keep.txt": 0}, minFileSize)
+}
+
+func folderSizeRowsByPath(rows []FolderSizeRow) map[string]FolderSizeRow {
+	byPath := make(map[string]FolderSizeRow, len(rows))
+	for _, row := range rows {
+		byPath[row.Path] = row
+	}
+	return byPath
+}
+
+func TestFolderSizesSumApparentSizesAndCounts(t *testing.T) {
+	app, tree, scanPath := scanFolderSizesFixture(t, 0)
+	rows, err := app.store.FolderSizes(FolderSizeOptions{NodeID: tree.RootID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0].Path != scanPath || rows[0].Depth != 0 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	byPath := folderSizeRowsByPath(rows)
+	root, photos := byPath[scanPath], byPath[filepath.Join(scanPath, "photos")]
+	if root.ApparentSize != 16000 || root.Files != 5 || root.Folders != 3 {
+		t.Fatalf("root = %+v", root)
+	}
+	if photos.ApparentSize != 11000 || photos.Files != 3 || photos.Folders != 1 || photos.Depth != 1 {
+		t.Fatalf("photos = %+v", photos)
+	}
+	if root.AllocatedSize < root.ApparentSize || photos.ModTime == 0 {
+		t.Fatalf("allocated %d, apparent %d, mtime %d", root.AllocatedSize, root.ApparentSize, photos.ModTime)
+	}
+
+	rows, err = app.store.FolderSizes(FolderSizeOptions{Path: filepath.Join(scanPath, "photos"), MaxDepth: 1, IncludeFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath = folderSizeRowsByPath(rows)
+	if len(rows) != 4 || byPath[filepath.Join(scanPath, "photos", "raw", "c.raw")].Path != "" {
+		t.Fatalf("the subtree export should stop one level down, got %+v", rows)
+	}
+	if file := byPath[filepath.Join(scanPath, "photos", "a.jpg")]; file.IsFolder || file.ApparentSize != 3000 || file.LinkCount == 0 {
+		t.Fatalf("file row = %+v", file)
+	}
+	if raw := byPath[filepath.Join(scanPath, "photos", "raw")]; raw.ApparentSize != 7000 || raw.Files != 1 {
+		t.Fatalf("raw = %+v", raw)
+	}
+
+	rows, err = app.store.FolderSizes(FolderSizeOptions{NodeID: tree.RootID, MinSize: root.AllocatedSize})
+	if err != nil || len(rows) != 1 || rows[0].Path != scanPath {
+		t.Fatalf("only the root reaches the minimum size, got %+v, %v", rows, err)
+	}
+	if _, err := app.store.FolderSizes(FolderSizeOptions{Path: filepath.Dir(scanPath)}); err == nil {
+		t.Fatal("a path outside the scan was accepted")
+	}
+}
+
+func TestFolderSizesCountSmallFileAggregates(t *testing.T) {
+	app, tree, scanPath := scanFolderSizesFixture(t, 4000)
+	rows, err := app.store.FolderSizes(FolderSizeOptions{NodeID: tree.RootID, IncludeFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := folderSizeRowsByPath(rows)
+	if photos := byPath[filepath.Join(scanPath, "photos")]; photos.ApparentSize != 11000 {
+		t.Fatalf("photos = %+v", photos)
+	}
+	for _, row := range rows {
+		if strings.Contains(row.Path, "[Small Files]") {
+			t.Fatalf("small-file aggregates should not be rows: %+v", row)
+		}
+	}
+	if root := byPath[scanPath]; root.ApparentSize != 16000 {
+		t.Fatalf("root = %+v", root)
+	}
+}
+
+func TestWriteFolderSizesFormats(t *testing.T) {
+	rows := []FolderSizeRow{
+		{Path: "/data", IsFolder: true, AllocatedSize: 8192, ApparentSize: 5000, Files: 2, Folders: 1, ModTime: 1700000000, LinkCount: 3},
+		{Path: "/data/a, \"b\".txt", Depth: 1, AllocatedSize: 4096, ApparentSize: 12, Files: 1, LinkCount: 1},
+	}
+	var output bytes.Buffer
+	if err := writeFolderSizes(&output, folderSizesCSV, rows); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&output).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != "path,type,depth,allocated_bytes,apparent_bytes,files,folders,mtime,link_count" {
+		t.Fatalf("records = %q", records)
+	}
+	if strings.Join(records[1], ",") != "/data,folder,0,8192,5000,2,1,2023-11-14T22:13:20Z,3" || records[2][0] != "/data/a, \"b\".txt" || records[2][7] != "" {
+		t.Fatalf("records = %q", records)
+	}
+
+	output.Reset()
+	if err := writeFolderSizes(&output, folderSizesJSONL, rows); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JSON Lines output = %q", output.String())
+	}
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first["path"] != "/data" || first["mtime"] != "2023-11-14T22:13:20Z" || first["apparentSize"] != float64(5000) || first["linkCount"] != float64(3) {
+		t.Fatalf("first line = %v", first)
+	}
+	if strings.Contains(lines[1], "mtime") {
+		t.Fatalf("unknown times should be left out, got %s", lines[1])
+	}
+}
+
+func TestSaveFolderSizesPicksFormatFromExtension(t *testing.T) {
+	app, tree, _ := scanFolderSizesFixture(t, 0)
+	outputDir := t.TempDir()
+	if _, err := app.saveFolderSizes(filepath.Join(outputDir, "sizes.txt"), FolderSizeOptions{NodeID: tree.RootID}); err == nil {
+		t.Fatal("an unknown extension was accepted")
+	}
+	path := filepath.Join(outputDir, "sizes.ndjson")
+	count, err := app.saveFolderSizes(path, FolderSizeOptions{NodeID: tree.RootID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 || bytes.Count(data, []byte("\n")) != 4 || !bytes.HasPrefix(data, []byte(`{"path":`)) {
+		t.Fatalf("saved %d rows: %s", count, data)
+	}
+}
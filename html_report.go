@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// htmlReportLargestItems is the length of the largest files and folders
+// tables.
+const htmlReportLargestItems = 25
+
+//go:embed html_report.tmpl
+var htmlReportTemplateText string
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc":  func(index int) int { return index + 1 },
+	"size": func(size int64) string { return formatDisplaySize(size, -1) },
+	"count": func(count int64) string {
+		return formatCount(int(count))
+	},
+	"date": func(seconds int64) string {
+		if seconds == 0 {
+			return ""
+		}
+		return time.Unix(seconds, 0).Format("2006-01-02")
+	},
+}).Parse(htmlReportTemplateText))
+
+// HTMLReportOptions limits how much of the tree a report carries.
+type HTMLReportOptions struct {
+	Depth          int `json:"depth"`
+	ItemsPerFolder int `json:"itemsPerFolder"`
+}
+
+func defaultHTMLReportOptions() HTMLReportOptions {
+	return HTMLReportOptions{Depth: defaultPrunedDepth, ItemsPerFolder: defaultPrunedChildren}
+}
+
+type htmlReportSetting struct {
+	Label string
+	Value string
+}
+
+type htmlReportCount struct {
+	Label string
+	Count int64
+}
+
+type htmlReportData struct {
+	RootPath       string
+	Version        string
+	GeneratedAt    string
+	ScannedAt      string
+	Duration       string
+	Files          int64
+	Folders        int64
+	Size           int64
+	Settings       []htmlReportSetting
+	Overrides      []string
+	ErrorCount     int64
+	Skipped        []htmlReportCount
+	Errors         []htmlReportCount
+	Examples       []ScanReportExample
+	LargestFiles   []LargestItem
+	LargestFolders []LargestItem
+	Options        HTMLReportOptions
+	Tree           template.JS
+	Colors         template.JS
+}
+
+func nonzeroScanCounts(counts []int64, labels []string) []htmlReportCount {
+	var nonzero []htmlReportCount
+	for index, count := range counts {
+		if count > 0 {
+			nonzero = append(nonzero, htmlReportCount{Label: labels[index], Count: count})
+		}
+	}
+	return nonzero
+}
+
+// writeHTMLReport writes a single-file report of the subtree at nodeID: the
+// pruned tree with a treemap viewer, the largest items, and the settings and
+// problems of the scan that produced it. It needs nothing but a browser.
+func (a *App) writeHTMLReport(w io.Writer, nodeID int, options HTMLReportOptions, now time.Time) error {
+	a.settingsMu.RLock()
+	showFreeSpace := a.showFreeSpace
+	appearance := a.profile.Appearance
+	a.settingsMu.RUnlock()
+	a.scanMu.RLock()
+	scan := a.lastScan
+	a.scanMu.RUnlock()
+
+	tree, err := a.store.PrunedTree(nodeID, options.Depth, options.ItemsPerFolder, showFreeSpace)
+	if err != nil {
+		return err
+	}
+	largestFiles, err := a.store.Largest(nodeID, htmlReportLargestItems, largestItemsFiles)
+	if err != nil {
+		return err
+	}
+	largestFolders, err := a.store.Largest(nodeID, htmlReportLargestItems, largestItemsFolders)
+	if err != nil {
+		return err
+	}
+	treeJSON, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("encode report tree: %w", err)
+	}
+	colorsJSON, err := json.Marshal(paletteColors(appearance))
+	if err != nil {
+		return fmt.Errorf("encode report palette: %w", err)
+	}
+
+	files, folders := a.store.Counts()
+	data := htmlReportData{
+		RootPath:       tree.Path,
+		Version:        applicationVersion(),
+		GeneratedAt:    now.Format("2006-01-02 15:04:05 MST"),
+		Files:          int64(files),
+		Folders:        int64(folders),
+		Size:           tree.Size,
+		Overrides:      scan.Overrides,
+		ErrorCount:     scan.Report.TotalErrors(),
+		Skipped:        nonzeroScanCounts(scan.Report.Skipped[:], scanSkipLabels[:]),
+		Errors:         nonzeroScanCounts(scan.Report.Errors[:], scanErrorLabels[:]),
+		Examples:       scan.Report.Examples,
+		LargestFiles:   largestFiles,
+		LargestFolders: largestFolders,
+		Options:        options,
+		Tree:           template.JS(treeJSON),
+		Colors:         template.JS(colorsJSON),
+	}
+	if !scan.StartedAt.IsZero() {
+		data.ScannedAt = scan.StartedAt.Format("2006-01-02 15:04:05 MST")
+		data.Duration = scan.Duration.Round(time.Millisecond).String()
+		if scan.RootPath != tree.Path {
+			data.Settings = append(data.Settings, htmlReportSetting{"Scan root", scan.RootPath})
+		}
+		if scan.ScanProfile != "" {
+			data.Settings = append(data.Settings, htmlReportSetting{"Scan profile", scan.ScanProfile})
+		}
+		data.Settings = append(data.Settings,
+			htmlReportSetting{"Skip hidden", fmt.Sprint(scan.Profile.SkipHidden)},
+			htmlReportSetting{"Small-file threshold", formatDisplaySize(scan.Profile.MinFileSize, -1)},
+			htmlReportSetting{"Follow symlinks", fmt.Sprint(scan.Profile.FollowSymlinks)},
+			htmlReportSetting{"Skip network filesystems", fmt.Sprint(scan.Profile.SkipNetworkFS)},
+		)
+		for _, path := range scan.Profile.ExcludedPaths {
+			data.Settings = append(data.Settings, htmlReportSetting{"Excluded", path})
+		}
+	}
+	var page bytes.Buffer
+	if err := htmlReportTemplate.Execute(&page, data); err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	if _, err := w.Write(page.Bytes()); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+func (a *App) saveHTMLReport(path string, nodeID int, options HTMLReportOptions) error {
+	var page bytes.Buffer
+	if err := a.writeHTMLReport(&page, nodeID, options, time.Now()); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, page.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// ExportHTMLReport asks where to save an HTML report of nodeID and returns
+// the written path.
+func (a *App) ExportHTMLReport(nodeID int, options HTMLReportOptions) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Save HTML report",
+		DefaultFilename:      "spacebrowser-report.html",
+		CanCreateDirectories: true,
+		Filters:              []runtime.FileFilter{{DisplayName: "HTML files (*.html)", Pattern: "*.html;*.htm"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if filepath.Ext(path) == "" {
+		path += ".html"
+	}
+	if err := a.saveHTMLReport(path, nodeID, options); err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scanHTMLReportFixture(t *testing.T) (*App, *TreeInfo, string) {
+	t.Helper()
+	return scanFixture(t, map[string]int{"large.bin": 64 << 10, "medium.bin": 32 << 10, "a&b'.bin": 16 << 10, "tiny.bin": 8 << 10, "nested/deep/file.bin": 4 << 10}, 0)
+}
+
+func TestPrunedTreeGroupsSmallItemsAndLimitsDepth(t *testing.T) {
+	app, tree, scanPath := scanHTMLReportFixture(t)
+	pruned, err := app.store.PrunedTree(tree.RootID, 1, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned.Path != scanPath || len(pruned.Children) != 3 {
+		t.Fatalf("pruned root = %+v", pruned)
+	}
+	if pruned.Children[0].Name != "large.bin" || pruned.Children[1].Name != "medium.bin" {
+		t.Fatalf("the largest items should be kept, got %q and %q", pruned.Children[0].Name, pruned.Children[1].Name)
+	}
+	grouped := pruned.Children[2]
+	if grouped.Kind != "grouped" || grouped.Grouped != 3 || grouped.Name != "3 more items" {
+		t.Fatalf("grouped = %+v", grouped)
+	}
+	var total int64
+	for _, child := range pruned.Children {
+		total += child.Size
+	}
+	if total != pruned.Size {
+		t.Fatalf("children add up to %d bytes, root has %d", total, pruned.Size)
+	}
+
+	pruned, err = app.store.PrunedTree(tree.RootID, 2, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range pruned.Children {
+		if child.Name == "nested" && (len(child.Children) != 1 || child.Children[0].Name != "deep" || child.Children[0].Children != nil) {
+			t.Fatalf("nested = %+v, want deep without children", child)
+		}
+	}
+	if _, err := app.store.PrunedTree(tree.RootID, 0, 10, false); err == nil {
+		t.Fatal("a zero depth was accepted")
+	}
+}
+
+func TestWriteHTMLReportIsSelfContained(t *testing.T) {
+	app, tree, scanPath := scanHTMLReportFixture(t)
+	var page bytes.Buffer
+	if err := app.writeHTMLReport(&page, tree.RootID, defaultHTMLReportOptions(), time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	html := page.String()
+	for _, want := range []string{
+		"<title>Disk usage of " + scanPath + "</title>",
+		"Generated 2026-05-01 12:00:00 UTC",
+		"<td class=\"path\">" + filepath.Join(scanPath, "large.bin") + "</td>",
+		"a&amp;b&#39;.bin",
+		`"n":"a\u0026b'.bin"`,
+		"<th>Small-file threshold</th><td class=\"path\">0 B</td>",
+		"No paths were skipped and no errors occurred.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %s", want)
+		}
+	}
+	for _, external := range []string{"a&b'", "src=", "href=", "@import"} {
+		if strings.Contains(html, external) {
+			t.Errorf("report contains %q", external)
+		}
+	}
+}
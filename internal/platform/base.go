@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 var (
@@ -15,6 +16,23 @@ var (
 	ErrOperationCancelled      = errors.New("platform operation cancelled")
 )
 
+// PartialTrashError reports an item that was copied into a Trash on another
+// file system but whose original could not be removed completely. What is
+// left at Path duplicates TrashPath until someone removes it.
+type PartialTrashError struct {
+	Path      string
+	TrashPath string
+	Err       error
+}
+
+func (e *PartialTrashError) Error() string {
+	return fmt.Sprintf("copied %s to Trash as %s, but the original was only partially removed: %v", e.Path, e.TrashPath, e.Err)
+}
+
+func (e *PartialTrashError) Unwrap() error {
+	return e.Err
+}
+
 type FileIdentity struct {
 	Volume uint64
 	Low    uint64
@@ -49,9 +67,65 @@ type DirectoryReadDiagnostic struct {
 	Cause            error
 }
 
+// TrashRestoreInfo describes a top-level Trash item. DeletedAt is the zero
+// time when the Trash does not record when the item was deleted.
 type TrashRestoreInfo struct {
 	TargetPath   string
 	OriginalPath string
+	DeletedAt    time.Time
+}
+
+type trashPathMover interface {
+	MoveToTrashWithPath(path string) (string, error)
+}
+
+// MoveToTrash moves path to Trash through actions and returns where the item
+// went. The location is empty on platforms whose Trash command does not
+// report it; TrashedItemPath can search for the item there.
+func MoveToTrash(actions DesktopActions, path string) (string, error) {
+	if mover, ok := actions.(trashPathMover); ok {
+		return mover.MoveToTrashWithPath(path)
+	}
+	return "", actions.MoveToTrash(path)
+}
+
+type trashLocator interface {
+	TrashedItemPath(originalPath string, since time.Time) (string, error)
+}
+
+// TrashedItemPath finds where MoveToTrash placed originalPath, considering
+// only items trashed at or after since. Platforms whose Trash does not record
+// original locations report an error.
+func TrashedItemPath(actions DesktopActions, originalPath string, since time.Time) (string, error) {
+	if locator, ok := actions.(trashLocator); ok {
+		return locator.TrashedItemPath(originalPath, since)
+	}
+	return "", fmt.Errorf("locating trashed items is not supported on this platform")
+}
+
+// TrashItem is one top-level item in a Trash the user can access. When the
+// Trash metadata is missing or unreadable, OriginalPath is empty and DeletedAt
+// is the zero time.
+type TrashItem struct {
+	Path          string
+	TrashRoot     string
+	OriginalPath  string
+	DeletedAt     time.Time
+	IsFolder      bool
+	AllocatedSize int64
+}
+
+type trashLister interface {
+	ListTrash() ([]TrashItem, error)
+}
+
+// ListTrash enumerates the items in every Trash of the current user, including
+// Trash directories on other mounted volumes.
+func ListTrash(actions DesktopActions) ([]TrashItem, error) {
+	if lister, ok := actions.(trashLister); ok {
+		return lister.ListTrash()
+	}
+	return nil, fmt.Errorf("browsing Trash is not supported on this platform")
 }
 
 // ScanLocation is a user-visible filesystem root that can be selected as a
@@ -74,6 +148,44 @@ func ReadDirWithDiagnostics(filesystem ScannerFilesystem, path string) ([]Direct
 	return entries, nil, err
 }
 
+// MountInfo describes one mounted filesystem. Device is the kernel
+// major:minor pair where the platform exposes it; Source is the mounted block
+// device, network share or pseudo-filesystem name.
+type MountInfo struct {
+	MountPoint     string `json:"mountPoint"`
+	FilesystemType string `json:"fsType"`
+	Device         string `json:"device,omitempty"`
+	Source         string `json:"source,omitempty"`
+}
+
+type mountTableReader interface {
+	MountTable() ([]MountInfo, error)
+}
+
+// MountTable lists the filesystems mounted on the system, or nothing when the
+// platform cannot enumerate them.
+func MountTable(filesystem ScannerFilesystem) ([]MountInfo, error) {
+	if reader, ok := filesystem.(mountTableReader); ok {
+		return reader.MountTable()
+	}
+	return nil, nil
+}
+
+type fileOwnerReader interface {
+	FileOwner(string) (string, error)
+}
+
+// FileOwner returns the account owning path, or "" when the platform does not
+// report owners or the owner cannot be read.
+func FileOwner(filesystem ScannerFilesystem, path string) string {
+	if reader, ok := filesystem.(fileOwnerReader); ok {
+		if owner, err := reader.FileOwner(path); err == nil {
+			return owner
+		}
+	}
+	return ""
+}
+
 // ScannerFilesystem is the filesystem surface required to discover and
 // account for a scan tree. It deliberately excludes user-facing desktop
 // operations so scanners can later receive only the dependency they need.
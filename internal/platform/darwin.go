@@ -34,6 +34,11 @@ func (Darwin) UsageFor(_ string, fi os.FileInfo) FileUsage {
 		MetadataError: fmt.Errorf("native macOS file metadata is unavailable"),
 	}
 }
+
+func (Darwin) FileOwner(p string) (string, error) {
+	return unixFileOwner(p)
+}
+
 func (Darwin) OpenInFileBrowser(p string) error {
 	if info, err := os.Stat(p); err == nil && !info.IsDir() {
 		return exec.Command("open", "-R", p).Run() // reveal
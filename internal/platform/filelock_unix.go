@@ -0,0 +1,24 @@
+//go:build linux || darwin
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// LockFile waits for an exclusive advisory lock on file, which other
+// processes honour when they lock the same file.
+func LockFile(file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// UnlockFile releases a lock taken with LockFile.
+func UnlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile waits for an exclusive lock on the first byte of file, which
+// other processes honour when they lock the same file.
+func LockFile(file *os.File) error {
+	return windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
+
+// UnlockFile releases a lock taken with LockFile.
+func UnlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
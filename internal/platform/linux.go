@@ -38,6 +38,11 @@ func (Linux) UsageFor(_ string, fi os.FileInfo) FileUsage {
 		MetadataError: fmt.Errorf("native Linux file metadata is unavailable"),
 	}
 }
+
+func (Linux) FileOwner(p string) (string, error) {
+	return unixFileOwner(p)
+}
+
 func (Linux) OpenInFileBrowser(p string) error {
 	if info, err := os.Stat(p); err == nil && !info.IsDir() {
 		uri := linuxFileURI(p)
@@ -175,27 +180,19 @@ func linuxFileURI(path string) string {
 	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
 }
 
-func (Linux) MoveToTrash(p string) error {
-	commands := [][]string{
-		{"gio", "trash", "--", p},
-		{"kioclient6", "move", p, "trash:/"},
-		{"kioclient5", "move", p, "trash:/"},
-	}
-	var lastErr error
-	for _, command := range commands {
-		if _, err := exec.LookPath(command[0]); err != nil {
-			continue
-		}
-		if err := exec.Command(command[0], command[1:]...).Run(); err == nil {
-			return nil
-		} else {
-			lastErr = err
-		}
-	}
-	if lastErr != nil {
-		return fmt.Errorf("move to Trash: %w", lastErr)
+func (l Linux) MoveToTrash(p string) error {
+	_, err := l.MoveToTrashWithPath(p)
+	return err
+}
+
+// MoveToTrashWithPath moves p to Trash and returns the files/<name> path it
+// was given there.
+func (Linux) MoveToTrashWithPath(p string) (string, error) {
+	trash, err := newXDGTrash()
+	if err != nil {
+		return "", fmt.Errorf("move to Trash: %w", err)
 	}
-	return fmt.Errorf("no desktop Trash service is available")
+	return trash.moveToTrash(p)
 }
 
 func (Linux) IsTrashRoot(p string) bool {
@@ -281,30 +278,12 @@ func linuxSafeSharedTrash(path string) bool {
 	return err == nil && info.IsDir() && info.Mode()&os.ModeSymlink == 0 && info.Mode()&os.ModeSticky != 0
 }
 
-func (l Linux) EmptyTrash(p string) error {
-	if !l.IsTrashRoot(p) {
-		return fmt.Errorf("the selected folder is not a supported Trash root")
-	}
-	commands := [][]string{
-		{"gio", "trash", "--empty"},
-		{"ktrash6", "--empty"},
-		{"ktrash5", "--empty"},
-	}
-	var lastErr error
-	for _, command := range commands {
-		if _, err := exec.LookPath(command[0]); err != nil {
-			continue
-		}
-		if err := exec.Command(command[0], command[1:]...).Run(); err == nil {
-			return nil
-		} else {
-			lastErr = err
-		}
-	}
-	if lastErr != nil {
-		return fmt.Errorf("empty Trash: %w", lastErr)
+func (Linux) EmptyTrash(p string) error {
+	trash, err := newXDGTrash()
+	if err != nil {
+		return fmt.Errorf("empty Trash: %w", err)
 	}
-	return fmt.Errorf("no desktop Trash service capable of emptying Trash is available")
+	return trash.empty(p)
 }
 
 func (Linux) DefaultStartPath() string {
@@ -8,6 +8,22 @@ import (
 	"github.com/shirou/gopsutil/v3/disk"
 )
 
+func (Darwin) MountTable() ([]MountInfo, error) {
+	partitions, err := disk.Partitions(true)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]MountInfo, 0, len(partitions))
+	for _, partition := range partitions {
+		result = append(result, MountInfo{
+			MountPoint:     filepath.Clean(partition.Mountpoint),
+			FilesystemType: partition.Fstype,
+			Source:         partition.Device,
+		})
+	}
+	return result, nil
+}
+
 func (Darwin) IsMountRoot(path string) bool {
 	partitions, err := disk.Partitions(true)
 	if err != nil {
@@ -27,8 +27,27 @@ func (Linux) IsMountRoot(path string) bool {
 	return found
 }
 
+func (Linux) MountTable() ([]MountInfo, error) {
+	file, err := os.Open(linuxMountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return linuxMountTable(file), nil
+}
+
 func linuxMountPoints(reader io.Reader) map[string]struct{} {
 	result := make(map[string]struct{})
+	for _, mount := range linuxMountTable(reader) {
+		result[mount.MountPoint] = struct{}{}
+	}
+	return result
+}
+
+// linuxMountTable parses mountinfo lines of the form
+// "id parent major:minor root mountpoint options [optional...] - fstype source superoptions".
+func linuxMountTable(reader io.Reader) []MountInfo {
+	var result []MountInfo
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		fields := strings.Fields(scanner.Text())
@@ -36,9 +55,25 @@ func linuxMountPoints(reader io.Reader) map[string]struct{} {
 			continue
 		}
 		mountPoint, ok := decodeLinuxMountInfoPath(fields[4])
-		if ok {
-			result[filepath.Clean(mountPoint)] = struct{}{}
+		if !ok {
+			continue
+		}
+		mount := MountInfo{MountPoint: filepath.Clean(mountPoint), Device: fields[2]}
+		for index := 6; index < len(fields); index++ {
+			if fields[index] != "-" {
+				continue
+			}
+			if index+1 < len(fields) {
+				mount.FilesystemType = fields[index+1]
+			}
+			if index+2 < len(fields) {
+				if source, ok := decodeLinuxMountInfoPath(fields[index+2]); ok {
+					mount.Source = source
+				}
+			}
+			break
 		}
+		result = append(result, mount)
 	}
 	return result
 }
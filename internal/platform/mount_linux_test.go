@@ -3,6 +3,7 @@
 package platform
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -19,6 +20,21 @@ func TestLinuxMountPointsParsesEscapedAndBindMounts(t *testing.T) {
 	}
 }
 
+func TestLinuxMountTableReadsFilesystemDeviceAndSource(t *testing.T) {
+	contents := "29 23 8:1 / / rw,relatime shared:1 - ext4 /dev/root rw\n" +
+		"36 29 8:17 / /srv/My\\040Disk rw,nosuid master:2 - xfs /dev/sdb1 rw\n" +
+		"41 29 0:52 / /mnt/share rw - cifs //server/share\\040name rw\n"
+	got := linuxMountTable(strings.NewReader(contents))
+	want := []MountInfo{
+		{MountPoint: "/", FilesystemType: "ext4", Device: "8:1", Source: "/dev/root"},
+		{MountPoint: "/srv/My Disk", FilesystemType: "xfs", Device: "8:17", Source: "/dev/sdb1"},
+		{MountPoint: "/mnt/share", FilesystemType: "cifs", Device: "0:52", Source: "//server/share name"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("linuxMountTable() = %+v, want %+v", got, want)
+	}
+}
+
 func TestLinuxRootIsMountRoot(t *testing.T) {
 	if !(Linux{}).IsMountRoot("/") {
 		t.Fatal("the root filesystem was not recognized as a mount root")
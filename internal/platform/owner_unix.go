@@ -0,0 +1,29 @@
+//go:build linux || darwin
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// unixFileOwner returns the user name owning path, or its numeric UID when
+// the account cannot be resolved.
+func unixFileOwner(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", fmt.Errorf("owner of %s is unavailable", path)
+	}
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	if account, err := user.LookupId(uid); err == nil {
+		return account.Username, nil
+	}
+	return uid, nil
+}
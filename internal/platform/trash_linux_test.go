@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestLinuxTrashRootClassification(t *testing.T) {
@@ -134,6 +135,46 @@ func TestLinuxRestoreTrashItem(t *testing.T) {
 	}
 }
 
+func TestLinuxLocatesNewestTrashedItem(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	trashRoot := filepath.Join(dataHome, "Trash")
+	for _, dir := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(trashRoot, dir), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	originalPath := filepath.Join(t.TempDir(), "report.pdf")
+	since := time.Now()
+	for name, modTime := range map[string]time.Time{
+		"report.pdf":   since.Add(-time.Hour),
+		"report.2.pdf": since.Add(time.Second),
+	} {
+		if err := os.WriteFile(filepath.Join(trashRoot, "files", name), []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		infoPath := filepath.Join(trashRoot, "info", name+".trashinfo")
+		metadata := "[Trash Info]\nPath=" + url.PathEscape(originalPath) + "\nDeletionDate=2026-10-18T12:00:00\n"
+		if err := os.WriteFile(infoPath, []byte(metadata), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(infoPath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := (Linux{}).TrashedItemPath(originalPath, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(trashRoot, "files", "report.2.pdf"); found != want {
+		t.Fatalf("TrashedItemPath() = %q, want %q", found, want)
+	}
+	if _, err := (Linux{}).TrashedItemPath(originalPath, since.Add(time.Hour)); err == nil {
+		t.Fatal("an item trashed before the requested time was returned")
+	}
+}
+
 func TestLinuxPermanentlyDeletesTrashItemAndMetadata(t *testing.T) {
 	dataHome := t.TempDir()
 	t.Setenv("XDG_DATA_HOME", dataHome)
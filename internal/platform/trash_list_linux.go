@@ -0,0 +1,122 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ListTrash enumerates the home Trash and the $topdir/.Trash/$uid and
+// $topdir/.Trash-$uid directories of every mounted filesystem.
+func (l Linux) ListTrash() ([]TrashItem, error) {
+	trash, err := newXDGTrash()
+	if err != nil {
+		return nil, fmt.Errorf("list Trash: %w", err)
+	}
+	// Without a mount table only the home Trash can be found.
+	mounts, _ := l.MountTable()
+	mountPoints := make([]string, 0, len(mounts))
+	for _, mount := range mounts {
+		mountPoints = append(mountPoints, mount.MountPoint)
+	}
+	return trash.list(mountPoints)
+}
+
+// accessibleRoots returns the home Trash and the per-volume Trash directories
+// at the top of mountPoints that pass the checks used to classify Trash.
+func (t xdgTrash) accessibleRoots(mountPoints []string) []string {
+	uidText := strconv.Itoa(t.uid)
+	seen := make(map[string]struct{})
+	var roots []string
+	add := func(root string) {
+		root = filepath.Clean(root)
+		if _, ok := seen[root]; ok || !isLinuxTrashRoot(root, t.dataHome, t.uid, t.isMountRoot) {
+			return
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, root)
+	}
+	add(t.homeTrash())
+	for _, mountPoint := range mountPoints {
+		add(filepath.Join(mountPoint, ".Trash", uidText))
+		add(filepath.Join(mountPoint, ".Trash-"+uidText))
+	}
+	return roots
+}
+
+// list reads every accessible Trash root. Unreadable roots are skipped; an
+// error is returned only when no root could be read.
+func (t xdgTrash) list(mountPoints []string) ([]TrashItem, error) {
+	var items []TrashItem
+	var firstErr error
+	read := 0
+	for _, root := range t.accessibleRoots(mountPoints) {
+		rootItems, err := listXDGTrashRoot(root)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		items = append(items, rootItems...)
+	}
+	if read == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return items, nil
+}
+
+func listXDGTrashRoot(root string) ([]TrashItem, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "files"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read Trash %s: %w", root, err)
+	}
+	items := make([]TrashItem, 0, len(entries))
+	for _, entry := range entries {
+		item := TrashItem{
+			Path:      filepath.Join(root, "files", entry.Name()),
+			TrashRoot: root,
+			IsFolder:  entry.IsDir(),
+		}
+		// Items without readable metadata are still listed so they can be
+		// deleted; they cannot be restored.
+		item.OriginalPath, item.DeletedAt, _ = readFreeDesktopTrashInfoDetails(filepath.Join(root, "info", entry.Name()+".trashinfo"), root)
+		item.AllocatedSize = linuxAllocatedTreeSize(item.Path)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// linuxAllocatedTreeSize sums the allocated size below path, counting each
+// hard-linked file once.
+func linuxAllocatedTreeSize(path string) int64 {
+	var total int64
+	seen := make(map[FileIdentity]struct{})
+	_ = filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		usage := (Linux{}).UsageFor(current, info)
+		if usage.HasIdentity && usage.LinkCount > 1 {
+			if _, ok := seen[usage.Identity]; ok {
+				return nil
+			}
+			seen[usage.Identity] = struct{}{}
+		}
+		total += usage.AllocatedSize
+		return nil
+	})
+	return total
+}
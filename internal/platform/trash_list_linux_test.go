@@ -0,0 +1,54 @@
+//go:build linux
+
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestXDGTrashListsHomeAndVolumeTrashes(t *testing.T) {
+	volume := t.TempDir()
+	trash := testXDGTrash(t, volume)
+	homeItem := filepath.Join(t.TempDir(), "notes.txt")
+	volumeItem := filepath.Join(volume, "media", "clip.mkv")
+	writeTrashTestFile(t, homeItem)
+	writeTrashTestFile(t, volumeItem)
+	for _, path := range []string{homeItem, volumeItem} {
+		if _, err := trash.moveToTrash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphan := filepath.Join(trash.homeTrash(), "files", "orphan")
+	writeTrashTestFile(t, orphan)
+
+	items, err := trash.list([]string{volume, filepath.Join(volume, "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byOriginal := make(map[string]TrashItem)
+	for _, item := range items {
+		byOriginal[item.OriginalPath] = item
+	}
+	if len(items) != 3 || len(byOriginal) != 3 {
+		t.Fatalf("listed Trash items = %+v", items)
+	}
+	deleted := trash.now().Truncate(time.Second)
+	for _, original := range []string{homeItem, volumeItem} {
+		item, ok := byOriginal[original]
+		if !ok || !item.DeletedAt.Equal(deleted) || item.AllocatedSize <= 0 || item.IsFolder {
+			t.Fatalf("listed item for %s = %+v", original, item)
+		}
+		if _, err := os.Lstat(item.Path); err != nil {
+			t.Fatalf("listed Trash path is missing: %v", err)
+		}
+	}
+	if byOriginal[volumeItem].TrashRoot == trash.homeTrash() {
+		t.Fatal("the volume item was attributed to the home Trash")
+	}
+	if item := byOriginal[""]; item.Path != orphan || !item.DeletedAt.IsZero() {
+		t.Fatalf("item without metadata = %+v", item)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func linuxTrashRootContaining(p string) (clean, root string, err error) {
@@ -51,11 +53,73 @@ func (Linux) TrashRestoreInfo(p string) (TrashRestoreInfo, error) {
 	}
 	targetPath := filepath.Join(root, "files", parts[1])
 	infoPath := filepath.Join(root, "info", parts[1]+".trashinfo")
-	originalPath, err := readFreeDesktopTrashInfo(infoPath, root)
+	originalPath, deleted, err := readFreeDesktopTrashInfoDetails(infoPath, root)
 	if err != nil {
 		return TrashRestoreInfo{}, err
 	}
-	return TrashRestoreInfo{TargetPath: targetPath, OriginalPath: originalPath}, nil
+	return TrashRestoreInfo{TargetPath: targetPath, OriginalPath: originalPath, DeletedAt: deleted}, nil
+}
+
+// linuxTrashRootsFor lists the Trash roots MoveToTrash may use for p: the home
+// Trash and the per-user Trash directories at the top of p's mount.
+func linuxTrashRootsFor(p string) []string {
+	var roots []string
+	linuxPlatform := Linux{}
+	if dataHome, ok := linuxTrashConfiguration(); ok {
+		roots = append(roots, filepath.Join(dataHome, "Trash"))
+	}
+	top := filepath.Dir(p)
+	for !linuxPlatform.IsMountRoot(top) && filepath.Dir(top) != top {
+		top = filepath.Dir(top)
+	}
+	uidText := strconv.Itoa(os.Getuid())
+	for _, candidate := range []string{filepath.Join(top, ".Trash", uidText), filepath.Join(top, ".Trash-"+uidText)} {
+		if linuxPlatform.IsTrashRoot(candidate) {
+			roots = append(roots, candidate)
+		}
+	}
+	return roots
+}
+
+func (Linux) TrashedItemPath(originalPath string, since time.Time) (string, error) {
+	clean, err := filepath.Abs(originalPath)
+	if err != nil {
+		return "", err
+	}
+	clean = filepath.Clean(clean)
+	// .trashinfo deletion dates have one-second resolution.
+	since = since.Truncate(time.Second)
+	var found string
+	var foundTime time.Time
+	for _, root := range linuxTrashRootsFor(clean) {
+		entries, err := os.ReadDir(filepath.Join(root, "info"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+			if !ok || entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.ModTime().Before(since) || (found != "" && !info.ModTime().After(foundTime)) {
+				continue
+			}
+			recorded, err := readFreeDesktopTrashInfo(filepath.Join(root, "info", entry.Name()), root)
+			if err != nil || recorded != clean {
+				continue
+			}
+			target := filepath.Join(root, "files", name)
+			if _, err := os.Lstat(target); err != nil {
+				continue
+			}
+			found, foundTime = target, info.ModTime()
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("the trashed item for %s was not found", clean)
+	}
+	return found, nil
 }
 
 func (l Linux) RestoreTrashItem(p string) error {
@@ -77,6 +141,7 @@ func (l Linux) RestoreTrashItem(p string) error {
 	}
 	_, root, _ := linuxTrashRootContaining(p)
 	_ = os.Remove(filepath.Join(root, "info", filepath.Base(info.TargetPath)+".trashinfo"))
+	_ = updateDirectorySizes(root, "", "")
 	return nil
 }
 
@@ -106,6 +171,7 @@ func (Linux) DeleteTrashItemPermanently(p string) error {
 		if err := os.Remove(infoPath); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("remove Trash metadata: %w", err)
 		}
+		_ = updateDirectorySizes(root, "", "")
 		return nil
 	case "info":
 		if len(parts) != 2 || !strings.HasSuffix(parts[1], ".trashinfo") {
@@ -125,41 +191,53 @@ func (Linux) DeleteTrashItemPermanently(p string) error {
 }
 
 func readFreeDesktopTrashInfo(path, trashRoot string) (string, error) {
+	originalPath, _, err := readFreeDesktopTrashInfoDetails(path, trashRoot)
+	return originalPath, err
+}
+
+// readFreeDesktopTrashInfoDetails returns the original path and deletion date
+// recorded in a .trashinfo file. A missing or malformed DeletionDate yields
+// the zero time rather than an error, because the item can still be restored.
+func readFreeDesktopTrashInfoDetails(path, trashRoot string) (string, time.Time, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("read Trash metadata: %w", err)
+		return "", time.Time{}, fmt.Errorf("read Trash metadata: %w", err)
 	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
 	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "[Trash Info]" {
-		return "", fmt.Errorf("Trash metadata has an invalid header")
+		return "", time.Time{}, fmt.Errorf("Trash metadata has an invalid header")
 	}
 	var encodedPath string
+	var deleted time.Time
 	for scanner.Scan() {
 		line := scanner.Text()
 		if encodedPath == "" && strings.HasPrefix(line, "Path=") {
 			encodedPath = strings.TrimPrefix(line, "Path=")
 		}
+		if value, ok := strings.CutPrefix(line, "DeletionDate="); ok && deleted.IsZero() {
+			deleted, _ = time.ParseInLocation("2006-01-02T15:04:05", strings.TrimSpace(value), time.Local)
+		}
 	}
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("read Trash metadata: %w", err)
+		return "", time.Time{}, fmt.Errorf("read Trash metadata: %w", err)
 	}
 	decodedPath, err := url.PathUnescape(encodedPath)
 	if err != nil || decodedPath == "" {
-		return "", fmt.Errorf("Trash metadata has an invalid original path")
+		return "", time.Time{}, fmt.Errorf("Trash metadata has an invalid original path")
 	}
 	if filepath.IsAbs(decodedPath) {
-		return filepath.Clean(decodedPath), nil
+		return filepath.Clean(decodedPath), deleted, nil
 	}
 	for _, component := range strings.Split(filepath.Clean(decodedPath), string(os.PathSeparator)) {
 		if component == ".." {
-			return "", fmt.Errorf("Trash metadata contains an unsafe relative path")
+			return "", time.Time{}, fmt.Errorf("Trash metadata contains an unsafe relative path")
 		}
 	}
 	base := filepath.Dir(trashRoot)
 	if filepath.Base(base) == ".Trash" {
 		base = filepath.Dir(base)
 	}
-	return filepath.Clean(filepath.Join(base, decodedPath)), nil
+	return filepath.Clean(filepath.Join(base, decodedPath)), deleted, nil
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"unicode/utf16"
 )
 
@@ -52,11 +53,58 @@ func (Windows) TrashRestoreInfo(p string) (TrashRestoreInfo, error) {
 	if !direct {
 		return TrashRestoreInfo{}, fmt.Errorf("select the top-level Recycle Bin item to restore it")
 	}
-	originalPath, err := readWindowsRecycleInfo(infoPath)
+	originalPath, deleted, err := readWindowsRecycleInfoDetails(infoPath)
 	if err != nil {
 		return TrashRestoreInfo{}, err
 	}
-	return TrashRestoreInfo{TargetPath: dataPath, OriginalPath: originalPath}, nil
+	return TrashRestoreInfo{TargetPath: dataPath, OriginalPath: originalPath, DeletedAt: deleted}, nil
+}
+
+func (w Windows) TrashedItemPath(originalPath string, since time.Time) (string, error) {
+	clean := w.Canonicalize(originalPath)
+	volume := filepath.VolumeName(clean)
+	if len(volume) != 2 || volume[1] != ':' {
+		return "", fmt.Errorf("path is not on a drive-letter volume")
+	}
+	root := filepath.Clean(volume + `\$Recycle.Bin`)
+	containers, err := os.ReadDir(root)
+	if err != nil {
+		return "", fmt.Errorf("read Recycle Bin: %w", err)
+	}
+	var found string
+	var foundTime time.Time
+	for _, container := range containers {
+		if !container.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, container.Name()))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if len(name) < 3 || !strings.EqualFold(name[:2], "$I") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.ModTime().Before(since) || (found != "" && !info.ModTime().After(foundTime)) {
+				continue
+			}
+			recorded, err := readWindowsRecycleInfo(filepath.Join(root, container.Name(), name))
+			if err != nil || !strings.EqualFold(filepath.Clean(recorded), clean) {
+				continue
+			}
+			target := filepath.Join(root, container.Name(), "$R"+name[2:])
+			if _, err := os.Lstat(target); err != nil {
+				continue
+			}
+			found, foundTime = target, info.ModTime()
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("the recycled item for %s was not found", clean)
+	}
+	return found, nil
 }
 
 func (w Windows) RestoreTrashItem(p string) error {
@@ -107,12 +155,27 @@ func (Windows) DeleteTrashItemPermanently(p string) error {
 }
 
 func readWindowsRecycleInfo(path string) (string, error) {
+	originalPath, _, err := readWindowsRecycleInfoDetails(path)
+	return originalPath, err
+}
+
+// windowsFileTimeEpochOffset is the number of 100-nanosecond intervals
+// between 1601-01-01, the FILETIME epoch, and the Unix epoch.
+const windowsFileTimeEpochOffset = 116444736000000000
+
+// readWindowsRecycleInfoDetails returns the original path and deletion time
+// recorded in a $I file. A zero FILETIME yields the zero time.
+func readWindowsRecycleInfoDetails(path string) (string, time.Time, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("read Recycle Bin metadata: %w", err)
+		return "", time.Time{}, fmt.Errorf("read Recycle Bin metadata: %w", err)
 	}
 	if len(data) < 28 {
-		return "", fmt.Errorf("Recycle Bin metadata is truncated")
+		return "", time.Time{}, fmt.Errorf("Recycle Bin metadata is truncated")
+	}
+	var deleted time.Time
+	if fileTime := int64(binary.LittleEndian.Uint64(data[16:24])); fileTime > windowsFileTimeEpochOffset {
+		deleted = time.Unix(0, (fileTime-windowsFileTimeEpochOffset)*100)
 	}
 	version := binary.LittleEndian.Uint64(data[:8])
 	var encoded []byte
@@ -122,11 +185,11 @@ func readWindowsRecycleInfo(path string) (string, error) {
 	case 2:
 		characters := int(binary.LittleEndian.Uint32(data[24:28]))
 		if characters <= 0 || characters > (len(data)-28)/2 {
-			return "", fmt.Errorf("Recycle Bin metadata has an invalid path length")
+			return "", time.Time{}, fmt.Errorf("Recycle Bin metadata has an invalid path length")
 		}
 		encoded = data[28 : 28+characters*2]
 	default:
-		return "", fmt.Errorf("unsupported Recycle Bin metadata version %d", version)
+		return "", time.Time{}, fmt.Errorf("unsupported Recycle Bin metadata version %d", version)
 	}
 	units := make([]uint16, 0, len(encoded)/2)
 	for index := 0; index+1 < len(encoded); index += 2 {
@@ -138,7 +201,7 @@ func readWindowsRecycleInfo(path string) (string, error) {
 	}
 	originalPath := filepath.Clean(string(utf16.Decode(units)))
 	if len(units) == 0 || !filepath.IsAbs(originalPath) {
-		return "", fmt.Errorf("Recycle Bin metadata does not contain an absolute original path")
+		return "", time.Time{}, fmt.Errorf("Recycle Bin metadata does not contain an absolute original path")
 	}
-	return originalPath, nil
+	return originalPath, deleted, nil
 }
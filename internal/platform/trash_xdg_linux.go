@@ -0,0 +1,433 @@
+//go:build linux
+
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// xdgTrash implements the freedesktop.org Trash specification without a
+// desktop service. Items on the home filesystem go to the home Trash; items on
+// other filesystems go to $topdir/.Trash/$uid or $topdir/.Trash-$uid, and fall
+// back to the home Trash by copying when neither can be used.
+type xdgTrash struct {
+	dataHome    string
+	uid         int
+	isMountRoot func(string) bool
+	deviceOf    func(string) (uint64, error)
+	now         func() time.Time
+}
+
+func newXDGTrash() (xdgTrash, error) {
+	dataHome, ok := linuxTrashConfiguration()
+	if !ok {
+		return xdgTrash{}, fmt.Errorf("the home directory is unavailable")
+	}
+	return xdgTrash{
+		dataHome:    dataHome,
+		uid:         os.Getuid(),
+		isMountRoot: (Linux{}).IsMountRoot,
+		deviceOf:    linuxDevice,
+		now:         time.Now,
+	}, nil
+}
+
+func linuxDevice(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("device of %s is unavailable", path)
+	}
+	return uint64(stat.Dev), nil
+}
+
+func (t xdgTrash) homeTrash() string {
+	return filepath.Join(t.dataHome, "Trash")
+}
+
+// existingAncestor returns path or its nearest existing parent, so the device
+// of a home Trash that has not been created yet can be compared.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// prepareRoot creates the files and info directories of a Trash root and
+// confirms that the result passes the same checks used to classify Trash.
+func (t xdgTrash) prepareRoot(root string) error {
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return err
+	}
+	if !isLinuxTrashRoot(root, t.dataHome, t.uid, t.isMountRoot) {
+		return fmt.Errorf("%s is not a safe Trash directory", root)
+	}
+	for _, dir := range []string{"files", "info"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// volumeTrash returns the per-volume Trash for an item below topdir, trying
+// $topdir/.Trash/$uid before $topdir/.Trash-$uid as the specification asks.
+func (t xdgTrash) volumeTrash(topdir string) (string, error) {
+	uidText := strconv.Itoa(t.uid)
+	shared := filepath.Join(topdir, ".Trash")
+	if linuxSafeSharedTrash(shared) {
+		root := filepath.Join(shared, uidText)
+		if err := t.prepareRoot(root); err == nil {
+			return root, nil
+		}
+	}
+	root := filepath.Join(topdir, ".Trash-"+uidText)
+	if err := t.prepareRoot(root); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+func (t xdgTrash) topdir(path string) string {
+	current := filepath.Dir(path)
+	for !t.isMountRoot(current) && filepath.Dir(current) != current {
+		current = filepath.Dir(current)
+	}
+	return current
+}
+
+// chooseRoot picks the Trash root for path. crossDevice is set when the item
+// has to be copied because it lives on another filesystem than the root.
+func (t xdgTrash) chooseRoot(path string) (root, topdir string, crossDevice bool, err error) {
+	itemDevice, err := t.deviceOf(path)
+	if err != nil {
+		return "", "", false, err
+	}
+	home := t.homeTrash()
+	homeDevice, homeErr := t.deviceOf(existingAncestor(home))
+	if homeErr == nil && homeDevice == itemDevice {
+		return home, "", false, t.prepareRoot(home)
+	}
+	topdir = t.topdir(path)
+	if volumeRoot, volumeErr := t.volumeTrash(topdir); volumeErr == nil {
+		return volumeRoot, topdir, false, nil
+	}
+	return home, "", true, t.prepareRoot(home)
+}
+
+func trashInfoPath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+func trashInfoContent(originalPath string, deleted time.Time) []byte {
+	return []byte("[Trash Info]\nPath=" + trashInfoPath(originalPath) + "\nDeletionDate=" + deleted.Format("2006-01-02T15:04:05") + "\n")
+}
+
+// collisionName returns the attempt-th candidate name for base in Trash:
+// "report.pdf", "report.2.pdf", "report.3.pdf", ...
+func collisionName(base string, attempt int) string {
+	if attempt == 1 {
+		return base
+	}
+	extension := filepath.Ext(base)
+	if extension == base {
+		extension = ""
+	}
+	return strings.TrimSuffix(base, extension) + "." + strconv.Itoa(attempt) + extension
+}
+
+// reserveName atomically creates the .trashinfo file of a Trash name that is
+// free in both info and files, and returns that name. The content is written
+// to a temporary file and linked into place so other Trash implementations
+// never see a partial file; where hard links are unsupported, the name is
+// reserved with O_EXCL instead.
+func reserveName(root, base string, content []byte) (string, error) {
+	infoDir := filepath.Join(root, "info")
+	temp, err := os.CreateTemp(infoDir, ".trashinfo-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create Trash metadata: %w", err)
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath)
+	_, writeErr := temp.Write(content)
+	syncErr := temp.Sync()
+	if closeErr := temp.Close(); writeErr != nil || syncErr != nil || closeErr != nil {
+		return "", fmt.Errorf("write Trash metadata: %w", errors.Join(writeErr, syncErr, closeErr))
+	}
+
+	linkSupported := true
+	for attempt := 1; attempt <= 1000; attempt++ {
+		name := collisionName(base, attempt)
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		if _, err := os.Lstat(filepath.Join(root, "files", name)); err == nil {
+			continue
+		}
+		if linkSupported {
+			err := os.Link(tempPath, infoPath)
+			if err == nil {
+				return name, nil
+			}
+			if errors.Is(err, fs.ErrExist) {
+				continue
+			}
+			linkSupported = false
+		}
+		file, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("create Trash metadata: %w", err)
+		}
+		_, writeErr := file.Write(content)
+		if closeErr := file.Close(); writeErr != nil || closeErr != nil {
+			os.Remove(infoPath)
+			return "", fmt.Errorf("write Trash metadata: %w", errors.Join(writeErr, closeErr))
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("no free Trash name for %s", base)
+}
+
+// moveToTrash moves path into Trash and returns its new location. A move
+// across file systems that leaves part of the original behind returns the
+// location together with a *PartialTrashError.
+func (t xdgTrash) moveToTrash(path string) (string, error) {
+	clean, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	clean = filepath.Clean(clean)
+	info, err := os.Lstat(clean)
+	if err != nil {
+		return "", err
+	}
+	if isLinuxPathInTrash(clean, t.dataHome, t.uid, t.isMountRoot) {
+		return "", fmt.Errorf("%s is already in Trash", clean)
+	}
+	root, topdir, crossDevice, err := t.chooseRoot(clean)
+	if err != nil {
+		return "", fmt.Errorf("prepare Trash: %w", err)
+	}
+
+	recordedPath := clean
+	if topdir != "" {
+		// Per-volume Trash stores paths relative to the volume so they
+		// survive a different mount point.
+		if relative, err := filepath.Rel(topdir, clean); err == nil {
+			recordedPath = relative
+		}
+	}
+	name, err := reserveName(root, filepath.Base(clean), trashInfoContent(recordedPath, t.now()))
+	if err != nil {
+		return "", err
+	}
+	infoPath := filepath.Join(root, "info", name+".trashinfo")
+	target := filepath.Join(root, "files", name)
+
+	err = os.Rename(clean, target)
+	if errors.Is(err, syscall.EXDEV) {
+		crossDevice = true
+	}
+	var partialErr error
+	if err != nil && crossDevice {
+		if err = copyTree(clean, target); err != nil {
+			os.RemoveAll(target)
+		} else if removeErr := os.RemoveAll(clean); removeErr != nil {
+			// The copy in Trash is complete, so keep it and its metadata and
+			// report both locations.
+			partialErr = &PartialTrashError{Path: clean, TrashPath: target, Err: removeErr}
+		}
+	}
+	if err != nil {
+		if _, statErr := os.Lstat(target); os.IsNotExist(statErr) {
+			os.Remove(infoPath)
+		}
+		return "", fmt.Errorf("move to Trash: %w", err)
+	}
+	if info.IsDir() {
+		// The cache is optional; readers recompute sizes without it.
+		_ = updateDirectorySizes(root, name, infoPath)
+	}
+	return target, partialErr
+}
+
+// copyTree copies a file, symbolic link or directory tree for moves across
+// filesystems, keeping permissions and modification times.
+func copyTree(source, target string) error {
+	info, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(source)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case info.IsDir():
+		if err := os.Mkdir(target, info.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(source, entry.Name()), filepath.Join(target, entry.Name())); err != nil {
+				return err
+			}
+		}
+		if err := os.Chmod(target, info.Mode().Perm()); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		in, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s cannot be copied to Trash", source)
+	}
+	return os.Chtimes(target, info.ModTime(), info.ModTime())
+}
+
+func directoryContentSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// updateDirectorySizes rewrites the directorysizes cache of a Trash root. When
+// name is set, its entry is recorded with the modification time of infoPath;
+// entries of items no longer in Trash are dropped either way.
+func updateDirectorySizes(root, name, infoPath string) error {
+	cachePath := filepath.Join(root, "directorysizes")
+	var lines []string
+	if file, err := os.Open(cachePath); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.SplitN(scanner.Text(), " ", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			entryName, err := url.PathUnescape(fields[2])
+			if err != nil || entryName == name {
+				continue
+			}
+			if _, err := os.Lstat(filepath.Join(root, "files", entryName)); err != nil {
+				continue
+			}
+			lines = append(lines, scanner.Text())
+		}
+		file.Close()
+	} else if !os.IsNotExist(err) {
+		return err
+	} else if name == "" {
+		return nil
+	}
+	if name != "" {
+		info, err := os.Stat(infoPath)
+		if err != nil {
+			return err
+		}
+		size := directoryContentSize(filepath.Join(root, "files", name))
+		lines = append(lines, fmt.Sprintf("%d %d %s", size, info.ModTime().Unix(), url.PathEscape(name)))
+	}
+
+	temp, err := os.CreateTemp(root, ".directorysizes-*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath)
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if _, err := temp.WriteString(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, cachePath)
+}
+
+// empty permanently deletes everything in the Trash root.
+func (t xdgTrash) empty(root string) error {
+	if !isLinuxTrashRoot(root, t.dataHome, t.uid, t.isMountRoot) {
+		return fmt.Errorf("the selected folder is not a supported Trash root")
+	}
+	roots := []string{root}
+	if filepath.Base(root) == ".Trash" {
+		// The shared container holds the per-user Trash of this user.
+		roots = []string{filepath.Join(root, strconv.Itoa(t.uid))}
+	}
+	var errs []error
+	for _, current := range roots {
+		// Delete files before metadata so an interrupted run never leaves
+		// items without a record of where they came from.
+		for _, dir := range []string{"files", "info"} {
+			entries, err := os.ReadDir(filepath.Join(current, dir))
+			if err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			for _, entry := range entries {
+				if err := os.RemoveAll(filepath.Join(current, dir, entry.Name())); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		if err := os.Remove(filepath.Join(current, "directorysizes")); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("empty Trash: %w", err)
+	}
+	return nil
+}
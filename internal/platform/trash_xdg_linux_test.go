@@ -0,0 +1,227 @@
+//go:build linux
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testXDGTrash(t *testing.T, volume string) xdgTrash {
+	t.Helper()
+	return xdgTrash{
+		dataHome: t.TempDir(),
+		uid:      os.Getuid(),
+		isMountRoot: func(path string) bool {
+			return volume != "" && filepath.Clean(path) == filepath.Clean(volume)
+		},
+		deviceOf: func(path string) (uint64, error) {
+			if volume != "" && (path == volume || strings.HasPrefix(path, volume+string(os.PathSeparator))) {
+				return 2, nil
+			}
+			return 1, nil
+		},
+		now: func() time.Time { return time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local) },
+	}
+}
+
+func writeTrashTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXDGTrashMovesToHomeTrashWithUniqueNames(t *testing.T) {
+	trash := testXDGTrash(t, "")
+	homeTrash := filepath.Join(trash.dataHome, "Trash")
+	source := t.TempDir()
+	first := filepath.Join(source, "a", "my report.pdf")
+	second := filepath.Join(source, "b", "my report.pdf")
+	writeTrashTestFile(t, first)
+	writeTrashTestFile(t, second)
+	writeTrashTestFile(t, filepath.Join(homeTrash, "files", "my report.3.pdf"))
+
+	for index, path := range []string{first, second} {
+		target, err := trash.moveToTrash(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := filepath.Join(homeTrash, "files", []string{"my report.pdf", "my report.2.pdf"}[index])
+		if target != want {
+			t.Fatalf("moveToTrash(%q) = %q, want %q", path, target, want)
+		}
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s still exists after moving it to Trash", path)
+		}
+		infoPath := filepath.Join(homeTrash, "info", filepath.Base(target)+".trashinfo")
+		data, err := os.ReadFile(infoPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "Path="+strings.ReplaceAll(path, " ", "%20")+"\n") || !strings.Contains(string(data), "DeletionDate=2026-10-18T09:30:00\n") {
+			t.Fatalf("Trash metadata = %q", data)
+		}
+		if original, err := readFreeDesktopTrashInfo(infoPath, homeTrash); err != nil || original != path {
+			t.Fatalf("recorded original path = %q, %v", original, err)
+		}
+	}
+
+	third := filepath.Join(source, "c", "my report.pdf")
+	writeTrashTestFile(t, third)
+	if target, err := trash.moveToTrash(third); err != nil || filepath.Base(target) != "my report.4.pdf" {
+		t.Fatalf("an orphaned Trash file name was reused: %q, %v", target, err)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(homeTrash, "info", ".trashinfo-*")); len(entries) != 0 {
+		t.Fatalf("temporary metadata files were left behind: %v", entries)
+	}
+}
+
+func TestXDGTrashRecordsDirectorySizesAndEmpties(t *testing.T) {
+	trash := testXDGTrash(t, "")
+	homeTrash := filepath.Join(trash.dataHome, "Trash")
+	folder := filepath.Join(t.TempDir(), "build output")
+	writeTrashTestFile(t, filepath.Join(folder, "one.o"))
+	writeTrashTestFile(t, filepath.Join(folder, "nested", "two.o"))
+
+	if _, err := trash.moveToTrash(folder); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(homeTrash, "directorysizes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 || fields[0] != "8" || fields[2] != "build%20output" {
+		t.Fatalf("directorysizes = %q", data)
+	}
+
+	if err := trash.empty(homeTrash); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"files", "info"} {
+		if entries, err := os.ReadDir(filepath.Join(homeTrash, dir)); err != nil || len(entries) != 0 {
+			t.Fatalf("%s after emptying = %v, %v", dir, entries, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(homeTrash, "directorysizes")); !os.IsNotExist(err) {
+		t.Fatalf("directorysizes survived emptying: %v", err)
+	}
+	if err := trash.empty(t.TempDir()); err == nil {
+		t.Fatal("a folder that is not a Trash root was emptied")
+	}
+}
+
+func TestXDGTrashUsesVolumeTrashes(t *testing.T) {
+	volume := t.TempDir()
+	trash := testXDGTrash(t, volume)
+	uid := strconv.Itoa(trash.uid)
+
+	item := filepath.Join(volume, "media", "clip.mkv")
+	writeTrashTestFile(t, item)
+	target, err := trash.moveToTrash(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateTrash := filepath.Join(volume, ".Trash-"+uid)
+	if target != filepath.Join(privateTrash, "files", "clip.mkv") {
+		t.Fatalf("volume item moved to %q", target)
+	}
+	infoPath := filepath.Join(privateTrash, "info", "clip.mkv.trashinfo")
+	if data, _ := os.ReadFile(infoPath); !strings.Contains(string(data), "Path=media/clip.mkv\n") {
+		t.Fatalf("volume Trash metadata = %q; want a path relative to the volume", data)
+	}
+	if original, err := readFreeDesktopTrashInfo(infoPath, privateTrash); err != nil || original != item {
+		t.Fatalf("recorded original path = %q, %v", original, err)
+	}
+
+	shared := filepath.Join(volume, ".Trash")
+	if err := os.Mkdir(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(shared, 0o777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	writeTrashTestFile(t, item)
+	if target, err := trash.moveToTrash(item); err != nil || target != filepath.Join(shared, uid, "files", "clip.mkv") {
+		t.Fatalf("shared volume Trash move = %q, %v", target, err)
+	}
+}
+
+func TestXDGTrashFallsBackToHomeTrash(t *testing.T) {
+	volume := t.TempDir()
+	trash := testXDGTrash(t, volume)
+	// A file where the private Trash directory belongs makes the volume
+	// Trash unusable.
+	writeTrashTestFile(t, filepath.Join(volume, ".Trash-"+strconv.Itoa(trash.uid)))
+	item := filepath.Join(volume, "notes.txt")
+	writeTrashTestFile(t, item)
+
+	target, err := trash.moveToTrash(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != filepath.Join(trash.dataHome, "Trash", "files", "notes.txt") {
+		t.Fatalf("fallback move = %q", target)
+	}
+}
+
+func TestCopyTreeKeepsContentsAndLinks(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "project")
+	writeTrashTestFile(t, filepath.Join(source, "src", "main.go"))
+	if err := os.Symlink("src/main.go", filepath.Join(source, "entry")); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "project")
+	if err := copyTree(source, target); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(target, "src", "main.go")); err != nil || string(data) != "data" {
+		t.Fatalf("copied file = %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(target, "entry")); err != nil || link != "src/main.go" {
+		t.Fatalf("copied link = %q, %v", link, err)
+	}
+}
+
+func TestXDGTrashReportsOriginalsItCouldNotRemove(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can remove files from read-only folders")
+	}
+	volume := t.TempDir()
+	trash := testXDGTrash(t, volume)
+	writeTrashTestFile(t, filepath.Join(volume, ".Trash-"+strconv.Itoa(trash.uid)))
+	item := filepath.Join(volume, "project")
+	writeTrashTestFile(t, filepath.Join(item, "locked", "notes.txt"))
+	// A read-only folder can be copied but not emptied or renamed.
+	locked := filepath.Join(item, "locked")
+	if err := os.Chmod(locked, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o700) })
+	if err := os.Chmod(volume, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(volume, 0o700) })
+
+	target, err := trash.moveToTrash(item)
+	t.Cleanup(func() { os.Chmod(filepath.Join(target, "locked"), 0o700) })
+	var partial *PartialTrashError
+	if !errors.As(err, &partial) || partial.Path != item || partial.TrashPath != target {
+		t.Fatalf("moveToTrash = %q, %v; want a partial removal error", target, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(target, "locked", "notes.txt")); err != nil || string(data) != "data" {
+		t.Fatalf("the Trash copy is incomplete: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(trash.dataHome, "Trash", "info", "project.trashinfo")); err != nil {
+		t.Fatalf("the Trash metadata was not kept: %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 	"unicode/utf16"
 	"unsafe"
 
@@ -541,6 +542,8 @@ func TestReadWindowsRecycleInfoVersionTwo(t *testing.T) {
 	units := append(utf16.Encode([]rune(originalPath)), 0)
 	data := make([]byte, 28+len(units)*2)
 	binary.LittleEndian.PutUint64(data[:8], 2)
+	deleted := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	binary.LittleEndian.PutUint64(data[16:24], uint64(deleted.UnixNano()/100+windowsFileTimeEpochOffset))
 	binary.LittleEndian.PutUint32(data[24:28], uint32(len(units)))
 	for index, unit := range units {
 		binary.LittleEndian.PutUint16(data[28+index*2:], unit)
@@ -549,11 +552,14 @@ func TestReadWindowsRecycleInfoVersionTwo(t *testing.T) {
 	if err := os.WriteFile(infoPath, data, 0o600); err != nil {
 		t.Fatal(err)
 	}
-	got, err := readWindowsRecycleInfo(infoPath)
+	got, gotDeleted, err := readWindowsRecycleInfoDetails(infoPath)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if got != originalPath {
 		t.Fatalf("original path = %q, want %q", got, originalPath)
 	}
+	if !gotDeleted.Equal(deleted) {
+		t.Fatalf("deletion time = %v, want %v", gotDeleted, deleted)
+	}
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	paletteFileFormat    = "spacebrowser-palettes"
+	maximumPaletteColors = 32
+	maximumPaletteName   = 40
+)
+
+// Palette is a named list of rectangle colors as lowercase #rrggbb strings.
+// AppearanceSettings.Palette selects one by ID; custom palettes use their
+// name as ID.
+type Palette struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Colors  []string `json:"colors"`
+	Builtin bool     `json:"builtin,omitempty"`
+}
+
+// builtinPalettes is the palette table shared by the window, the terminal and
+// image exports. The first entry is the fallback for unknown IDs.
+var builtinPalettes = []Palette{
+	{ID: "default", Name: "Default", Colors: []string{"#ff9b85", "#ffbe76", "#ffe066", "#7bed9f", "#70d6ff", "#a29bfe", "#dfe4ea"}},
+	{ID: "legacy", Name: "SpaceMonger 1.4", Colors: []string{"#ff7f7f", "#ffbf7f", "#ffff00", "#7fff7f", "#7fffff", "#bfbfff", "#bfbfbf", "#ff7fff"}},
+	{ID: "single", Name: "Single tone", Colors: []string{"#9fc5d8"}},
+	{ID: "duotone", Name: "Two tone", Colors: []string{"#f2c078", "#78a6c8"}},
+	{ID: "tricolor", Name: "Three tone", Colors: []string{"#e8846b", "#e3bf62", "#78a88b"}},
+	{ID: "playful", Name: "Playful", Colors: []string{"#ff6b6b", "#ffd93d", "#6bcb77", "#4d96ff", "#c77dff", "#ff8fab", "#72efdd"}},
+	{ID: "monochrome", Name: "Monochrome", Colors: []string{"#f0f0f0", "#dedede", "#cccccc", "#bababa", "#a8a8a8", "#969696", "#848484"}},
+	{ID: "earth", Name: "Earth", Colors: []string{"#d9c7a6", "#c3a982", "#ad8b63", "#96a17b", "#7f9168", "#b98268", "#8f7559"}},
+	{ID: "ocean", Name: "Ocean", Colors: []string{"#c6e8e5", "#a8dadc", "#8ecfd1", "#73c0c5", "#78b7d0", "#91a8d0", "#a7c4bc"}},
+	{ID: "retro", Name: "Retro", Colors: []string{"#e49a78", "#e5bd63", "#a8b47d", "#78a0a8", "#a58aa8", "#c9ae88", "#87949a"}},
+}
+
+// String describes the palette in settings import previews.
+func (p Palette) String() string {
+	return p.Name + ": " + strings.Join(p.Colors, " ")
+}
+
+func builtinPalette(id string) (Palette, bool) {
+	for _, palette := range builtinPalettes {
+		if palette.ID == id {
+			return palette, true
+		}
+	}
+	return Palette{}, false
+}
+
+// paletteColors returns the colors of the palette appearance selects.
+func paletteColors(appearance AppearanceSettings) []string {
+	for _, palette := range appearance.CustomPalettes {
+		if palette.ID == appearance.Palette {
+			return append([]string(nil), palette.Colors...)
+		}
+	}
+	palette, ok := builtinPalette(appearance.Palette)
+	if !ok {
+		palette = builtinPalettes[0]
+	}
+	return append([]string(nil), palette.Colors...)
+}
+
+// normalizeCustomPalettes validates user palettes, which need distinct names
+// that differ from the built-in ones and between 1 and 32 colors.
+func normalizeCustomPalettes(palettes []Palette) ([]Palette, error) {
+	if len(palettes) == 0 {
+		return nil, nil
+	}
+	normalized := make([]Palette, 0, len(palettes))
+	names := make(map[string]struct{}, len(palettes)+len(builtinPalettes))
+	for _, palette := range builtinPalettes {
+		names[strings.ToLower(palette.ID)] = struct{}{}
+		names[strings.ToLower(palette.Name)] = struct{}{}
+	}
+	for _, palette := range palettes {
+		name := strings.TrimSpace(palette.Name)
+		if name == "" {
+			return nil, fmt.Errorf("custom palettes need a name")
+		}
+		if len([]rune(name)) > maximumPaletteName {
+			return nil, fmt.Errorf("palette name %q is longer than %d characters", name, maximumPaletteName)
+		}
+		if _, used := names[strings.ToLower(name)]; used {
+			return nil, fmt.Errorf("palette name %q is already used", name)
+		}
+		names[strings.ToLower(name)] = struct{}{}
+		if len(palette.Colors) == 0 || len(palette.Colors) > maximumPaletteColors {
+			return nil, fmt.Errorf("palette %q needs between 1 and %d colors", name, maximumPaletteColors)
+		}
+		colors := make([]string, len(palette.Colors))
+		for index, color := range palette.Colors {
+			hex, err := normalizeHexColor(color)
+			if err != nil {
+				return nil, fmt.Errorf("palette %q: %w", name, err)
+			}
+			colors[index] = hex
+		}
+		normalized = append(normalized, Palette{ID: name, Name: name, Colors: colors})
+	}
+	return normalized, nil
+}
+
+// normalizeHexColor accepts #rgb and #rrggbb colors and returns #rrggbb in
+// lowercase.
+func normalizeHexColor(color string) (string, error) {
+	hex := strings.ToLower(strings.TrimSpace(color))
+	digits, found := strings.CutPrefix(hex, "#")
+	if found && len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	valid := found && len(digits) == 6
+	for _, digit := range digits {
+		valid = valid && (digit >= '0' && digit <= '9' || digit >= 'a' && digit <= 'f')
+	}
+	if !valid {
+		return "", fmt.Errorf("%q is not a #rrggbb color", color)
+	}
+	return "#" + digits, nil
+}
+
+type paletteFile struct {
+	Format   string    `json:"format"`
+	Palettes []Palette `json:"palettes"`
+}
+
+func encodePalettes(palettes []Palette) ([]byte, error) {
+	normalized, err := normalizeCustomPalettes(palettes)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("there are no palettes to export")
+	}
+	data, err := json.MarshalIndent(paletteFile{Format: paletteFileFormat, Palettes: normalized}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode palettes: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func decodePalettes(data []byte) ([]Palette, error) {
+	var file paletteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode palettes: %w", err)
+	}
+	if file.Format != paletteFileFormat {
+		return nil, fmt.Errorf("the file is not a SpaceBrowser palette file")
+	}
+	palettes, err := normalizeCustomPalettes(file.Palettes)
+	if err != nil {
+		return nil, err
+	}
+	if len(palettes) == 0 {
+		return nil, fmt.Errorf("the palette file is empty")
+	}
+	return palettes, nil
+}
+
+// GetPalettes lists the built-in palettes followed by the saved custom ones.
+func (a *App) GetPalettes() []Palette {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	palettes := make([]Palette, 0, len(builtinPalettes)+len(a.profile.Appearance.CustomPalettes))
+	for _, palette := range builtinPalettes {
+		palette.Builtin = true
+		palette.Colors = append([]string(nil), palette.Colors...)
+		palettes = append(palettes, palette)
+	}
+	for _, palette := range a.profile.Appearance.CustomPalettes {
+		palette.Colors = append([]string(nil), palette.Colors...)
+		palettes = append(palettes, palette)
+	}
+	return palettes
+}
+
+// ExportPalettes asks where to save palettes as JSON and returns the written
+// path. The palettes come from the caller so unsaved edits can be shared.
+func (a *App) ExportPalettes(palettes []Palette) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	data, err := encodePalettes(palettes)
+	if err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Export palettes",
+		DefaultFilename:      "spacebrowser-palettes.json",
+		CanCreateDirectories: true,
+		Filters:              []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("write palettes: %w", err)
+	}
+	return filepath.Clean(path), nil
+}
+
+// ImportPalettes asks for a palette file and returns its validated palettes
+// without saving them; Settings adds them to the appearance being edited.
+func (a *App) ImportPalettes() ([]Palette, error) {
+	if a.ctx == nil {
+		return nil, fmt.Errorf("app not initialized")
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import palettes",
+		Filters: []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read palettes: %w", err)
+	}
+	return decodePalettes(data)
+}
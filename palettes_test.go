@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var okabeIto = Palette{Name: "Okabe-Ito", Colors: []string{"#E69F00", "#56b4e9", "#009E73", "#f0e442", "#0072B2", "#d55e00", "#c7a"}}
+
+func TestNormalizeCustomPalettesRejectsInvalidPalettes(t *testing.T) {
+	for name, palettes := range map[string][]Palette{
+		"unnamed":         {{Colors: []string{"#000000"}}},
+		"built-in id":     {{Name: "Ocean", Colors: []string{"#000000"}}},
+		"built-in name":   {{Name: "spacemonger 1.4", Colors: []string{"#000000"}}},
+		"duplicate name":  {{Name: "Mine", Colors: []string{"#000000"}}, {Name: "mine", Colors: []string{"#ffffff"}}},
+		"no colors":       {{Name: "Mine"}},
+		"named color":     {{Name: "Mine", Colors: []string{"red"}}},
+		"short hex":       {{Name: "Mine", Colors: []string{"#ff00"}}},
+		"not hex":         {{Name: "Mine", Colors: []string{"#gg0000"}}},
+		"too many colors": {{Name: "Mine", Colors: make([]string, maximumPaletteColors+1)}},
+	} {
+		if _, err := normalizeCustomPalettes(palettes); err == nil {
+			t.Errorf("%s: palettes %+v were accepted", name, palettes)
+		}
+	}
+
+	normalized, err := normalizeCustomPalettes([]Palette{okabeIto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Palette{ID: "Okabe-Ito", Name: "Okabe-Ito", Colors: []string{"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc77aa"}}
+	if !reflect.DeepEqual(normalized, []Palette{want}) {
+		t.Fatalf("normalized = %+v, want %+v", normalized, want)
+	}
+}
+
+func TestNormalizeAppearanceSelectsCustomPalettes(t *testing.T) {
+	appearance := defaultAppearanceSettings()
+	appearance.Palette = "Okabe-Ito"
+	if _, err := normalizeAppearance(appearance); err == nil {
+		t.Fatal("an undefined custom palette was accepted")
+	}
+	appearance.CustomPalettes = []Palette{okabeIto}
+	normalized, err := normalizeAppearance(appearance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors := paletteColors(normalized); colors[0] != "#e69f00" || len(colors) != 7 {
+		t.Fatalf("paletteColors = %v", colors)
+	}
+	normalized.Palette = "missing"
+	if colors := paletteColors(normalized); !reflect.DeepEqual(colors, builtinPalettes[0].Colors) {
+		t.Fatalf("unknown palettes should fall back to the default colors, got %v", colors)
+	}
+}
+
+func TestPaletteFilesRoundTrip(t *testing.T) {
+	data, err := encodePalettes([]Palette{okabeIto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	palettes, err := decodePalettes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(palettes) != 1 || palettes[0].Name != "Okabe-Ito" || palettes[0].Colors[6] != "#cc77aa" {
+		t.Fatalf("decoded %+v", palettes)
+	}
+	for _, data := range []string{`{"palettes":[]}`, `{"format":"spacebrowser-palettes","palettes":[]}`, `[]`} {
+		if _, err := decodePalettes([]byte(data)); err == nil {
+			t.Errorf("decodePalettes(%s) succeeded", data)
+		}
+	}
+	if _, err := encodePalettes(nil); err == nil {
+		t.Fatal("exporting no palettes should fail")
+	}
+}
+
+func TestCustomPalettesAreSavedAndListed(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newApp(settingsPath)
+	profile := app.GetProfile()
+	profile.Appearance.CustomPalettes = []Palette{okabeIto}
+	profile.Appearance.Palette = "Okabe-Ito"
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	palettes := newApp(settingsPath).GetPalettes()
+	if len(palettes) != len(builtinPalettes)+1 {
+		t.Fatalf("GetPalettes returned %d palettes", len(palettes))
+	}
+	if !palettes[0].Builtin || palettes[0].ID != "default" {
+		t.Fatalf("the built-in palettes should come first, got %+v", palettes[0])
+	}
+	if custom := palettes[len(palettes)-1]; custom.Builtin || custom.ID != "Okabe-Ito" || custom.Colors[0] != "#e69f00" {
+		t.Fatalf("custom palette = %+v", custom)
+	}
+}
+
+func TestCustomPalettesLoadAndSave(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "settings", fmt.Sprintf("v%d.json", settingsFileVersion)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := decodeSettingsDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.setIn("appearance", "customPalettes", []Palette{okabeIto}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.setIn("appearance", "palette", "Okabe-Ito"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(settingsPath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Palette{{ID: "Okabe-Ito", Name: "Okabe-Ito", Colors: []string{"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc77aa"}}}
+	if loaded.Appearance.Palette != "Okabe-Ito" || !reflect.DeepEqual(loaded.Appearance.CustomPalettes, want) {
+		t.Fatalf("loaded appearance = %+v", loaded.Appearance)
+	}
+	if err := saveSettings(settingsPath, loaded); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := loadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.Appearance, loaded.Appearance) {
+		t.Fatalf("saved appearance = %+v, want %+v", reloaded.Appearance, loaded.Appearance)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"spacebrowser/internal/platform"
+)
+
+var errLockedByPolicy = errors.New("the setting is managed by the system policy")
+
+// systemPolicy is the administrator-managed policy file. It is read-only for
+// the app and applied on top of user settings. A boolean that is present
+// forces that value. Listed excluded and protected paths are always present;
+// the lock flags also stop users from adding their own entries.
+type systemPolicy struct {
+	AllowDelete          *bool    `json:"allowDelete"`
+	AllowPermanentDelete *bool    `json:"allowPermanentDelete"`
+	SkipNetworkFS        *bool    `json:"skipNetworkFS"`
+	ExcludedPaths        []string `json:"excludedPaths"`
+	LockExcludedPaths    bool     `json:"lockExcludedPaths"`
+	ProtectedPaths       []string `json:"protectedPaths"`
+	LockProtectedPaths   bool     `json:"lockProtectedPaths"`
+	// QuarantineRetentionDays is how long quarantined items are kept before
+	// they are removed. Only the policy sets it, since the quarantine folder
+	// is shared; without it items are kept until they are restored.
+	QuarantineRetentionDays *int `json:"quarantineRetentionDays"`
+
+	path string
+}
+
+// ProfilePolicy reports what the system policy enforces. LockedFields holds
+// the JSON names of Profile fields that cannot be changed; ExcludedPaths and
+// ProtectedPaths are entries that cannot be removed.
+type ProfilePolicy struct {
+	Path                    string   `json:"path"`
+	LockedFields            []string `json:"lockedFields"`
+	ExcludedPaths           []string `json:"excludedPaths"`
+	ProtectedPaths          []string `json:"protectedPaths"`
+	QuarantineRetentionDays int      `json:"quarantineRetentionDays"`
+}
+
+// systemPolicyPath returns where administrators install the policy on system.
+func systemPolicyPath(system string, getenv func(string) string) string {
+	switch system {
+	case "windows":
+		return filepath.Join(windowsFolder(getenv("ProgramData"), `C:\ProgramData`), "SpaceBrowser", "policy.json")
+	case "darwin":
+		return "/Library/Application Support/SpaceBrowser/policy.json"
+	default:
+		return "/etc/spacebrowser/policy.json"
+	}
+}
+
+// loadSystemPolicy reads the policy at path. A missing file is reported as
+// fs.ErrNotExist. Any other failure returns a policy that forbids deletion,
+// so a damaged policy file never loosens a managed installation.
+func loadSystemPolicy(path string, filesystem platform.ScannerFilesystem) (systemPolicy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return systemPolicy{}, err
+	}
+	if err == nil {
+		var policy systemPolicy
+		if err = decodeSystemPolicy(data, &policy, filesystem); err == nil {
+			policy.path = path
+			return policy, nil
+		}
+	}
+	forbidden := false
+	return systemPolicy{AllowDelete: &forbidden, AllowPermanentDelete: &forbidden, path: path}, fmt.Errorf("read system policy %s: %w", path, err)
+}
+
+func decodeSystemPolicy(data []byte, policy *systemPolicy, filesystem platform.ScannerFilesystem) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(policy); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if policy.QuarantineRetentionDays != nil && *policy.QuarantineRetentionDays < 0 {
+		return fmt.Errorf("quarantine retention cannot be negative")
+	}
+	for _, paths := range []*[]string{&policy.ExcludedPaths, &policy.ProtectedPaths} {
+		for index, path := range *paths {
+			if !filepath.IsAbs(path) {
+				return fmt.Errorf("policy path %q must be absolute", path)
+			}
+			(*paths)[index] = filesystem.Canonicalize(path)
+		}
+	}
+	return nil
+}
+
+func (p systemPolicy) active() bool {
+	return p.path != ""
+}
+
+// apply returns profile with the policy enforced.
+func (p systemPolicy) apply(profile Profile) Profile {
+	if p.AllowDelete != nil {
+		profile.AllowDelete = *p.AllowDelete
+	}
+	if p.AllowPermanentDelete != nil {
+		profile.AllowPermanentDelete = *p.AllowPermanentDelete
+	}
+	if p.SkipNetworkFS != nil {
+		profile.SkipNetworkFS = *p.SkipNetworkFS
+	}
+	profile.ExcludedPaths = policyPaths(profile.ExcludedPaths, p.ExcludedPaths, p.LockExcludedPaths)
+	profile.ProtectedPaths = policyPaths(profile.ProtectedPaths, p.ProtectedPaths, p.LockProtectedPaths)
+	return profile
+}
+
+func policyPaths(user, required []string, locked bool) []string {
+	if locked {
+		return append([]string(nil), required...)
+	}
+	paths := append([]string(nil), user...)
+	for _, path := range required {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// check reports the first setting of a normalized profile that the policy
+// does not allow.
+func (p systemPolicy) check(profile Profile) error {
+	forced := p.apply(profile)
+	switch {
+	case forced.AllowDelete != profile.AllowDelete:
+		return fmt.Errorf("%w: Allow delete command", errLockedByPolicy)
+	case forced.AllowPermanentDelete != profile.AllowPermanentDelete:
+		return fmt.Errorf("%w: Allow permanent deletion", errLockedByPolicy)
+	case forced.SkipNetworkFS != profile.SkipNetworkFS:
+		return fmt.Errorf("%w: Skip network file systems", errLockedByPolicy)
+	case !samePaths(forced.ExcludedPaths, profile.ExcludedPaths):
+		return fmt.Errorf("%w: excluded paths", errLockedByPolicy)
+	case !samePaths(forced.ProtectedPaths, profile.ProtectedPaths):
+		return fmt.Errorf("%w: protected paths", errLockedByPolicy)
+	}
+	return nil
+}
+
+func samePaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, path := range a {
+		if !slices.Contains(b, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// quarantineRetentionDays returns how many days quarantined items are kept,
+// or zero to keep them until they are restored.
+func (p systemPolicy) quarantineRetentionDays() int {
+	if p.QuarantineRetentionDays == nil {
+		return 0
+	}
+	return *p.QuarantineRetentionDays
+}
+
+// report describes the policy for GetProfile, or returns nil without one.
+func (p systemPolicy) report() *ProfilePolicy {
+	if !p.active() {
+		return nil
+	}
+	report := &ProfilePolicy{
+		Path:                    p.path,
+		LockedFields:            []string{},
+		ExcludedPaths:           append([]string{}, p.ExcludedPaths...),
+		ProtectedPaths:          append([]string{}, p.ProtectedPaths...),
+		QuarantineRetentionDays: p.quarantineRetentionDays(),
+	}
+	for _, field := range []struct {
+		name   string
+		locked bool
+	}{
+		{"allowDelete", p.AllowDelete != nil},
+		{"allowPermanentDelete", p.AllowPermanentDelete != nil},
+		{"skipNetworkFS", p.SkipNetworkFS != nil},
+		{"excludedPaths", p.LockExcludedPaths},
+		{"protectedPaths", p.LockProtectedPaths},
+	} {
+		if field.locked {
+			report.LockedFields = append(report.LockedFields, field.name)
+		}
+	}
+	return report
+}
+
+// setSystemPolicy enforces policy on the current and all future profiles.
+func (a *App) setSystemPolicy(policy systemPolicy) {
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	a.policy = policy
+	a.profile = policy.apply(a.profile)
+	a.store.SetProtection(newPathProtection(a.profile))
+}
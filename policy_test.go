@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"spacebrowser/internal/platform"
+)
+
+func TestLoadSystemPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if path := systemPolicyPath("linux", os.Getenv); path != "/etc/spacebrowser/policy.json" {
+		t.Fatalf("linux policy path = %q", path)
+	}
+	if _, err := loadSystemPolicy(filepath.Join(dir, "missing.json"), platform.Impl); !os.IsNotExist(err) {
+		t.Fatalf("missing policy error = %v", err)
+	}
+
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(`{"allowDelete": true, "skipNetworkFS": true, "excludedPaths": ["/mnt/shares/"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := loadSystemPolicy(path, platform.Impl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.active() || policy.AllowDelete == nil || !*policy.AllowDelete || policy.AllowPermanentDelete != nil ||
+		!slices.Equal(policy.ExcludedPaths, []string{"/mnt/shares"}) {
+		t.Fatalf("policy = %+v", policy)
+	}
+
+	for _, content := range []string{`{"allowDelet": true}`, `{"protectedPaths": ["data"]}`, `{"quarantineRetentionDays": -1}`, `{`} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		policy, err := loadSystemPolicy(path, platform.Impl)
+		if err == nil {
+			t.Fatalf("policy %s was accepted", content)
+		}
+		if profile := policy.apply(Profile{AllowDelete: true, AllowPermanentDelete: true}); profile.AllowDelete || profile.AllowPermanentDelete {
+			t.Fatalf("the unreadable policy %s allowed deletion", content)
+		}
+	}
+}
+
+func TestSystemPolicyLocksProfileFields(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	profile := app.GetProfile()
+	profile.AllowDelete = true
+	profile.ExcludedPaths = []string{"/home/alex/cache"}
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	forbidden := false
+	app.setSystemPolicy(systemPolicy{
+		AllowDelete:        &forbidden,
+		ExcludedPaths:      []string{"/mnt/shares"},
+		ProtectedPaths:     []string{"/srv/data"},
+		LockProtectedPaths: true,
+		path:               "/etc/spacebrowser/policy.json",
+	})
+	profile = app.GetProfile()
+	if profile.AllowDelete || !slices.Equal(profile.ExcludedPaths, []string{"/home/alex/cache", "/mnt/shares"}) ||
+		!slices.Equal(profile.ProtectedPaths, []string{"/srv/data"}) {
+		t.Fatalf("profile under policy = %+v", profile)
+	}
+	if profile.Policy == nil || !slices.Equal(profile.Policy.LockedFields, []string{"allowDelete", "protectedPaths"}) ||
+		!slices.Equal(profile.Policy.ExcludedPaths, []string{"/mnt/shares"}) {
+		t.Fatalf("reported policy = %+v", profile.Policy)
+	}
+	if !app.store.Protects("/srv/data/reports") {
+		t.Fatal("policy protected paths were not enforced by the store")
+	}
+	if defaults := app.GetDefaultProfile(); defaults.AllowDelete || !slices.Contains(defaults.ExcludedPaths, "/mnt/shares") {
+		t.Fatalf("defaults under policy = %+v", defaults)
+	}
+
+	for name, change := range map[string]func(*Profile){
+		"allow delete":            func(p *Profile) { p.AllowDelete = true },
+		"remove required exclude": func(p *Profile) { p.ExcludedPaths = []string{"/home/alex/cache"} },
+		"add protected path":      func(p *Profile) { p.ProtectedPaths = append(p.ProtectedPaths, "/home/alex") },
+	} {
+		changed := app.GetProfile()
+		change(&changed)
+		if err := app.SetProfile(changed); !errors.Is(err, errLockedByPolicy) {
+			t.Fatalf("%s: SetProfile() error = %v, want locked by policy", name, err)
+		}
+	}
+	profile.ExcludedPaths = append(profile.ExcludedPaths, "/home/alex/tmp")
+	profile.SkipHidden = !profile.SkipHidden
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatalf("SetProfile() with unlocked changes error = %v", err)
+	}
+	saved, err := loadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Policy != nil {
+		t.Fatal("the policy report was saved with user settings")
+	}
+}
+
+func TestQuarantineRetentionComesFromTheSystemPolicy(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	// A retention left in the settings of an older build is ignored.
+	settings := `{"version": 13, "deleteStrategy": "quarantine", "quarantineDir": "` + filepath.ToSlash(t.TempDir()) + `", "quarantineRetentionDays": 1}`
+	if err := os.WriteFile(settingsPath, []byte(settings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	q, err := app.openQuarantine(app.GetProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.retentionDays != 0 {
+		t.Fatalf("retention without a policy = %d days, want items kept until restored", q.retentionDays)
+	}
+
+	days := 90
+	app.setSystemPolicy(systemPolicy{QuarantineRetentionDays: &days, path: "/etc/spacebrowser/policy.json"})
+	if q, err = app.openQuarantine(app.GetProfile()); err != nil || q.retentionDays != 90 {
+		t.Fatalf("retention under the policy = %d days, %v", q.retentionDays, err)
+	}
+	if report := app.GetProfile().Policy; report == nil || report.QuarantineRetentionDays != 90 {
+		t.Fatalf("reported policy = %+v", report)
+	}
+}
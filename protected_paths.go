@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errProtectedPath = errors.New("protected paths and folders containing them cannot be deleted")
+
+// pathProtection decides which paths delete commands must never remove.
+// Subtree entries protect a path and everything inside it. Exact entries only
+// protect the path itself, so their contents may still be cleaned up, but a
+// folder containing any entry is protected because removing it would remove
+// the entry as well.
+type pathProtection struct {
+	subtrees        []string
+	exact           []string
+	caseInsensitive bool
+}
+
+// builtinProtectedPaths returns the system locations protected on system for
+// the user whose home folder is home. getenv resolves the Windows system
+// folders.
+func builtinProtectedPaths(system, home string, getenv func(string) string) (subtrees, exact []string) {
+	switch system {
+	case "windows":
+		drive := getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		drive += `\`
+		subtrees = []string{windowsFolder(getenv("SystemRoot"), drive+"Windows")}
+		exact = []string{
+			drive,
+			drive + "Users",
+			windowsFolder(getenv("ProgramFiles"), drive+"Program Files"),
+			windowsFolder(getenv("ProgramFiles(x86)"), drive+"Program Files (x86)"),
+			windowsFolder(getenv("ProgramData"), drive+"ProgramData"),
+		}
+	case "darwin":
+		subtrees = []string{"/System", "/bin", "/sbin", "/usr", "/private/etc", "/private/var/db", "/Library/Apple"}
+		exact = []string{"/", "/Applications", "/Library", "/Users", "/Volumes", "/cores", "/opt", "/private", "/private/var"}
+	default:
+		subtrees = []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/libx32", "/proc", "/run", "/sbin", "/sys", "/usr", "/var/lib"}
+		exact = []string{"/", "/home", "/media", "/mnt", "/opt", "/root", "/snap", "/srv", "/tmp", "/var"}
+	}
+	if home != "" {
+		exact = append(exact, filepath.Clean(home))
+	}
+	return subtrees, exact
+}
+
+func windowsFolder(configured, fallback string) string {
+	if configured = strings.TrimSpace(configured); configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+// newPathProtection combines the built-in system locations with the
+// protected paths configured in profile.
+func newPathProtection(profile Profile) pathProtection {
+	home, _ := os.UserHomeDir()
+	subtrees, exact := builtinProtectedPaths(profile.PlatformSystem, home, os.Getenv)
+	return pathProtection{
+		subtrees:        append(subtrees, profile.ProtectedPaths...),
+		exact:           exact,
+		caseInsensitive: profile.PlatformSystem == "windows",
+	}
+}
+
+// within reports whether path is parent or inside it.
+func (p pathProtection) within(path, parent string) bool {
+	if pathsEqual(path, parent, p.caseInsensitive) {
+		return true
+	}
+	prefix := strings.TrimRight(parent, `/\`) + string(os.PathSeparator)
+	return pathHasPrefix(path, prefix, p.caseInsensitive)
+}
+
+// protects reports whether deleting path, or emptying it when it is a Trash
+// folder, would remove a protected path.
+func (p pathProtection) protects(path string) bool {
+	if path == "" {
+		return false
+	}
+	path = filepath.Clean(path)
+	for _, entry := range p.subtrees {
+		if p.within(path, entry) || p.within(entry, path) {
+			return true
+		}
+	}
+	for _, entry := range p.exact {
+		if p.within(entry, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuiltinProtectedPaths(t *testing.T) {
+	subtrees, exact := builtinProtectedPaths("linux", "/home/alex", func(string) string { return "" })
+	protection := pathProtection{subtrees: subtrees, exact: exact}
+	for path, want := range map[string]bool{
+		"/":                      true,
+		"/usr":                   true,
+		"/usr/local/share/fonts": true,
+		"/etc/fstab":             true,
+		"/home":                  true,
+		"/home/alex":             true,
+		"/home/alex/Downloads":   false,
+		"/var":                   true,
+		"/var/lib/dpkg":          true,
+		"/var/cache/apt":         false,
+		"/tmp/build":             false,
+		"/usrlocal":              false,
+	} {
+		if got := protection.protects(path); got != want {
+			t.Errorf("linux protects(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	env := map[string]string{"SystemDrive": "D:", "SystemRoot": `D:\WINNT`}
+	subtrees, exact = builtinProtectedPaths("windows", "", func(key string) string { return env[key] })
+	want := map[string]bool{`D:\WINNT`: true, `D:\`: true, `D:\Program Files (x86)`: true, `D:\Users`: true}
+	for _, path := range append(subtrees, exact...) {
+		delete(want, path)
+	}
+	if len(want) != 0 {
+		t.Fatalf("windows built-ins %v %v lack %v", subtrees, exact, want)
+	}
+}
+
+func TestTreeStoreRefusesProtectedPaths(t *testing.T) {
+	base := t.TempDir()
+	root := &Node{ID: 0, ParentID: -1, Name: "base", FullPath: base, Size: 300, IsFolder: true}
+	projects := &Node{ID: 1, ParentID: 0, Name: "projects", FullPath: filepath.Join(base, "projects"), Size: 200, IsFolder: true}
+	keep := &Node{ID: 2, ParentID: 1, Name: "keep", FullPath: filepath.Join(base, "projects", "keep"), Size: 100, IsFolder: true}
+	scratch := &Node{ID: 3, ParentID: 1, Name: "scratch", FullPath: filepath.Join(base, "projects", "scratch"), Size: 100}
+	notes := &Node{ID: 4, ParentID: 2, Name: "notes.txt", FullPath: filepath.Join(base, "projects", "keep", "notes.txt"), Size: 100}
+	other := &Node{ID: 5, ParentID: 0, Name: "other", FullPath: filepath.Join(base, "other"), Size: 100}
+	root.Children = []*Node{projects, other}
+	projects.Children = []*Node{keep, scratch}
+	keep.Children = []*Node{notes}
+	store := &TreeStore{root: root, nodes: []*Node{root, projects, keep, scratch, notes, other}, fileCount: 3, dirCount: 3}
+	store.SetProtection(newPathProtection(Profile{PlatformSystem: "linux", ProtectedPaths: []string{keep.FullPath}}))
+	if err := os.Mkdir(projects.FullPath, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{scratch.FullPath, other.FullPath} {
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved := 0
+	moveToTrash := func(string) error { moved++; return nil }
+	for _, node := range []*Node{keep, notes, projects} {
+		if _, err := store.DeleteNode(node.ID, nil, nil, moveToTrash); !errors.Is(err, errProtectedPath) {
+			t.Fatalf("DeleteNode(%s) error = %v, want protected path error", node.Name, err)
+		}
+	}
+	if _, err := store.DeleteNodes([]int{other.ID, keep.ID}, nil, nil, moveToTrash); !errors.Is(err, errProtectedPath) {
+		t.Fatalf("DeleteNodes() with a protected item error = %v", err)
+	}
+	isTrashRoot := func(path string) bool { return path == projects.FullPath }
+	if _, err := store.EmptyTrashNode(projects.ID, isTrashRoot, func(string) error { moved++; return nil }); !errors.Is(err, errProtectedPath) {
+		t.Fatalf("EmptyTrashNode() over a protected path error = %v", err)
+	}
+	if moved != 0 {
+		t.Fatalf("%d protected items reached the filesystem", moved)
+	}
+	if _, err := store.DeleteNode(scratch.ID, nil, nil, moveToTrash); err != nil {
+		t.Fatalf("DeleteNode() next to a protected path error = %v", err)
+	}
+
+	rects, err := store.Layout(root.ID, 800, 600, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagged := make(map[string]bool)
+	for _, rect := range rects {
+		flagged[rect.Name] = rect.IsProtected
+	}
+	if !flagged["projects"] || !flagged["keep"] || !flagged["notes.txt"] || flagged["other"] {
+		t.Fatalf("protected rects = %v", flagged)
+	}
+}
+
+func TestCleanupPlanSkipsProtectedPaths(t *testing.T) {
+	items := []CleanupBasketItem{{Path: "/etc/ssh"}, {Path: "/srv/cache/old"}}
+	plan := newCleanupPlan(items, Profile{PlatformSystem: "linux", AllowDelete: true}, nil, time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+	if plan.Items[0].Action != cleanupActionSkip || plan.Items[1].Action != cleanupActionTrash {
+		t.Fatalf("plan actions = %q, %q", plan.Items[0].Action, plan.Items[1].Action)
+	}
+}
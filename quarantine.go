@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// Delete strategies. The Trash strategy uses the desktop Trash; quarantine
+// moves items into a staging folder chosen by an administrator instead, and
+// disables emptying Trash and permanent deletion.
+const (
+	deleteStrategyTrash      = "trash"
+	deleteStrategyQuarantine = "quarantine"
+)
+
+const (
+	quarantineManifestVersion = 1
+	quarantineManifestName    = "manifest.json"
+	quarantineLockName        = "manifest.lock"
+	quarantineItemsDir        = "items"
+	// The quarantine folder is shared by every user of a server, so what
+	// SpaceBrowser creates in it stays writable by the folder's group.
+	// Administrators give the folder a shared group and the setgid bit so
+	// new entries inherit that group.
+	quarantineDirMode  = 0o770
+	quarantineFileMode = 0o660
+)
+
+// QuarantineEntry records one item moved into quarantine. The item is stored
+// as items/<ID>/<original name> below the quarantine folder.
+type QuarantineEntry struct {
+	ID            string    `json:"id"`
+	OriginalPath  string    `json:"originalPath"`
+	Owner         string    `json:"owner,omitempty"`
+	Size          int64     `json:"size"`
+	IsFolder      bool      `json:"isFolder"`
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+}
+
+type quarantineManifest struct {
+	Version int               `json:"version"`
+	Items   []QuarantineEntry `json:"items"`
+}
+
+// quarantine moves items into dir and keeps the manifest describing them.
+// retentionDays comes from the system policy, because purging removes every
+// user's items from a shared folder; zero keeps items until they are
+// restored. Changes to the
+// manifest hold a lock on manifest.lock so several users and processes can
+// quarantine into the same folder.
+type quarantine struct {
+	dir           string
+	retentionDays int
+	filesystem    platform.ScannerFilesystem
+	now           func() time.Time
+}
+
+func newQuarantine(profile Profile, policy systemPolicy, filesystem platform.ScannerFilesystem) (quarantine, error) {
+	if profile.DeleteStrategy != deleteStrategyQuarantine {
+		return quarantine{}, fmt.Errorf("the quarantine delete strategy is not enabled")
+	}
+	if profile.QuarantineDir == "" {
+		return quarantine{}, fmt.Errorf("no quarantine folder is configured")
+	}
+	return quarantine{
+		dir:           profile.QuarantineDir,
+		retentionDays: policy.quarantineRetentionDays(),
+		filesystem:    filesystem,
+		now:           time.Now,
+	}, nil
+}
+
+func (q quarantine) manifestPath() string {
+	return filepath.Join(q.dir, quarantineManifestName)
+}
+
+func (q quarantine) storedPath(entry QuarantineEntry) string {
+	return filepath.Join(q.dir, quarantineItemsDir, entry.ID, filepath.Base(entry.OriginalPath))
+}
+
+// expiresAt returns when entry leaves quarantine, or the zero time when items
+// are retained indefinitely.
+func (q quarantine) expiresAt(entry QuarantineEntry) time.Time {
+	if q.retentionDays <= 0 {
+		return time.Time{}
+	}
+	return entry.QuarantinedAt.AddDate(0, 0, q.retentionDays)
+}
+
+func (q quarantine) load() ([]QuarantineEntry, error) {
+	data, err := os.ReadFile(q.manifestPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read quarantine manifest: %w", err)
+	}
+	var manifest quarantineManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("decode quarantine manifest: %w", err)
+	}
+	if manifest.Version < 1 || manifest.Version > quarantineManifestVersion {
+		return nil, fmt.Errorf("unsupported quarantine manifest version %d", manifest.Version)
+	}
+	for _, entry := range manifest.Items {
+		if err := validQuarantineEntry(entry); err != nil {
+			return nil, fmt.Errorf("quarantine manifest: %w", err)
+		}
+	}
+	return manifest.Items, nil
+}
+
+// validQuarantineEntry rejects manifest entries whose ID or original name
+// would lead outside their slot below items/, since both become paths that
+// are renamed and removed.
+func validQuarantineEntry(entry QuarantineEntry) error {
+	if entry.ID == "" || entry.ID == "." || entry.ID == ".." || filepath.Base(entry.ID) != entry.ID {
+		return fmt.Errorf("invalid item ID %q", entry.ID)
+	}
+	name := filepath.Base(entry.OriginalPath)
+	if !filepath.IsAbs(entry.OriginalPath) || name == "." || name == ".." || name == string(filepath.Separator) {
+		return fmt.Errorf("invalid original path %q for item %s", entry.OriginalPath, entry.ID)
+	}
+	return nil
+}
+
+// lockManifest waits until no other process is changing the manifest and
+// returns the function that lets them continue.
+func (q quarantine) lockManifest() (func(), error) {
+	path := filepath.Join(q.dir, quarantineLockName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, quarantineFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("open quarantine lock: %w", err)
+	}
+	shareQuarantinePath(file.Name(), quarantineFileMode)
+	if err := platform.LockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("lock quarantine manifest: %w", err)
+	}
+	return func() {
+		_ = platform.UnlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// shareQuarantinePath gives the group access to a path this process owns,
+// which the umask may have withheld. Paths owned by other users keep the
+// mode their owner gave them.
+func shareQuarantinePath(path string, mode os.FileMode) {
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&mode != mode {
+		_ = os.Chmod(path, info.Mode().Perm()|mode)
+	}
+}
+
+func (q quarantine) save(items []QuarantineEntry) error {
+	if items == nil {
+		items = []QuarantineEntry{}
+	}
+	data, err := json.MarshalIndent(quarantineManifest{Version: quarantineManifestVersion, Items: items}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode quarantine manifest: %w", err)
+	}
+	temp, err := os.CreateTemp(q.dir, ".manifest-*.tmp")
+	if err != nil {
+		return fmt.Errorf("save quarantine manifest: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(append(data, '\n'))
+	if err == nil {
+		err = temp.Chmod(quarantineFileMode)
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), q.manifestPath())
+	}
+	if err != nil {
+		return fmt.Errorf("save quarantine manifest: %w", err)
+	}
+	return nil
+}
+
+// sameVolume reports whether path is on the volume of the quarantine folder,
+// so that quarantining is a rename rather than a copy.
+func (q quarantine) sameVolume(path string, info os.FileInfo) (bool, error) {
+	dirInfo, err := os.Stat(q.dir)
+	if err != nil {
+		return false, fmt.Errorf("the quarantine folder is unavailable: %w", err)
+	}
+	if !dirInfo.IsDir() {
+		return false, fmt.Errorf("the quarantine folder %s is not a folder", q.dir)
+	}
+	itemUsage := q.filesystem.UsageFor(path, info)
+	dirUsage := q.filesystem.UsageFor(q.dir, dirInfo)
+	if !itemUsage.HasIdentity || !dirUsage.HasIdentity {
+		// Without volume identities the rename below reports cross-device moves.
+		return true, nil
+	}
+	return itemUsage.Identity.Volume == dirUsage.Identity.Volume, nil
+}
+
+// move places path in quarantine and records it in the manifest.
+func (q quarantine) move(path string) (QuarantineEntry, error) {
+	path = filepath.Clean(path)
+	if pathWithin(path, q.dir) || pathWithin(q.dir, path) {
+		return QuarantineEntry{}, fmt.Errorf("the quarantine folder and its contents cannot be quarantined")
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return QuarantineEntry{}, fmt.Errorf("inspect item to quarantine: %w", err)
+	}
+	same, err := q.sameVolume(path, info)
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	if !same {
+		return QuarantineEntry{}, fmt.Errorf("%s is on another volume than the quarantine folder", path)
+	}
+	unlock, err := q.lockManifest()
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	defer unlock()
+	items, err := q.load()
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+
+	now := q.now()
+	entry := QuarantineEntry{
+		OriginalPath:  path,
+		Owner:         platform.FileOwner(q.filesystem, path),
+		Size:          allocatedTreeSize(q.filesystem, path),
+		IsFolder:      info.IsDir(),
+		QuarantinedAt: now.UTC().Truncate(time.Second),
+	}
+	itemsDir := filepath.Join(q.dir, quarantineItemsDir)
+	if err := os.MkdirAll(itemsDir, quarantineDirMode); err != nil {
+		return QuarantineEntry{}, fmt.Errorf("create quarantine storage: %w", err)
+	}
+	shareQuarantinePath(itemsDir, quarantineDirMode)
+	for attempt := 0; ; attempt++ {
+		entry.ID = strconv.FormatInt(now.UnixNano()+int64(attempt), 36)
+		err = os.Mkdir(filepath.Join(itemsDir, entry.ID), quarantineDirMode)
+		if err == nil {
+			shareQuarantinePath(filepath.Join(itemsDir, entry.ID), quarantineDirMode)
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return QuarantineEntry{}, fmt.Errorf("create quarantine slot: %w", err)
+		}
+	}
+	slot := filepath.Join(itemsDir, entry.ID)
+	if err := os.Rename(path, q.storedPath(entry)); err != nil {
+		_ = os.Remove(slot)
+		return QuarantineEntry{}, fmt.Errorf("move to quarantine: %w", err)
+	}
+	if err := q.save(append(items, entry)); err != nil {
+		if restoreErr := os.Rename(q.storedPath(entry), path); restoreErr == nil {
+			_ = os.Remove(slot)
+		}
+		return QuarantineEntry{}, err
+	}
+	return entry, nil
+}
+
+// restore moves the quarantined item id back to its original location.
+func (q quarantine) restore(id string) (QuarantineEntry, error) {
+	unlock, err := q.lockManifest()
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	defer unlock()
+	items, err := q.load()
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	index := -1
+	for candidate, entry := range items {
+		if entry.ID == id {
+			index = candidate
+			break
+		}
+	}
+	if index < 0 {
+		return QuarantineEntry{}, fmt.Errorf("the quarantined item is no longer available")
+	}
+	entry := items[index]
+	if _, err := os.Lstat(entry.OriginalPath); err == nil {
+		return QuarantineEntry{}, fmt.Errorf("the original location already exists: %s", entry.OriginalPath)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return QuarantineEntry{}, fmt.Errorf("inspect original location: %w", err)
+	}
+	parent := filepath.Dir(entry.OriginalPath)
+	if parentInfo, err := os.Stat(parent); err != nil || !parentInfo.IsDir() {
+		return QuarantineEntry{}, fmt.Errorf("the original parent folder is unavailable: %s", parent)
+	}
+	if err := os.Rename(q.storedPath(entry), entry.OriginalPath); err != nil {
+		return QuarantineEntry{}, fmt.Errorf("restore quarantined item: %w", err)
+	}
+	_ = os.Remove(filepath.Join(q.dir, quarantineItemsDir, entry.ID))
+	remaining := append(append([]QuarantineEntry(nil), items[:index]...), items[index+1:]...)
+	if err := q.save(remaining); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// purgeExpired deletes items whose retention period has elapsed and returns
+// them. Items that cannot be removed stay in the manifest.
+func (q quarantine) purgeExpired() ([]QuarantineEntry, error) {
+	if q.retentionDays <= 0 {
+		return nil, nil
+	}
+	if _, err := os.Stat(q.manifestPath()); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	unlock, err := q.lockManifest()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	items, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+	now := q.now()
+	var kept, purged []QuarantineEntry
+	var firstErr error
+	for _, entry := range items {
+		if now.Before(q.expiresAt(entry)) {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(q.dir, quarantineItemsDir, entry.ID)); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("remove expired quarantined item %s: %w", entry.OriginalPath, err)
+			}
+			kept = append(kept, entry)
+			continue
+		}
+		purged = append(purged, entry)
+	}
+	if len(purged) == 0 {
+		return nil, firstErr
+	}
+	if err := q.save(kept); err != nil {
+		return purged, err
+	}
+	return purged, firstErr
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func testQuarantine(t *testing.T, retentionDays int) (quarantine, *time.Time) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "quarantine")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	q := quarantine{dir: dir, retentionDays: retentionDays, filesystem: platform.Impl, now: func() time.Time { return now }}
+	return q, &now
+}
+
+func TestQuarantineMoveRecordsManifestAndRestores(t *testing.T) {
+	q, _ := testQuarantine(t, 30)
+	source := filepath.Join(filepath.Dir(q.dir), "project")
+	if err := os.MkdirAll(filepath.Join(source, "build"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(source, "build", "out.bin"), make([]byte, 16384), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := q.move(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.OriginalPath != source || !entry.IsFolder || entry.Size < 16384 || entry.ID == "" || entry.Owner == "" {
+		t.Fatalf("quarantine entry = %+v", entry)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Fatal("the quarantined folder is still at its original location")
+	}
+	if _, err := os.Stat(filepath.Join(q.storedPath(entry), "build", "out.bin")); err != nil {
+		t.Fatalf("the quarantined folder was not stored: %v", err)
+	}
+	items, err := q.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != entry {
+		t.Fatalf("manifest items = %+v, want [%+v]", items, entry)
+	}
+	if want := entry.QuarantinedAt.AddDate(0, 0, 30); !q.expiresAt(entry).Equal(want) {
+		t.Fatalf("expiresAt = %v, want %v", q.expiresAt(entry), want)
+	}
+
+	if err := os.Mkdir(source, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.restore(entry.ID); err == nil {
+		t.Fatal("restore replaced an existing item at the original location")
+	}
+	if err := os.Remove(source); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.restore(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(source, "build", "out.bin")); err != nil {
+		t.Fatalf("the folder was not restored: %v", err)
+	}
+	if items, err := q.load(); err != nil || len(items) != 0 {
+		t.Fatalf("manifest after restore = %+v, %v", items, err)
+	}
+	if _, err := q.restore(entry.ID); err == nil {
+		t.Fatal("an item was restored twice")
+	}
+}
+
+func TestQuarantineRefusesItsOwnFolder(t *testing.T) {
+	q, _ := testQuarantine(t, 30)
+	if err := os.WriteFile(filepath.Join(q.dir, "note.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{q.dir, filepath.Join(q.dir, "note.txt"), filepath.Dir(q.dir)} {
+		if _, err := q.move(path); err == nil {
+			t.Fatalf("%s was quarantined", path)
+		}
+	}
+}
+
+func TestQuarantinePurgesExpiredItems(t *testing.T) {
+	q, now := testQuarantine(t, 30)
+	var entries []QuarantineEntry
+	for _, name := range []string{"old.iso", "new.iso"} {
+		path := filepath.Join(filepath.Dir(q.dir), name)
+		if err := os.WriteFile(path, make([]byte, 4096), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		entry, err := q.move(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+		*now = now.AddDate(0, 0, 20)
+	}
+
+	// 40 days after the first move and 20 after the second.
+	purged, err := q.purgeExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 1 || purged[0].ID != entries[0].ID {
+		t.Fatalf("purged = %+v, want only old.iso", purged)
+	}
+	if _, err := os.Stat(filepath.Join(q.dir, quarantineItemsDir, entries[0].ID)); !os.IsNotExist(err) {
+		t.Fatal("the expired item is still stored")
+	}
+	items, err := q.load()
+	if err != nil || len(items) != 1 || items[0].ID != entries[1].ID {
+		t.Fatalf("manifest after purge = %+v, %v", items, err)
+	}
+
+	q.retentionDays = 0
+	*now = now.AddDate(1, 0, 0)
+	if purged, err := q.purgeExpired(); err != nil || len(purged) != 0 {
+		t.Fatalf("purge without retention = %+v, %v", purged, err)
+	}
+}
+
+func TestQuarantineDeleteStrategyPlugsIntoDeleteNode(t *testing.T) {
+	base := t.TempDir()
+	quarantineDir := filepath.Join(base, "quarantine")
+	for _, dir := range []string{quarantineDir, filepath.Join(base, "project")} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "project", "data.bin"), make([]byte, 1<<20), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, trashActionDesktop{path: filepath.Join(t.TempDir(), "Trash")}, nil)
+	retentionDays := 30
+	app.setSystemPolicy(systemPolicy{QuarantineRetentionDays: &retentionDays, path: "/etc/spacebrowser/policy.json"})
+	profile := app.GetProfile()
+	profile.SkipNetworkFS = false
+	profile.MinFileSize = 0
+	profile.AllowDelete = true
+	profile.AllowPermanentDelete = true
+	profile.RescanOnDelete = false
+	profile.DeleteStrategy = deleteStrategyQuarantine
+	if err := app.SetProfile(profile); err == nil {
+		t.Fatal("the quarantine strategy was accepted without a folder")
+	}
+	profile.QuarantineDir = quarantineDir
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.GetFullTree(base); err != nil {
+		t.Fatal(err)
+	}
+
+	quarantineNode := app.store.nodeByPath(quarantineDir)
+	if _, err := app.DeleteNode(quarantineNode.ID); err == nil {
+		t.Fatal("the quarantine folder itself was deleted")
+	}
+	project := filepath.Join(base, "project")
+	result, err := app.DeleteNode(app.store.nodeByPath(project).ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.store.nodeByPath(project) != nil || result.UndoCount != 0 {
+		t.Fatalf("after quarantine: node kept = %v, undo count = %d", app.store.nodeByPath(project) != nil, result.UndoCount)
+	}
+
+	items, err := app.GetQuarantine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != project || items[0].ExpiresAt == 0 {
+		t.Fatalf("quarantine listing = %+v", items)
+	}
+	if app.store.nodeByPath(filepath.Join(items[0].StoredPath, "data.bin")) == nil {
+		t.Fatal("the displayed quarantine folder was not refreshed")
+	}
+	stored := app.store.nodeByPath(items[0].StoredPath)
+	if _, err := app.DeleteNode(stored.ID); err == nil {
+		t.Fatal("a quarantined item was deleted from the treemap")
+	}
+
+	if _, err := app.RestoreQuarantinedItems([]string{items[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if app.store.nodeByPath(filepath.Join(project, "data.bin")) == nil {
+		t.Fatal("the restored folder was not inserted into the tree")
+	}
+	if app.store.nodeByPath(items[0].StoredPath) != nil {
+		t.Fatal("the restored item is still displayed in quarantine")
+	}
+}
+
+func TestQuarantineRejectsManifestEntriesOutsideItems(t *testing.T) {
+	q, _ := testQuarantine(t, 30)
+	outside := filepath.Join(filepath.Dir(q.dir), "keep.txt")
+	if err := os.WriteFile(outside, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", ".", "..", "../..", "../../..", "a/b"} {
+		manifest := `{"version":1,"items":[{"id":` + strconv.Quote(id) + `,"originalPath":"/data/old.iso","quarantinedAt":"2020-01-01T00:00:00Z"}]}`
+		if err := os.WriteFile(q.manifestPath(), []byte(manifest), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.load(); err == nil {
+			t.Errorf("a manifest with ID %q was accepted", id)
+		}
+		if _, err := q.purgeExpired(); err == nil {
+			t.Errorf("purging a manifest with ID %q succeeded", id)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("purge removed a file outside the quarantine: %v", err)
+	}
+	manifest := `{"version":1,"items":[{"id":"abc","originalPath":"/","quarantinedAt":"2020-01-01T00:00:00Z"}]}`
+	if err := os.WriteFile(q.manifestPath(), []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.load(); err == nil {
+		t.Error("a manifest entry without an original name was accepted")
+	}
+}
+
+func TestQuarantineManifestIsSharedBetweenProcesses(t *testing.T) {
+	q, _ := testQuarantine(t, 30)
+	// Each goroutine stands for another process: nothing but the manifest
+	// lock keeps their updates apart.
+	const count = 8
+	errs := make(chan error, count)
+	for index := range count {
+		path := filepath.Join(filepath.Dir(q.dir), "file"+strconv.Itoa(index))
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			_, err := q.move(path)
+			errs <- err
+		}()
+	}
+	for range count {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err := q.load()
+	if err != nil || len(items) != count {
+		t.Fatalf("manifest lists %d of %d items: %v", len(items), count, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	for path, want := range map[string]os.FileMode{
+		q.manifestPath():                                      quarantineFileMode,
+		filepath.Join(q.dir, quarantineItemsDir):              quarantineDirMode,
+		filepath.Join(q.dir, quarantineItemsDir, items[0].ID): quarantineDirMode,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&want != want {
+			t.Errorf("%s has mode %v, want the group to share %v", path, info.Mode().Perm(), want)
+		}
+	}
+}
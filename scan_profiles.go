@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"spacebrowser/internal/platform"
+)
+
+// defaultScanProfileName names the scan settings stored directly in Profile,
+// used for roots that no named scan profile is bound to.
+const defaultScanProfileName = "Default"
+
+// ScanProfile is a named set of scan settings that replaces the default
+// ones when scanning inside one of Roots, which may be folders or mount
+// points.
+type ScanProfile struct {
+	Name                 string   `json:"name"`
+	Roots                []string `json:"roots"`
+	ExcludedPaths        []string `json:"excludedPaths"`
+	SkipHidden           bool     `json:"skipHidden"`
+	MinFileSize          int64    `json:"minFileSize"`
+	FollowSymlinks       bool     `json:"followSymlinks"`
+	KeepSmallFileDetails bool     `json:"keepSmallFileDetails"`
+	SkipNetworkFS        bool     `json:"skipNetworkFS"`
+}
+
+func normalizeScanProfiles(profiles []ScanProfile, filesystem platform.ScannerFilesystem) ([]ScanProfile, error) {
+	normalized := make([]ScanProfile, 0, len(profiles))
+	names := map[string]struct{}{strings.ToLower(defaultScanProfileName): {}}
+	roots := make(map[string]string)
+	for _, profile := range profiles {
+		profile.Name = strings.TrimSpace(profile.Name)
+		if profile.Name == "" {
+			return nil, fmt.Errorf("scan profiles need a name")
+		}
+		if _, exists := names[strings.ToLower(profile.Name)]; exists {
+			return nil, fmt.Errorf("scan profile name %q is already used", profile.Name)
+		}
+		names[strings.ToLower(profile.Name)] = struct{}{}
+		if profile.MinFileSize < 0 {
+			return nil, fmt.Errorf("scan profile %q: minimum file size cannot be negative", profile.Name)
+		}
+
+		for _, root := range profile.Roots {
+			if root = strings.TrimSpace(root); root != "" && !filepath.IsAbs(root) {
+				return nil, fmt.Errorf("scan profile %q: root %q must be absolute", profile.Name, root)
+			}
+		}
+		profile.Roots = cleanProfilePaths(profile.Roots, filesystem)
+		for _, root := range profile.Roots {
+			if other, bound := roots[root]; bound {
+				return nil, fmt.Errorf("root %s is bound to both scan profiles %q and %q", root, other, profile.Name)
+			}
+			roots[root] = profile.Name
+		}
+		profile.ExcludedPaths = cleanProfilePaths(profile.ExcludedPaths, filesystem)
+		normalized = append(normalized, profile)
+	}
+	return normalized, nil
+}
+
+// cleanProfilePaths trims, canonicalizes and deduplicates paths, dropping
+// blank entries.
+func cleanProfilePaths(paths []string, filesystem platform.ScannerFilesystem) []string {
+	cleaned := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path = filesystem.Canonicalize(path)
+		if _, exists := seen[path]; exists {
+			continue
+		}
+		seen[path] = struct{}{}
+		cleaned = append(cleaned, path)
+	}
+	return cleaned
+}
+
+// scanProfileFor returns the scan profile bound to the root closest to path,
+// or false when path is outside every bound root.
+func scanProfileFor(profiles []ScanProfile, path string, caseInsensitive bool) (ScanProfile, bool) {
+	protection := pathProtection{caseInsensitive: caseInsensitive}
+	var match ScanProfile
+	matchLength := -1
+	for _, profile := range profiles {
+		for _, root := range profile.Roots {
+			if len(root) > matchLength && protection.within(path, root) {
+				match, matchLength = profile, len(root)
+			}
+		}
+	}
+	return match, matchLength >= 0
+}
+
+// withScanProfile returns profile with its scan settings replaced by scan.
+func (profile Profile) withScanProfile(scan ScanProfile) Profile {
+	profile.ExcludedPaths = append([]string(nil), scan.ExcludedPaths...)
+	profile.SkipHidden = scan.SkipHidden
+	profile.MinFileSize = scan.MinFileSize
+	profile.FollowSymlinks = scan.FollowSymlinks
+	profile.KeepSmallFileDetails = scan.KeepSmallFileDetails
+	profile.SkipNetworkFS = scan.SkipNetworkFS
+	return profile
+}
+
+// scanSettingsFor returns the settings to scan path with and the name of the
+// scan profile they come from. Session overrides and then the system policy
+// apply to every profile.
+func (a *App) scanSettingsFor(path string) (Profile, string) {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	profile := a.profile
+	profile.ExcludedPaths = append([]string(nil), profile.ExcludedPaths...)
+	scan, ok := scanProfileFor(profile.ScanProfiles, path, profile.PlatformSystem == "windows")
+	if forced := a.overrides.ScanProfile; forced != "" {
+		scan, ok = scanProfileNamed(profile.ScanProfiles, forced)
+	}
+	name := defaultScanProfileName
+	if ok {
+		profile, name = profile.withScanProfile(scan), scan.Name
+	}
+	return a.policy.apply(a.overrides.apply(profile)), name
+}
+
+// treeScanSettings returns the settings the displayed tree was scanned with,
+// for scans that refresh part of it.
+func (a *App) treeScanSettings() Profile {
+	profile, _ := a.scanSettingsFor(a.store.RootPath())
+	return profile
+}
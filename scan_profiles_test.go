@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func TestNormalizeScanProfilesRejectsConflicts(t *testing.T) {
+	for name, profiles := range map[string][]ScanProfile{
+		"unnamed":        {{Roots: []string{"/srv"}}},
+		"default name":   {{Name: "default"}},
+		"duplicate name": {{Name: "NAS"}, {Name: "nas"}},
+		"relative root":  {{Name: "NAS", Roots: []string{"shares"}}},
+		"shared root":    {{Name: "NAS", Roots: []string{"/mnt/nas"}}, {Name: "Backups", Roots: []string{"/mnt/nas/"}}},
+		"negative size":  {{Name: "NAS", MinFileSize: -1}},
+	} {
+		if _, err := normalizeScanProfiles(profiles, platform.Impl); err == nil {
+			t.Errorf("%s: scan profiles %+v were accepted", name, profiles)
+		}
+	}
+}
+
+func TestScanProfileForPrefersClosestRoot(t *testing.T) {
+	profiles := []ScanProfile{
+		{Name: "NAS", Roots: []string{"/mnt/nas"}},
+		{Name: "Builds", Roots: []string{"/srv", "/mnt/nas/builds"}},
+	}
+	for path, want := range map[string]string{
+		"/mnt/nas":              "NAS",
+		"/mnt/nas/photos":       "NAS",
+		"/mnt/nas/builds/2026":  "Builds",
+		"/srv":                  "Builds",
+		"/mnt/nasbackup":        "",
+		"/home/alex/Downloads":  "",
+		"/mnt/nas/buildsOld/21": "NAS",
+	} {
+		profile, ok := scanProfileFor(profiles, path, false)
+		if got := profile.Name; got != want || ok != (want != "") {
+			t.Errorf("scanProfileFor(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestGetFullTreeSelectsBoundScanProfile(t *testing.T) {
+	base := t.TempDir()
+	build := filepath.Join(base, "build")
+	for _, dir := range []string{filepath.Join(build, "cache"), filepath.Join(base, "home", "cache")} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blob.bin"), make([]byte, 4096), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	profile := app.GetProfile()
+	profile.SkipNetworkFS = false
+	profile.MinFileSize = 0
+	profile.ScanProfiles = []ScanProfile{{Name: "Build server", Roots: []string{build}, ExcludedPaths: []string{filepath.Join(build, "cache")}}}
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := app.GetFullTree(build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ScanProfile != "Build server" || app.store.nodeByPath(filepath.Join(build, "cache")) != nil {
+		t.Fatalf("scan of the bound root used profile %q, cache kept = %v", info.ScanProfile, app.store.nodeByPath(filepath.Join(build, "cache")) != nil)
+	}
+	if info, err = app.GetFullTree(filepath.Join(base, "home")); err != nil {
+		t.Fatal(err)
+	}
+	if info.ScanProfile != defaultScanProfileName || app.store.nodeByPath(filepath.Join(base, "home", "cache")) == nil {
+		t.Fatalf("scan outside bound roots used profile %q", info.ScanProfile)
+	}
+
+	report := formatScanReport(scanReportDetails{RootPath: build, Profile: profile, ScanProfile: "Build server"}, time.Now())
+	if !strings.Contains(report, "Scan profile: Build server\n") {
+		t.Fatalf("scan report does not name the scan profile:\n%s", report)
+	}
+}
@@ -42,6 +42,7 @@ const (
 	scanErrorPortableDirectoryFallback
 	scanErrorResolveSymlink
 	scanErrorSubdirectory
+	scanErrorMountTable
 	scanErrorReasonCount
 )
 
@@ -54,6 +55,7 @@ var scanErrorLabels = [scanErrorReasonCount]string{
 	"portable directory enumeration fallbacks",
 	"symlink resolution",
 	"subdirectory scans",
+	"mount table",
 }
 
 type ScanReportExample struct {
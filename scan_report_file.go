@@ -26,13 +26,18 @@ type scanReportDetails struct {
 	StartedAt time.Time
 	Duration  time.Duration
 	Profile   Profile
+	// ScanProfile names the scan profile Profile was selected from.
+	ScanProfile string
+	// Overrides lists the settings the command line or environment
+	// replaced for this run.
+	Overrides []string
 	Report    ScanReportSnapshot
 	Files     int64
 	Folders   int64
 	Bytes     int64
 }
 
-func (a *App) persistScanReport(rootPath string, startedAt time.Time, duration time.Duration, profile Profile, report ScanReportSnapshot, files, folders, bytes int64) *ScanReportInfo {
+func (a *App) persistScanReport(rootPath string, startedAt time.Time, duration time.Duration, profile Profile, scanProfile string, report ScanReportSnapshot, files, folders, bytes int64) *ScanReportInfo {
 	if report.TotalErrors() == 0 {
 		return nil
 	}
@@ -42,14 +47,16 @@ func (a *App) persistScanReport(rootPath string, startedAt time.Time, duration t
 		Details:    formatNonzeroScanCounts(report.Errors[:], scanErrorLabels[:]),
 	}
 	path, err := writeScanReport(a.GetDefaultSettingsPath(), scanReportDetails{
-		RootPath:  rootPath,
-		StartedAt: startedAt,
-		Duration:  duration,
-		Profile:   profile,
-		Report:    report,
-		Files:     files,
-		Folders:   folders,
-		Bytes:     bytes,
+		RootPath:    rootPath,
+		StartedAt:   startedAt,
+		Duration:    duration,
+		Profile:     profile,
+		ScanProfile: scanProfile,
+		Overrides:   a.sessionOverrideSources(),
+		Report:      report,
+		Files:       files,
+		Folders:     folders,
+		Bytes:       bytes,
 	})
 	info.ReportPath = path
 	if err != nil {
@@ -136,6 +143,9 @@ func formatScanReport(details scanReportDetails, completedAt time.Time) string {
 	fmt.Fprintf(&output, "Files: %d\nFolders: %d\nDisk usage: %d bytes\n", details.Files, details.Folders, details.Bytes)
 	fmt.Fprintln(&output)
 	fmt.Fprintln(&output, "Scan settings")
+	if details.ScanProfile != "" {
+		fmt.Fprintf(&output, "Scan profile: %s\n", details.ScanProfile)
+	}
 	fmt.Fprintf(&output, "Skip hidden: %t\n", details.Profile.SkipHidden)
 	fmt.Fprintf(&output, "Minimum file size: %d bytes\n", details.Profile.MinFileSize)
 	fmt.Fprintf(&output, "Follow symlinks: %t\n", details.Profile.FollowSymlinks)
@@ -148,6 +158,12 @@ func formatScanReport(details scanReportDetails, completedAt time.Time) string {
 			fmt.Fprintf(&output, "  - %s\n", path)
 		}
 	}
+	if len(details.Overrides) > 0 {
+		fmt.Fprintln(&output, "Overridden for this session:")
+		for _, override := range details.Overrides {
+			fmt.Fprintf(&output, "  - %s\n", override)
+		}
+	}
 	fmt.Fprintln(&output)
 	fmt.Fprintln(&output, "Summary")
 	fmt.Fprintf(&output, "Skipped paths: %d\n", details.Report.TotalSkipped())
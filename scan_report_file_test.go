@@ -100,7 +100,7 @@ func TestWriteScanReportRetainsLatestTwenty(t *testing.T) {
 func TestPersistScanReportSkipsCleanScans(t *testing.T) {
 	defaultPath := filepath.Join(t.TempDir(), "settings.json")
 	app := newAppWithPathsAndLogger(defaultPath, defaultPath, NewSeverityLogger(verbosityInfo, io.Discard))
-	if info := app.persistScanReport("root", time.Now(), time.Second, *defaultProfile(), ScanReportSnapshot{}, 1, 1, 1); info != nil {
+	if info := app.persistScanReport("root", time.Now(), time.Second, *defaultProfile(), defaultScanProfileName, ScanReportSnapshot{}, 1, 1, 1); info != nil {
 		t.Fatalf("clean scan returned report info: %+v", info)
 	}
 	if _, err := os.Stat(filepath.Join(filepath.Dir(defaultPath), "logs")); !os.IsNotExist(err) {
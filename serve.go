@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultServeAddress  = "127.0.0.1:8421"
+	serveTokenCookie     = "spacebrowser-token"
+	minimumServeToken    = 16
+	maximumServeRequest  = 8 << 20
+	serveEventBuffer     = 32
+	serveShutdownTimeout = 5 * time.Second
+)
+
+//go:embed serve_runtime.js
+var serveRuntimeScript []byte
+
+type serveAccess int
+
+const (
+	serveUnavailable serveAccess = iota
+	serveRead
+	serveWrite
+)
+
+// serveMethods lists the App methods that browsers may call. serveWrite
+// methods change files or saved settings and need --serve-allow-delete.
+// Methods missing here open native dialogs or act on the desktop of the
+// serving machine, so they fail in the browser.
+var serveMethods = map[string]serveAccess{
+	"CancelScan":               serveRead,
+	"DefaultPath":              serveRead,
+	"ExcludeMount":             serveRead,
+	"GetAssociatedIcon":        serveRead,
+	"GetAuditLog":              serveRead,
+	"GetBuiltinProtectedPaths": serveRead,
+	"GetCleanupBasket":         serveRead,
+	"GetDefaultProfile":        serveRead,
+	"GetDefaultSettingsPath":   serveRead,
+	"GetFullTree":              serveRead,
+	"GetInitialScanPath":       serveRead,
+	"GetLargestItems":          serveRead,
+	"GetPalettes":              serveRead,
+	"GetProfile":               serveRead,
+	"GetQuarantine":            serveRead,
+	"GetScanLocations":         serveRead,
+	"GetScanProgress":          serveRead,
+	"GetSelectionInfo":         serveRead,
+	"GetSettingsPath":          serveRead,
+	"GetSmallFiles":            serveRead,
+	"GetTrashRestoreInfo":      serveRead,
+	"GetTrashUndo":             serveRead,
+	"Layout":                   serveRead,
+	"ListTrashItems":           serveRead,
+	"SetMountCollapsed":        serveRead,
+	"SetShowFreeSpace":         serveRead,
+	"ValidateScanPath":         serveRead,
+
+	"AddToCleanupBasket":      serveWrite,
+	"ClearCleanupBasket":      serveWrite,
+	"DeleteNode":              serveWrite,
+	"DeleteNodes":             serveWrite,
+	"DeleteSmallFile":         serveWrite,
+	"DeleteTrashItems":        serveWrite,
+	"ExecuteCleanupBasket":    serveWrite,
+	"PurgeTrashOlderThan":     serveWrite,
+	"RemoveFromCleanupBasket": serveWrite,
+	"RestoreNode":             serveWrite,
+	"RestoreQuarantinedItems": serveWrite,
+	"RestoreTrashItems":       serveWrite,
+	"SetProfile":              serveWrite,
+	"SetSettingsPath":         serveWrite,
+	"UndoMoveToTrash":         serveWrite,
+}
+
+// serveOptions configures --serve. Without allowDelete the server is
+// read-only: serveWrite methods are refused and profiles report deletion
+// as disabled so the page hides its delete commands.
+type serveOptions struct {
+	address     string
+	token       string
+	allowDelete bool
+}
+
+// webServer hosts the web assets and the App bindings for browsers.
+type webServer struct {
+	app         *App
+	assets      fs.FS
+	token       string
+	allowDelete bool
+	upgrader    websocket.Upgrader
+
+	clientsMu sync.Mutex
+	clients   map[chan []byte]struct{}
+}
+
+func newServeToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate access token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func newWebServer(app *App, assets fs.FS, options serveOptions) (*webServer, error) {
+	if len(options.token) < minimumServeToken {
+		return nil, fmt.Errorf("the access token needs at least %d characters", minimumServeToken)
+	}
+	return &webServer{
+		app:         app,
+		assets:      assets,
+		token:       options.token,
+		allowDelete: options.allowDelete,
+		clients:     make(map[chan []byte]struct{}),
+	}, nil
+}
+
+func (s *webServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /wailsjs/go/main/App.js", s.serveBindings)
+	mux.HandleFunc("GET /wailsjs/runtime/runtime.js", s.serveRuntime)
+	mux.HandleFunc("POST /api/call/{method}", s.serveCall)
+	mux.HandleFunc("POST /api/log", s.serveLog)
+	mux.HandleFunc("GET /api/events", s.serveEvents)
+	mux.Handle("GET /", http.FileServerFS(s.assets))
+	return s.authenticate(mux)
+}
+
+// authenticate admits requests that carry the access token as a cookie or
+// bearer token. Opening the server with ?token= stores the cookie and loads
+// the page again without the token in its address.
+func (s *webServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		query := r.URL.Query()
+		if query.Has("token") && r.Method == http.MethodGet {
+			if !s.validToken(query.Get("token")) {
+				http.Error(w, "The access token is not valid.", http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     serveTokenCookie,
+				Value:    s.token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			query.Del("token")
+			location := url.URL{Path: "/", RawQuery: query.Encode()}
+			http.Redirect(w, r, location.String(), http.StatusSeeOther)
+			return
+		}
+		if !s.authorized(r) {
+			http.Error(w, "Open the address that spacebrowser --serve printed, including its token.", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *webServer) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *webServer) authorized(r *http.Request) bool {
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return s.validToken(token)
+	}
+	cookie, err := r.Cookie(serveTokenCookie)
+	return err == nil && s.validToken(cookie.Value)
+}
+
+// bindingsScript replaces the generated Wails bindings with functions that
+// call the server. Every exported App method is present so the page's
+// imports resolve; unavailable ones reject when called.
+func bindingsScript(app *App) []byte {
+	var script strings.Builder
+	script.WriteString("// Generated by spacebrowser --serve in place of the Wails bindings.\n")
+	script.WriteString("import { callBackend } from \"../../runtime/runtime.js\";\n")
+	appType := reflect.TypeOf(app)
+	names := make([]string, 0, appType.NumMethod())
+	for index := range appType.NumMethod() {
+		if name := appType.Method(index).Name; name != "Startup" && name != "Shutdown" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if serveMethods[name] == serveUnavailable {
+			fmt.Fprintf(&script, "\nexport function %s() {\n  return Promise.reject(%q);\n}\n", name, name+" is not available in the browser.")
+			continue
+		}
+		fmt.Fprintf(&script, "\nexport function %s(...args) {\n  return callBackend(%q, args);\n}\n", name, name)
+	}
+	return []byte(script.String())
+}
+
+func (s *webServer) serveBindings(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write(bindingsScript(s.app))
+}
+
+func (s *webServer) serveRuntime(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write(serveRuntimeScript)
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	writeServeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// serveCall runs the App method named in the path with the JSON array of
+// arguments in the body and answers with its JSON result. Errors the method
+// returns are sent as {"error": text}.
+func (s *webServer) serveCall(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("method")
+	switch access := serveMethods[name]; {
+	case access == serveUnavailable:
+		writeServeError(w, http.StatusNotFound, fmt.Errorf("%s is not available in the browser", name))
+		return
+	case access == serveWrite && !s.allowDelete:
+		writeServeError(w, http.StatusForbidden, fmt.Errorf("SpaceBrowser is serving read-only; restart it with --serve-allow-delete to allow %s", name))
+		return
+	}
+	// A JSON content type keeps other sites from posting without a CORS
+	// preflight, which this server never grants.
+	if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) != "application/json" {
+		writeServeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("calls must be sent as application/json"))
+		return
+	}
+
+	method := reflect.ValueOf(s.app).MethodByName(name)
+	var raw []json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maximumServeRequest)).Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("decode arguments of %s: %w", name, err))
+		return
+	}
+	if len(raw) != method.Type().NumIn() {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("%s takes %d arguments, not %d", name, method.Type().NumIn(), len(raw)))
+		return
+	}
+	args := make([]reflect.Value, len(raw))
+	for index, value := range raw {
+		argument := reflect.New(method.Type().In(index))
+		if err := json.Unmarshal(value, argument.Interface()); err != nil {
+			writeServeError(w, http.StatusBadRequest, fmt.Errorf("argument %d of %s: %w", index+1, name, err))
+			return
+		}
+		args[index] = argument.Elem()
+	}
+
+	results := method.Call(args)
+	if count := len(results); count > 0 && method.Type().Out(count-1) == reflect.TypeFor[error]() {
+		if err, _ := results[count-1].Interface().(error); err != nil {
+			writeServeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		results = results[:count-1]
+	}
+	var result any
+	if len(results) > 0 {
+		result = results[0].Interface()
+	}
+	writeServeJSON(w, http.StatusOK, s.browserValue(result))
+}
+
+// browserValue reports deletion as disabled in the profiles a read-only
+// server sends.
+func (s *webServer) browserValue(value any) any {
+	if profile, ok := value.(Profile); ok && !s.allowDelete {
+		profile.AllowDelete = false
+		profile.AllowPermanentDelete = false
+		return profile
+	}
+	return value
+}
+
+// serveLog writes messages the page logs through the runtime to the
+// application log, as the desktop window does.
+func (s *webServer) serveLog(w http.ResponseWriter, r *http.Request) {
+	var entry struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maximumServeRequest)).Decode(&entry); err != nil {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("decode log entry: %w", err))
+		return
+	}
+	logger := s.app.logger
+	switch entry.Level {
+	case "trace":
+		logger.Trace(entry.Message)
+	case "debug":
+		logger.Debug(entry.Message)
+	case "warning":
+		logger.Warning(entry.Message)
+	case "error":
+		logger.Error(entry.Message)
+	default:
+		logger.Info(entry.Message)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveEvents streams backend events to a browser over a WebSocket as
+// {"name": ..., "data": [...]} messages. The upgrader only accepts pages
+// served from this address.
+func (s *webServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	connection, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	events := make(chan []byte, serveEventBuffer)
+	s.clientsMu.Lock()
+	s.clients[events] = struct{}{}
+	s.clientsMu.Unlock()
+	defer s.removeClient(events)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := connection.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	defer connection.Close()
+	for {
+		select {
+		case <-closed:
+			return
+		case message, open := <-events:
+			if !open {
+				connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := connection.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *webServer) removeClient(events chan []byte) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	if _, found := s.clients[events]; found {
+		delete(s.clients, events)
+		close(events)
+	}
+}
+
+// emit sends an event to every connected browser. Browsers that fall
+// behind are disconnected and reconnect with the current state.
+func (s *webServer) emit(name string, data ...any) {
+	values := make([]any, len(data))
+	for index, value := range data {
+		values[index] = s.browserValue(value)
+	}
+	message, err := json.Marshal(struct {
+		Name string `json:"name"`
+		Data []any  `json:"data"`
+	}{name, values})
+	if err != nil {
+		s.app.logger.Warningf("could not send %s to browsers: %v", name, err)
+		return
+	}
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for events := range s.clients {
+		select {
+		case events <- message:
+		default:
+			delete(s.clients, events)
+			close(events)
+		}
+	}
+}
+
+// closeClients ends every event stream, which http.Server.Shutdown leaves
+// open because WebSockets are hijacked connections.
+func (s *webServer) closeClients() {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for events := range s.clients {
+		delete(s.clients, events)
+		close(events)
+	}
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// runServer serves app to browsers at options.address until the process is
+// interrupted, printing the address to open on output.
+func runServer(app *App, assets fs.FS, options serveOptions, output io.Writer) error {
+	if options.token == "" {
+		token, err := newServeToken()
+		if err != nil {
+			return err
+		}
+		options.token = token
+	}
+	web, err := newWebServer(app, assets, options)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", options.address)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", options.address, err)
+	}
+	host, _, _ := net.SplitHostPort(options.address)
+	if !isLoopbackHost(host) {
+		app.logger.Warningf("listening on %s, which other machines may reach; anyone with the token can browse this computer's files", listener.Addr())
+	}
+	if !options.allowDelete {
+		app.logger.Infof("serving read-only; use --serve-allow-delete to allow deleting files and saving settings")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Handler: web.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go app.watchSettingsFile(ctx, settingsWatchInterval, web.emit)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		app.CancelScan()
+		web.closeClients()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	address := url.URL{Scheme: "http", Host: listener.Addr().String(), Path: "/", RawQuery: url.Values{"token": {options.token}}.Encode()}
+	fmt.Fprintf(output, "SpaceBrowser is serving at %s\nPress Ctrl+C to stop.\n", address.String())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	app.logger.Infof("SpaceBrowser stopped")
+	return nil
+}
@@ -0,0 +1,108 @@
+// Browser stand-in for the Wails runtime, served by spacebrowser --serve in
+// place of wailsjs/runtime/runtime.js. Bound App methods are called over
+// HTTP and backend events arrive over a WebSocket.
+
+const listeners = new Map();
+
+// callBackend calls a bound App method with args and resolves with its
+// result. Failures reject with the error text, like the Wails bindings.
+export async function callBackend(method, args) {
+  let response;
+  try {
+    response = await fetch(`/api/call/${encodeURIComponent(method)}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(args),
+    });
+  } catch (error) {
+    throw `SpaceBrowser is not reachable: ${error.message || error}`;
+  }
+  const body = await response.json().catch(() => null);
+  if (!response.ok) throw body?.error || `${method} failed with HTTP ${response.status}`;
+  return body;
+}
+
+function dispatch(name, data) {
+  for (const listener of [...(listeners.get(name) || [])]) {
+    if (listener.remaining > 0 && --listener.remaining === 0) listener.cancel();
+    listener.callback(...data);
+  }
+}
+
+function connectEvents() {
+  const url = new URL("/api/events", location.href);
+  url.protocol = url.protocol === "https:" ? "wss:" : "ws:";
+  const socket = new WebSocket(url);
+  socket.addEventListener("message", message => {
+    const event = JSON.parse(message.data);
+    dispatch(event.name, event.data || []);
+  });
+  socket.addEventListener("close", () => setTimeout(connectEvents, 2000));
+}
+
+export function EventsOnMultiple(name, callback, maxCallbacks) {
+  const listener = { callback, remaining: maxCallbacks };
+  listener.cancel = () => {
+    const remaining = (listeners.get(name) || []).filter(entry => entry !== listener);
+    if (remaining.length > 0) listeners.set(name, remaining);
+    else listeners.delete(name);
+  };
+  listeners.set(name, [...(listeners.get(name) || []), listener]);
+  return listener.cancel;
+}
+
+export function EventsOn(name, callback) {
+  return EventsOnMultiple(name, callback, -1);
+}
+
+export function EventsOnce(name, callback) {
+  return EventsOnMultiple(name, callback, 1);
+}
+
+export function EventsOff(name, ...additionalNames) {
+  for (const eventName of [name, ...additionalNames]) listeners.delete(eventName);
+}
+
+export function EventsOffAll() {
+  listeners.clear();
+}
+
+// EventsEmit only reaches listeners in this page; the backend does not
+// subscribe to browser events.
+export function EventsEmit(name, ...data) {
+  dispatch(name, data);
+}
+
+function log(level, message) {
+  fetch("/api/log", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ level, message: String(message) }),
+  }).catch(() => {});
+}
+
+export const LogPrint = message => log("info", message);
+export const LogTrace = message => log("trace", message);
+export const LogDebug = message => log("debug", message);
+export const LogInfo = message => log("info", message);
+export const LogWarning = message => log("warning", message);
+export const LogError = message => log("error", message);
+export const LogFatal = message => log("error", message);
+
+export function BrowserOpenURL(url) {
+  window.open(url, "_blank", "noopener");
+}
+
+export function WindowSetTitle(title) {
+  document.title = title;
+}
+
+export function ClipboardGetText() {
+  return navigator.clipboard.readText();
+}
+
+export function ClipboardSetText(text) {
+  return navigator.clipboard.writeText(text).then(() => true, () => false);
+}
+
+connectEvents();
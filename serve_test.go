@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const testServeToken = "test-token-0123456789"
+
+func newTestWebServer(t *testing.T, allowDelete bool) (*webServer, *httptest.Server) {
+	t.Helper()
+	app := newApp(filepath.Join(t.TempDir(), "settings.json"))
+	app.profile.AllowDelete = true
+	assets := fstest.MapFS{"index.html": {Data: []byte("<!DOCTYPE html><title>SpaceBrowser</title>")}}
+	web, err := newWebServer(app, assets, serveOptions{token: testServeToken, allowDelete: allowDelete})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(web.handler())
+	t.Cleanup(server.Close)
+	return web, server
+}
+
+func callServedMethod(t *testing.T, server *httptest.Server, method string, args ...any) (int, []byte) {
+	t.Helper()
+	if args == nil {
+		args = []any{}
+	}
+	body, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := http.NewRequest(http.MethodPost, server.URL+"/api/call/"+method, strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Authorization", "Bearer "+testServeToken)
+	request.Header.Set("Content-Type", "application/json")
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode, data
+}
+
+func TestServeRequiresTheAccessToken(t *testing.T) {
+	_, server := newTestWebServer(t, false)
+	client := server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	for _, path := range []string{"/", "/index.html", "/wailsjs/go/main/App.js", "/?token=wrong-token-0123456789"} {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("GET %s without the token returned %d", path, response.StatusCode)
+		}
+	}
+
+	response, err := client.Get(server.URL + "/?token=" + testServeToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	cookies := response.Cookies()
+	if response.StatusCode != http.StatusSeeOther || response.Header.Get("Location") != "/" || len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("token login answered %d, location %q, cookies %+v", response.StatusCode, response.Header.Get("Location"), cookies)
+	}
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.AddCookie(cookies[0])
+	response, err = client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(page), "<title>SpaceBrowser</title>") {
+		t.Fatalf("GET / with the cookie returned %d: %s", response.StatusCode, page)
+	}
+}
+
+func TestServeCallsAppMethods(t *testing.T) {
+	_, server := newTestWebServer(t, false)
+	scanPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scanPath, "data.bin"), make([]byte, 64<<10), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	status, body := callServedMethod(t, server, "GetFullTree", scanPath)
+	var tree TreeInfo
+	if err := json.Unmarshal(body, &tree); status != http.StatusOK || err != nil || tree.FileCount != 1 {
+		t.Fatalf("GetFullTree answered %d: %s", status, body)
+	}
+	status, body = callServedMethod(t, server, "Layout", tree.RootID, 400, 300, 1)
+	var rects []Rect
+	if err := json.Unmarshal(body, &rects); status != http.StatusOK || err != nil || len(rects) < 2 {
+		t.Fatalf("Layout answered %d: %s", status, body)
+	}
+	if status, body = callServedMethod(t, server, "GetScanProgress"); status != http.StatusOK || !strings.HasPrefix(string(body), "{") {
+		t.Fatalf("GetScanProgress answered %d: %s", status, body)
+	}
+	if status, body = callServedMethod(t, server, "ValidateScanPath", filepath.Join(scanPath, "missing")); status != http.StatusUnprocessableEntity || !strings.Contains(string(body), `"error"`) {
+		t.Fatalf("a failing method answered %d: %s", status, body)
+	}
+	if status, _ = callServedMethod(t, server, "Layout", "root"); status != http.StatusBadRequest {
+		t.Fatalf("wrong arguments answered %d", status)
+	}
+	if status, _ = callServedMethod(t, server, "PickFolder"); status != http.StatusNotFound {
+		t.Fatalf("a native dialog answered %d", status)
+	}
+}
+
+func TestServeIsReadOnlyUnlessDeleteIsAllowed(t *testing.T) {
+	_, server := newTestWebServer(t, false)
+	if status, body := callServedMethod(t, server, "DeleteNode", 0); status != http.StatusForbidden || !strings.Contains(string(body), "--serve-allow-delete") {
+		t.Fatalf("DeleteNode answered %d: %s", status, body)
+	}
+	if status, _ := callServedMethod(t, server, "SetProfile", Profile{}); status != http.StatusForbidden {
+		t.Fatalf("SetProfile answered %d", status)
+	}
+	_, body := callServedMethod(t, server, "GetProfile")
+	var profile Profile
+	if err := json.Unmarshal(body, &profile); err != nil || profile.AllowDelete {
+		t.Fatalf("a read-only server should report deletion as disabled: %s", body)
+	}
+
+	_, server = newTestWebServer(t, true)
+	_, body = callServedMethod(t, server, "GetProfile")
+	if err := json.Unmarshal(body, &profile); err != nil || !profile.AllowDelete {
+		t.Fatalf("--serve-allow-delete should keep the saved setting: %s", body)
+	}
+	if status, body := callServedMethod(t, server, "DeleteNode", 0); status != http.StatusUnprocessableEntity {
+		t.Fatalf("DeleteNode without a scan answered %d: %s", status, body)
+	}
+}
+
+func TestServeBindingsCoverEveryAppMethod(t *testing.T) {
+	script := string(bindingsScript(&App{}))
+	for _, want := range []string{
+		"import { callBackend } from \"../../runtime/runtime.js\";",
+		"export function GetFullTree(...args) {\n  return callBackend(\"GetFullTree\", args);",
+		"export function DeleteNode(...args) {",
+		"export function PickFolder() {\n  return Promise.reject(\"PickFolder is not available in the browser.\");",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("bindings lack %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "Startup") {
+		t.Fatal("lifecycle hooks should not be exported")
+	}
+	for name := range serveMethods {
+		if !strings.Contains(script, "export function "+name+"(") {
+			t.Errorf("serveMethods lists %s, which App does not have", name)
+		}
+	}
+}
+
+func TestServeSendsEventsToBrowsers(t *testing.T) {
+	web, server := newTestWebServer(t, false)
+	address := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/events"
+	cookie := serveTokenCookie + "=" + testServeToken
+	if _, _, err := websocket.DefaultDialer.Dial(address, http.Header{"Origin": {server.URL}}); err == nil {
+		t.Fatal("an event stream opened without the token")
+	}
+	if _, _, err := websocket.DefaultDialer.Dial(address, http.Header{"Cookie": {cookie}, "Origin": {"http://example.com"}}); err == nil {
+		t.Fatal("an event stream opened from another site")
+	}
+	connection, _, err := websocket.DefaultDialer.Dial(address, http.Header{"Cookie": {cookie}, "Origin": {server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connection.Close()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		web.clientsMu.Lock()
+		connected := len(web.clients)
+		web.clientsMu.Unlock()
+		if connected == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the event stream was not registered")
+		}
+	}
+
+	web.emit(settingsReloadedEvent, web.app.GetProfile())
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event struct {
+		Name string    `json:"name"`
+		Data []Profile `json:"data"`
+	}
+	if err := connection.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != settingsReloadedEvent || len(event.Data) != 1 || event.Data[0].AllowDelete {
+		t.Fatalf("event = %+v", event)
+	}
+}
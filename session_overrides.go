@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sessionOverrides are scan settings given on the command line or in
+// SPACEBROWSER_* environment variables. They are layered over the loaded
+// settings for this run only and never saved. Nil fields keep the setting
+// of the selected scan profile.
+type sessionOverrides struct {
+	ScanProfile    string
+	ExcludedPaths  []string
+	MinFileSize    *int64
+	FollowSymlinks *bool
+	SkipHidden     *bool
+	SkipNetworkFS  *bool
+
+	// sources names the option or variable each setting came from.
+	sources map[string]string
+}
+
+// Overridable settings, in the order scan reports list them.
+const (
+	overrideScanProfile    = "Scan profile"
+	overrideExcludedPaths  = "Excluded paths"
+	overrideMinFileSize    = "Minimum file size"
+	overrideFollowSymlinks = "Follow symlinks"
+	overrideSkipHidden     = "Skip hidden"
+	overrideSkipNetworkFS  = "Skip network filesystems"
+)
+
+var overrideSettings = []string{overrideScanProfile, overrideExcludedPaths, overrideMinFileSize, overrideFollowSymlinks, overrideSkipHidden, overrideSkipNetworkFS}
+
+// overrideEnvironment maps each command-line option to its environment
+// variable. Options given on the command line win.
+var overrideEnvironment = []struct{ option, variable string }{
+	{"--profile", "SPACEBROWSER_PROFILE"},
+	{"--exclude", "SPACEBROWSER_EXCLUDE"},
+	{"--min-file-size", "SPACEBROWSER_MIN_FILE_SIZE"},
+	{"--follow-symlinks", "SPACEBROWSER_FOLLOW_SYMLINKS"},
+	{"--include-hidden", "SPACEBROWSER_INCLUDE_HIDDEN"},
+	{"--include-network", "SPACEBROWSER_INCLUDE_NETWORK"},
+}
+
+// set records value for option, naming source in scan reports.
+func (o *sessionOverrides) set(option, source, value string) error {
+	setting := ""
+	switch option {
+	case "--profile":
+		if value = strings.TrimSpace(value); value == "" {
+			return fmt.Errorf("%s requires a scan profile name", source)
+		}
+		o.ScanProfile, setting = value, overrideScanProfile
+	case "--exclude":
+		if value = strings.TrimSpace(value); value == "" {
+			return fmt.Errorf("%s requires a path", source)
+		}
+		absPath, err := filepath.Abs(value)
+		if err != nil {
+			return fmt.Errorf("%s: resolve %s: %w", source, value, err)
+		}
+		o.ExcludedPaths, setting = append(o.ExcludedPaths, filepath.Clean(absPath)), overrideExcludedPaths
+	case "--min-file-size":
+		size, err := parseByteSize(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		o.MinFileSize, setting = &size, overrideMinFileSize
+	case "--follow-symlinks", "--include-hidden", "--include-network":
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", source)
+		}
+		switch option {
+		case "--follow-symlinks":
+			o.FollowSymlinks, setting = &enabled, overrideFollowSymlinks
+		case "--include-hidden":
+			skip := !enabled
+			o.SkipHidden, setting = &skip, overrideSkipHidden
+		default:
+			skip := !enabled
+			o.SkipNetworkFS, setting = &skip, overrideSkipNetworkFS
+		}
+	default:
+		return fmt.Errorf("unknown option %q", option)
+	}
+	if o.sources == nil {
+		o.sources = make(map[string]string)
+	}
+	o.sources[setting] = source
+	return nil
+}
+
+// applyEnvironment fills the settings the command line left alone from
+// SPACEBROWSER_* variables. SPACEBROWSER_EXCLUDE holds a path list.
+func (o *sessionOverrides) applyEnvironment(getenv func(string) string) error {
+	commandLine := *o
+	for _, entry := range overrideEnvironment {
+		value := getenv(entry.variable)
+		if value == "" || commandLine.has(entry.option) {
+			continue
+		}
+		values := []string{value}
+		if entry.option == "--exclude" {
+			values = filepath.SplitList(value)
+		}
+		for _, value := range values {
+			if err := o.set(entry.option, entry.variable, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o sessionOverrides) has(option string) bool {
+	for _, source := range o.sources {
+		if source == option {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns profile with the overridden settings replaced. Excluded
+// paths are added to the profile's own.
+func (o sessionOverrides) apply(profile Profile) Profile {
+	for _, path := range o.ExcludedPaths {
+		if !slices.Contains(profile.ExcludedPaths, path) {
+			profile.ExcludedPaths = append(profile.ExcludedPaths, path)
+		}
+	}
+	if o.MinFileSize != nil {
+		profile.MinFileSize = *o.MinFileSize
+	}
+	if o.FollowSymlinks != nil {
+		profile.FollowSymlinks = *o.FollowSymlinks
+	}
+	if o.SkipHidden != nil {
+		profile.SkipHidden = *o.SkipHidden
+	}
+	if o.SkipNetworkFS != nil {
+		profile.SkipNetworkFS = *o.SkipNetworkFS
+	}
+	return profile
+}
+
+// describe lists the overridden settings with their sources, for scan
+// reports and the startup log.
+func (o sessionOverrides) describe() []string {
+	var lines []string
+	for _, setting := range overrideSettings {
+		if source, ok := o.sources[setting]; ok {
+			lines = append(lines, fmt.Sprintf("%s (%s)", setting, source))
+		}
+	}
+	return lines
+}
+
+// scanProfileNamed finds a named scan profile regardless of case. The
+// default profile reports false, as no named profile replaces it.
+func scanProfileNamed(profiles []ScanProfile, name string) (ScanProfile, bool) {
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Name, name) {
+			return profile, true
+		}
+	}
+	return ScanProfile{}, false
+}
+
+// setSessionOverrides applies overrides to every scan of this run. A forced
+// scan profile must exist in the loaded settings, and settings the system
+// policy locks cannot be overridden.
+func (a *App) setSessionOverrides(overrides sessionOverrides) error {
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	if name := overrides.ScanProfile; name != "" && !strings.EqualFold(name, defaultScanProfileName) {
+		if _, ok := scanProfileNamed(a.profile.ScanProfiles, name); !ok {
+			return fmt.Errorf("unknown scan profile %q", name)
+		}
+	}
+	overrides.ExcludedPaths = cleanProfilePaths(overrides.ExcludedPaths, a.filesystem)
+	if err := a.policy.check(overrides.apply(a.profile)); err != nil {
+		return fmt.Errorf("session override: %w", err)
+	}
+	a.overrides = overrides
+	return nil
+}
+
+func (a *App) sessionOverrideSources() []string {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	return a.overrides.describe()
+}
+
+var byteSizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// parseByteSize reads a size such as 4096, 512K or 1.5GiB. Units are binary
+// like the small-file threshold in Settings.
+func parseByteSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	number := strings.TrimRightFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	amount, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	multiplier, known := byteSizeUnits[strings.ToLower(strings.TrimSpace(value[len(number):]))]
+	if err != nil || !known || amount < 0 || amount*multiplier >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q; use bytes or a K, M, G or T suffix", value)
+	}
+	return int64(amount * multiplier), nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func TestSessionOverridesApplyWithoutChangingSettings(t *testing.T) {
+	base := t.TempDir()
+	for _, name := range []string{"cache", "keep"} {
+		if err := os.MkdirAll(filepath.Join(base, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, name, "blob.bin"), make([]byte, 4096), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	profile := app.GetProfile()
+	profile.SkipNetworkFS = false
+	profile.MinFileSize = 1 << 20
+	profile.ScanProfiles = []ScanProfile{{Name: "Archive", Roots: []string{"/srv/archive"}, MinFileSize: 1 << 20}}
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	var overrides sessionOverrides
+	for option, value := range map[string]string{"--exclude": filepath.Join(base, "cache"), "--min-file-size": "0", "--profile": "archive"} {
+		if err := overrides.set(option, option, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := app.setSessionOverrides(overrides); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := app.GetFullTree(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ScanProfile != "Archive" {
+		t.Fatalf("scan used profile %q, want the forced Archive profile", info.ScanProfile)
+	}
+	if app.store.nodeByPath(filepath.Join(base, "cache")) != nil || app.store.nodeByPath(filepath.Join(base, "keep", "blob.bin")) == nil {
+		t.Fatal("the scan ignored the session exclusion or minimum file size")
+	}
+	saved := app.GetProfile()
+	if saved.MinFileSize != 1<<20 || len(saved.ExcludedPaths) != 0 {
+		t.Fatalf("session overrides leaked into the settings: %+v", saved)
+	}
+	loaded, err := loadSettings(settingsPath)
+	if err != nil || loaded.MinFileSize != 1<<20 {
+		t.Fatalf("saved settings changed: %+v, %v", loaded, err)
+	}
+
+	scanned, _ := app.scanSettingsFor(base)
+	report := formatScanReport(scanReportDetails{RootPath: base, Profile: scanned, ScanProfile: info.ScanProfile, Overrides: app.sessionOverrideSources()}, time.Now())
+	for _, want := range []string{"Minimum file size: 0 bytes\n", "  - " + filepath.Join(base, "cache") + "\n", "Overridden for this session:\n  - Scan profile (--profile)\n"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("scan report lacks %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestSessionOverridesRejectUnknownScanProfile(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	var overrides sessionOverrides
+	if err := overrides.set("--profile", "SPACEBROWSER_PROFILE", "Missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.setSessionOverrides(overrides); err == nil {
+		t.Fatal("an unknown scan profile was accepted")
+	}
+	overrides.ScanProfile = "default"
+	if err := app.setSessionOverrides(overrides); err != nil {
+		t.Fatalf("the default scan profile was rejected: %v", err)
+	}
+}
+
+func TestSessionOverridesRejectSettingsLockedByPolicy(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	skip := true
+	app.setSystemPolicy(systemPolicy{SkipNetworkFS: &skip, path: "/etc/spacebrowser/policy.json"})
+
+	var overrides sessionOverrides
+	if err := overrides.set("--include-network", "SPACEBROWSER_INCLUDE_NETWORK", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.setSessionOverrides(overrides); !errors.Is(err, errLockedByPolicy) {
+		t.Fatalf("including network filesystems against the policy = %v, want errLockedByPolicy", err)
+	}
+	if sources := app.sessionOverrideSources(); len(sources) != 0 {
+		t.Fatalf("a rejected override is listed: %v", sources)
+	}
+	if err := overrides.set("--include-network", "--include-network", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.setSessionOverrides(overrides); err != nil {
+		t.Fatalf("an override matching the policy was rejected: %v", err)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	for value, want := range map[string]int64{"0": 0, "4096": 4096, "512K": 512 << 10, "1.5GiB": 3 << 29, "2 mb": 2 << 20, "1T": 1 << 40} {
+		if got, err := parseByteSize(value); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v, want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "K", "-1", "1.5X", "1e30T"} {
+		if _, err := parseByteSize(value); err == nil {
+			t.Errorf("parseByteSize(%q) succeeded", value)
+		}
+	}
+}
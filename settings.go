@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,7 +10,7 @@ import (
 	"spacebrowser/internal/platform"
 )
 
-const settingsFileVersion = 10
+const settingsFileVersion = 14
 
 type persistedSettings struct {
 	Version              int                `json:"version"`
@@ -17,14 +18,21 @@ type persistedSettings struct {
 	SkipHidden           bool               `json:"skipHidden"`
 	MinFileSize          int64              `json:"minFileSize"`
 	FollowSymlinks       bool               `json:"followSymlinks"`
+	KeepSmallFileDetails bool               `json:"keepSmallFileDetails"`
+	ShowVolumeContext    bool               `json:"showVolumeContext"`
 	SkipNetworkFS        bool               `json:"skipNetworkFS"`
 	ShowTooltips         bool               `json:"showTooltips"`
 	TooltipDelayMS       int                `json:"tooltipDelayMs"`
 	AllowDelete          bool               `json:"allowDelete"`
 	AllowPermanentDelete bool               `json:"allowPermanentDelete"`
 	RescanOnDelete       bool               `json:"rescanOnDelete"`
+	ProtectedPaths       []string           `json:"protectedPaths"`
+	RequireAuditLog      bool               `json:"requireAuditLog"`
+	DeleteStrategy       string             `json:"deleteStrategy"`
+	QuarantineDir        string             `json:"quarantineDir"`
 	Appearance           AppearanceSettings `json:"appearance"`
 	Controls             ControlSettings    `json:"controls"`
+	ScanProfiles         []ScanProfile      `json:"scanProfiles"`
 }
 
 type persistedSettingsLocation struct {
@@ -76,39 +84,19 @@ func loadSettingsWithFilesystem(path string, filesystem platform.ScannerFilesyst
 		return Profile{}, err
 	}
 
-	var saved persistedSettings
-	if err := json.Unmarshal(data, &saved); err != nil {
-		return Profile{}, fmt.Errorf("decode settings: %w", err)
-	}
-	if saved.Version < 1 || saved.Version > settingsFileVersion {
-		return Profile{}, fmt.Errorf("unsupported settings version %d", saved.Version)
-	}
-	appearance := saved.Appearance
-	if saved.Version == 1 {
-		appearance = defaultAppearanceSettings()
-	} else if saved.Version < 8 {
-		appearance.HoverBrightness = defaultAppearanceSettings().HoverBrightness
-	}
-	allowDelete := saved.AllowDelete
-	allowPermanentDelete := saved.AllowPermanentDelete
-	rescanOnDelete := saved.RescanOnDelete
-	if saved.Version < 3 {
-		defaults := defaultProfile()
-		allowDelete = defaults.AllowDelete
-		rescanOnDelete = defaults.RescanOnDelete
+	doc, version, err := decodeSettingsDocument(data)
+	if err != nil {
+		return Profile{}, err
 	}
-	if saved.Version < 6 {
-		allowPermanentDelete = defaultProfile().AllowPermanentDelete
+	if err := migrateSettings(doc, version); err != nil {
+		return Profile{}, err
 	}
-	controls := saved.Controls
-	if saved.Version < 7 {
-		controls = defaultControlSettings()
+	if data, err = json.Marshal(doc); err != nil {
+		return Profile{}, fmt.Errorf("encode migrated settings: %w", err)
 	}
-	showTooltips := saved.ShowTooltips
-	tooltipDelayMS := saved.TooltipDelayMS
-	if saved.Version < 10 {
-		showTooltips = defaultProfile().ShowTooltips
-		tooltipDelayMS = defaultProfile().TooltipDelayMS
+	var saved persistedSettings
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return Profile{}, fmt.Errorf("decode settings: %w", err)
 	}
 
 	return normalizeProfileWithFilesystem(Profile{
@@ -116,14 +104,21 @@ func loadSettingsWithFilesystem(path string, filesystem platform.ScannerFilesyst
 		SkipHidden:           saved.SkipHidden,
 		MinFileSize:          saved.MinFileSize,
 		FollowSymlinks:       saved.FollowSymlinks,
+		KeepSmallFileDetails: saved.KeepSmallFileDetails,
+		ShowVolumeContext:    saved.ShowVolumeContext,
 		SkipNetworkFS:        saved.SkipNetworkFS,
-		ShowTooltips:         showTooltips,
-		TooltipDelayMS:       tooltipDelayMS,
-		AllowDelete:          allowDelete,
-		AllowPermanentDelete: allowPermanentDelete,
-		RescanOnDelete:       rescanOnDelete,
-		Appearance:           appearance,
-		Controls:             controls,
+		ShowTooltips:         saved.ShowTooltips,
+		TooltipDelayMS:       saved.TooltipDelayMS,
+		AllowDelete:          saved.AllowDelete,
+		AllowPermanentDelete: saved.AllowPermanentDelete,
+		RescanOnDelete:       saved.RescanOnDelete,
+		ProtectedPaths:       saved.ProtectedPaths,
+		RequireAuditLog:      saved.RequireAuditLog,
+		DeleteStrategy:       saved.DeleteStrategy,
+		QuarantineDir:        saved.QuarantineDir,
+		Appearance:           saved.Appearance,
+		Controls:             saved.Controls,
+		ScanProfiles:         saved.ScanProfiles,
 	}, filesystem)
 }
 
@@ -134,21 +129,44 @@ func saveSettings(path string, profile Profile) error {
 		SkipHidden:           profile.SkipHidden,
 		MinFileSize:          profile.MinFileSize,
 		FollowSymlinks:       profile.FollowSymlinks,
+		KeepSmallFileDetails: profile.KeepSmallFileDetails,
+		ShowVolumeContext:    profile.ShowVolumeContext,
 		SkipNetworkFS:        profile.SkipNetworkFS,
 		ShowTooltips:         profile.ShowTooltips,
 		TooltipDelayMS:       profile.TooltipDelayMS,
 		AllowDelete:          profile.AllowDelete,
 		AllowPermanentDelete: profile.AllowPermanentDelete,
 		RescanOnDelete:       profile.RescanOnDelete,
+		ProtectedPaths:       profile.ProtectedPaths,
+		RequireAuditLog:      profile.RequireAuditLog,
+		DeleteStrategy:       profile.DeleteStrategy,
+		QuarantineDir:        profile.QuarantineDir,
 		Appearance:           profile.Appearance,
 		Controls:             profile.Controls,
+		ScanProfiles:         profile.ScanProfiles,
+	}
+	existing, version, err := existingSettings(path)
+	if err != nil {
+		return err
 	}
-	data, err := json.MarshalIndent(saved, "", "  ")
+	// A file from a newer build keeps its version so that build does not
+	// migrate its own settings again.
+	saved.Version = max(saved.Version, version)
+	data, err := json.Marshal(saved)
 	if err != nil {
 		return fmt.Errorf("encode settings: %w", err)
 	}
-	data = append(data, '\n')
-	return writeSettingsFile(path, data)
+	if existing != nil {
+		if data, err = mergeJSONObjects(existing, data); err != nil {
+			return fmt.Errorf("merge settings: %w", err)
+		}
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	indented.WriteByte('\n')
+	return writeSettingsFile(path, indented.Bytes())
 }
 
 func writeSettingsFile(path string, data []byte) error {
@@ -179,6 +197,7 @@ func writeSettingsFile(path string, data []byte) error {
 	if err := temp.Close(); err != nil {
 		return fmt.Errorf("close temporary settings file: %w", err)
 	}
+	recordSettingsWrite(path, data)
 	if err := os.Rename(tempPath, path); err != nil {
 		return fmt.Errorf("replace settings file: %w", err)
 	}
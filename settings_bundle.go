@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const settingsBundleFormat = "spacebrowser-settings-bundle"
+
+// Settings bundle sections.
+const (
+	bundleSectionAppearance = "appearance"
+	bundleSectionControls   = "controls"
+	bundleSectionExclusions = "exclusions"
+	bundleSectionProfiles   = "profiles"
+)
+
+var settingsBundleSections = []string{bundleSectionAppearance, bundleSectionControls, bundleSectionExclusions, bundleSectionProfiles}
+
+// SettingsBundle carries selected settings sections between machines. Only
+// the sections listed in Sections are present.
+type SettingsBundle struct {
+	Format          string              `json:"format"`
+	SettingsVersion int                 `json:"settingsVersion"`
+	ExportedAt      time.Time           `json:"exportedAt"`
+	Platform        string              `json:"platform"`
+	Sections        []string            `json:"sections"`
+	Appearance      *AppearanceSettings `json:"appearance,omitempty"`
+	Controls        *ControlSettings    `json:"controls,omitempty"`
+	ExcludedPaths   []string            `json:"excludedPaths,omitempty"`
+	ScanProfiles    []ScanProfile       `json:"scanProfiles,omitempty"`
+}
+
+// PathMapping rewrites paths inside From, or From itself, to the same place
+// inside To when a bundle is imported.
+type PathMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SettingsBundleImport selects which sections of the bundle at Path are
+// imported and how their paths are remapped. No sections imports all of
+// them.
+type SettingsBundleImport struct {
+	Path         string        `json:"path"`
+	Sections     []string      `json:"sections"`
+	PathMappings []PathMapping `json:"pathMappings"`
+}
+
+// SettingChange is one line of an import preview.
+type SettingChange struct {
+	Section string `json:"section"`
+	Setting string `json:"setting"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+}
+
+// SettingsBundlePreview describes what importing a bundle would change.
+type SettingsBundlePreview struct {
+	Platform   string          `json:"platform"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Sections   []string        `json:"sections"`
+	Changes    []SettingChange `json:"changes"`
+}
+
+func validBundleSections(sections []string) ([]string, error) {
+	if len(sections) == 0 {
+		return slices.Clone(settingsBundleSections), nil
+	}
+	var valid []string
+	for _, section := range sections {
+		if !slices.Contains(settingsBundleSections, section) {
+			return nil, fmt.Errorf("unknown settings section %q", section)
+		}
+		if !slices.Contains(valid, section) {
+			valid = append(valid, section)
+		}
+	}
+	return valid, nil
+}
+
+func newSettingsBundle(profile Profile, sections []string, now time.Time) SettingsBundle {
+	bundle := SettingsBundle{
+		Format:          settingsBundleFormat,
+		SettingsVersion: settingsFileVersion,
+		ExportedAt:      now.UTC(),
+		Platform:        profile.PlatformSystem,
+		Sections:        sections,
+	}
+	for _, section := range sections {
+		switch section {
+		case bundleSectionAppearance:
+			appearance := profile.Appearance
+			bundle.Appearance = &appearance
+		case bundleSectionControls:
+			controls := profile.Controls
+			bundle.Controls = &controls
+		case bundleSectionExclusions:
+			bundle.ExcludedPaths = slices.Clone(profile.ExcludedPaths)
+		case bundleSectionProfiles:
+			bundle.ScanProfiles = slices.Clone(profile.ScanProfiles)
+		}
+	}
+	return bundle
+}
+
+func readSettingsBundle(path string) (SettingsBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SettingsBundle{}, fmt.Errorf("read settings bundle: %w", err)
+	}
+	var bundle SettingsBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return SettingsBundle{}, fmt.Errorf("decode settings bundle: %w", err)
+	}
+	if bundle.Format != settingsBundleFormat {
+		return SettingsBundle{}, fmt.Errorf("%s is not a SpaceBrowser settings bundle", path)
+	}
+	if _, err := validBundleSections(bundle.Sections); err != nil {
+		return SettingsBundle{}, err
+	}
+	return bundle, nil
+}
+
+// remapBundlePath applies the first mapping whose From contains path. Paths
+// from another platform get this platform's separators once remapped.
+func remapBundlePath(path string, mappings []PathMapping, foreign, caseInsensitive bool) string {
+	for _, mapping := range mappings {
+		from := strings.TrimRight(strings.TrimSpace(mapping.From), `/\`)
+		to := strings.TrimSpace(mapping.To)
+		if from == "" || to == "" || len(path) < len(from) {
+			continue
+		}
+		prefix, rest := path[:len(from)], path[len(from):]
+		if !pathsEqual(prefix, from, caseInsensitive) || (rest != "" && rest[0] != '/' && rest[0] != '\\') {
+			continue
+		}
+		if foreign {
+			rest = filepath.FromSlash(strings.ReplaceAll(rest, `\`, "/"))
+		}
+		return filepath.Clean(to + rest)
+	}
+	return path
+}
+
+// applySettingsBundle returns profile with the chosen sections of bundle
+// imported.
+func applySettingsBundle(profile Profile, bundle SettingsBundle, sections []string, mappings []PathMapping) (Profile, error) {
+	foreign := bundle.Platform != profile.PlatformSystem
+	caseInsensitive := bundle.Platform == "windows"
+	remap := func(paths []string) []string {
+		remapped := make([]string, len(paths))
+		for index, path := range paths {
+			remapped[index] = remapBundlePath(path, mappings, foreign, caseInsensitive)
+		}
+		return remapped
+	}
+	for _, section := range sections {
+		if !slices.Contains(bundle.Sections, section) {
+			return Profile{}, fmt.Errorf("the settings bundle has no %s section", section)
+		}
+		switch section {
+		case bundleSectionAppearance:
+			if bundle.Appearance != nil {
+				profile.Appearance = *bundle.Appearance
+			}
+		case bundleSectionControls:
+			if bundle.Controls != nil {
+				profile.Controls = *bundle.Controls
+			}
+		case bundleSectionExclusions:
+			profile.ExcludedPaths = remap(bundle.ExcludedPaths)
+		case bundleSectionProfiles:
+			profile.ScanProfiles = nil
+			for _, scan := range bundle.ScanProfiles {
+				scan.Roots = remap(scan.Roots)
+				scan.ExcludedPaths = remap(scan.ExcludedPaths)
+				profile.ScanProfiles = append(profile.ScanProfiles, scan)
+			}
+		}
+	}
+	return profile, nil
+}
+
+// settingsChanges lists the differences between two normalized profiles in
+// the bundle sections.
+func settingsChanges(before, after Profile) []SettingChange {
+	changes := []SettingChange{}
+	changes = append(changes, fieldChanges(bundleSectionAppearance, before.Appearance, after.Appearance)...)
+	changes = append(changes, fieldChanges(bundleSectionControls, before.Controls, after.Controls)...)
+	changes = append(changes, listChanges(bundleSectionExclusions, "excluded path", before.ExcludedPaths, after.ExcludedPaths)...)
+
+	names := func(profiles []ScanProfile) map[string]ScanProfile {
+		byName := make(map[string]ScanProfile, len(profiles))
+		for _, scan := range profiles {
+			byName[scan.Name] = scan
+		}
+		return byName
+	}
+	beforeProfiles, afterProfiles := names(before.ScanProfiles), names(after.ScanProfiles)
+	for _, scan := range before.ScanProfiles {
+		if _, kept := afterProfiles[scan.Name]; !kept {
+			changes = append(changes, SettingChange{Section: bundleSectionProfiles, Setting: scan.Name, Before: strings.Join(scan.Roots, ", ")})
+		}
+	}
+	for _, scan := range after.ScanProfiles {
+		previous, existed := beforeProfiles[scan.Name]
+		if !existed {
+			changes = append(changes, SettingChange{Section: bundleSectionProfiles, Setting: scan.Name, After: strings.Join(scan.Roots, ", ")})
+		} else if !reflect.DeepEqual(previous, scan) {
+			changes = append(changes, SettingChange{Section: bundleSectionProfiles, Setting: scan.Name, Before: fmt.Sprintf("%+v", previous), After: fmt.Sprintf("%+v", scan)})
+		}
+	}
+	return changes
+}
+
+// fieldChanges compares two structs of the same type field by field, naming
+// fields by their JSON keys.
+func fieldChanges(section string, previous, current any) []SettingChange {
+	var changes []SettingChange
+	previousValue, currentValue := reflect.ValueOf(previous), reflect.ValueOf(current)
+	for index := 0; index < previousValue.NumField(); index++ {
+		before, after := fmt.Sprint(previousValue.Field(index).Interface()), fmt.Sprint(currentValue.Field(index).Interface())
+		if before != after {
+			name, _, _ := strings.Cut(previousValue.Type().Field(index).Tag.Get("json"), ",")
+			changes = append(changes, SettingChange{Section: section, Setting: name, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+func listChanges(section, setting string, before, after []string) []SettingChange {
+	var changes []SettingChange
+	for _, path := range before {
+		if !slices.Contains(after, path) {
+			changes = append(changes, SettingChange{Section: section, Setting: setting, Before: path})
+		}
+	}
+	for _, path := range after {
+		if !slices.Contains(before, path) {
+			changes = append(changes, SettingChange{Section: section, Setting: setting, After: path})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+func newBundleTestApp(t *testing.T) *App {
+	t.Helper()
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	return newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+}
+
+func writeTestBundle(t *testing.T, bundle SettingsBundle) string {
+	t.Helper()
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSettingsBundleExportsOnlyChosenSections(t *testing.T) {
+	app := newBundleTestApp(t)
+	profile := app.GetProfile()
+	profile.ExcludedPaths = []string{"/srv/cache"}
+	profile.Appearance.Palette = "ocean"
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	exportedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := app.writeSettingsBundle(path, []string{bundleSectionExclusions}, exportedAt); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := readSettingsBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Appearance != nil || bundle.Controls != nil || bundle.ScanProfiles != nil {
+		t.Fatalf("unselected sections were exported: %+v", bundle)
+	}
+	if len(bundle.ExcludedPaths) != 1 || bundle.ExcludedPaths[0] != "/srv/cache" || !bundle.ExportedAt.Equal(exportedAt) {
+		t.Fatalf("unexpected bundle %+v", bundle)
+	}
+
+	if err := app.writeSettingsBundle(path, []string{"history"}, exportedAt); err == nil {
+		t.Fatal("unknown sections should be rejected")
+	}
+}
+
+func TestSettingsBundlePreviewRemapsPathsWithoutApplying(t *testing.T) {
+	app := newBundleTestApp(t)
+	appearance := defaultAppearanceSettings()
+	appearance.Palette = "retro"
+	path := writeTestBundle(t, SettingsBundle{
+		Format:        settingsBundleFormat,
+		Platform:      "windows",
+		Sections:      []string{bundleSectionAppearance, bundleSectionExclusions, bundleSectionProfiles},
+		Appearance:    &appearance,
+		ExcludedPaths: []string{`C:\Users\Ann\AppData`},
+		ScanProfiles:  []ScanProfile{{Name: "Music", Roots: []string{`C:\Users\Ann\Music`}}},
+	})
+	request := SettingsBundleImport{
+		Path:         path,
+		Sections:     []string{bundleSectionExclusions, bundleSectionProfiles},
+		PathMappings: []PathMapping{{From: `c:\users\ann\`, To: "/home/ann"}},
+	}
+
+	preview, err := app.PreviewSettingsBundle(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SettingChange{
+		{Section: bundleSectionExclusions, Setting: "excluded path", After: "/home/ann/AppData"},
+		{Section: bundleSectionProfiles, Setting: "Music", After: "/home/ann/Music"},
+	}
+	if len(preview.Changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", preview.Changes, want)
+	}
+	for index := range want {
+		if preview.Changes[index] != want[index] {
+			t.Fatalf("change %d = %+v, want %+v", index, preview.Changes[index], want[index])
+		}
+	}
+	if len(app.GetProfile().ExcludedPaths) != 0 {
+		t.Fatal("a preview should not change the settings")
+	}
+
+	if err := app.ImportSettingsBundle(request); err != nil {
+		t.Fatal(err)
+	}
+	profile := app.GetProfile()
+	if len(profile.ExcludedPaths) != 1 || profile.ExcludedPaths[0] != "/home/ann/AppData" {
+		t.Fatalf("excluded paths = %v", profile.ExcludedPaths)
+	}
+	if len(profile.ScanProfiles) != 1 || profile.ScanProfiles[0].Roots[0] != "/home/ann/Music" {
+		t.Fatalf("scan profiles = %+v", profile.ScanProfiles)
+	}
+	if profile.Appearance.Palette == "retro" {
+		t.Fatal("an unselected section was imported")
+	}
+}
+
+func TestSettingsBundleImportValidatesTheResult(t *testing.T) {
+	app := newBundleTestApp(t)
+	path := writeTestBundle(t, SettingsBundle{
+		Format:       settingsBundleFormat,
+		Platform:     "windows",
+		Sections:     []string{bundleSectionProfiles},
+		ScanProfiles: []ScanProfile{{Name: "Games", Roots: []string{`D:\Games`}}},
+	})
+	request := SettingsBundleImport{Path: path}
+	if _, err := app.PreviewSettingsBundle(request); err == nil || !strings.Contains(err.Error(), "must be absolute") {
+		t.Fatalf("unmapped Windows roots should be rejected, got %v", err)
+	}
+
+	request.Sections = []string{bundleSectionControls}
+	if _, err := app.PreviewSettingsBundle(request); err == nil {
+		t.Fatal("importing a section missing from the bundle should fail")
+	}
+
+	request = SettingsBundleImport{Path: path, PathMappings: []PathMapping{{From: `D:\`, To: "/mnt/data"}}}
+	if err := app.ImportSettingsBundle(request); err != nil {
+		t.Fatal(err)
+	}
+	if roots := app.GetProfile().ScanProfiles[0].Roots; roots[0] != "/mnt/data/Games" {
+		t.Fatalf("roots = %v", roots)
+	}
+}
+
+func TestSettingsBundleRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := saveSettings(path, *defaultProfile()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSettingsBundle(path); err == nil {
+		t.Fatal("a settings file is not a settings bundle")
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// settingsDocument is a settings file as raw JSON members. Migrations work on
+// it rather than on persistedSettings so members this build does not know
+// survive an upgrade.
+type settingsDocument map[string]json.RawMessage
+
+// settingsMigration upgrades a settings document from version to-1 to
+// version to. Steps whose new settings default to their zero value have no
+// apply function.
+type settingsMigration struct {
+	to          int
+	description string
+	apply       func(settingsDocument) error
+}
+
+// settingsMigrations lists every step from version 1 to settingsFileVersion
+// in order. Adding a settings version means appending a step here.
+var settingsMigrations = []settingsMigration{
+	{2, "add the appearance section", func(doc settingsDocument) error {
+		return doc.set("appearance", defaultAppearanceSettings())
+	}},
+	{3, "add the delete command settings", func(doc settingsDocument) error {
+		defaults := defaultProfile()
+		return errors.Join(doc.set("allowDelete", defaults.AllowDelete), doc.set("rescanOnDelete", defaults.RescanOnDelete))
+	}},
+	{4, "add key bindings, replaced by controls in version 7", nil},
+	{5, "add open key bindings, replaced by controls in version 7", nil},
+	{6, "add the permanent deletion permission", func(doc settingsDocument) error {
+		return doc.set("allowPermanentDelete", defaultProfile().AllowPermanentDelete)
+	}},
+	{7, "replace key bindings with the controls section", func(doc settingsDocument) error {
+		delete(doc, "keyBindings")
+		delete(doc, "input")
+		return doc.set("controls", defaultControlSettings())
+	}},
+	{8, "add the hover brightness", func(doc settingsDocument) error {
+		return doc.setIn("appearance", "hoverBrightness", defaultAppearanceSettings().HoverBrightness)
+	}},
+	{9, "add roll-over boxes", nil},
+	{10, "add the tooltip settings", func(doc settingsDocument) error {
+		defaults := defaultProfile()
+		return errors.Join(doc.set("showTooltips", defaults.ShowTooltips), doc.set("tooltipDelayMs", defaults.TooltipDelayMS))
+	}},
+	{11, "add small-file details", nil},
+	{12, "add the containing volume context", nil},
+	{13, "add the delete strategy and quarantine retention", func(doc settingsDocument) error {
+		return errors.Join(doc.set("deleteStrategy", defaultProfile().DeleteStrategy), doc.set("quarantineRetentionDays", 30))
+	}},
+	{14, "move quarantine retention to the system policy", func(doc settingsDocument) error {
+		delete(doc, "quarantineRetentionDays")
+		return nil
+	}},
+}
+
+func (doc settingsDocument) set(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	doc[key] = data
+	return nil
+}
+
+// setIn sets key inside the section object, creating the section if needed.
+func (doc settingsDocument) setIn(section, key string, value any) error {
+	inner := settingsDocument{}
+	if data, ok := doc[section]; ok && string(data) != "null" {
+		if err := json.Unmarshal(data, &inner); err != nil {
+			return fmt.Errorf("decode %s: %w", section, err)
+		}
+	}
+	if err := inner.set(key, value); err != nil {
+		return err
+	}
+	return doc.set(section, inner)
+}
+
+func decodeSettingsDocument(data []byte) (settingsDocument, int, error) {
+	var doc settingsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("decode settings: %w", err)
+	}
+	if doc == nil {
+		return nil, 0, fmt.Errorf("decode settings: the file is not a JSON object")
+	}
+	var version int
+	if err := json.Unmarshal(doc["version"], &version); err != nil || version < 1 {
+		return nil, 0, fmt.Errorf("unsupported settings version %s", doc["version"])
+	}
+	return doc, version, nil
+}
+
+// migrateSettings upgrades doc from version to settingsFileVersion. Newer
+// documents are left alone: this build reads the members it knows and keeps
+// the rest.
+func migrateSettings(doc settingsDocument, version int) error {
+	if version >= settingsFileVersion {
+		return nil
+	}
+	for _, migration := range settingsMigrations {
+		if migration.to <= version || migration.to > settingsFileVersion || migration.apply == nil {
+			continue
+		}
+		if err := migration.apply(doc); err != nil {
+			return fmt.Errorf("migrate settings to version %d (%s): %w", migration.to, migration.description, err)
+		}
+	}
+	return doc.set("version", settingsFileVersion)
+}
+
+func settingsBackupPath(path string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, version)
+}
+
+// existingSettings reads the settings file a save is about to replace, so its
+// unknown members can be kept. An older file is backed up and upgraded first.
+// It returns nil when there is no readable file, and the file's version.
+func existingSettings(path string) (json.RawMessage, int, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, nil
+	}
+	doc, version, err := decodeSettingsDocument(existing)
+	if err != nil {
+		// An unreadable file has nothing worth keeping.
+		return nil, 0, nil
+	}
+	if version < settingsFileVersion {
+		if err := writeSettingsFile(settingsBackupPath(path, version), existing); err != nil {
+			return nil, 0, fmt.Errorf("back up version %d settings: %w", version, err)
+		}
+		if err := migrateSettings(doc, version); err != nil {
+			return nil, 0, err
+		}
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encode existing settings: %w", err)
+	}
+	return data, version, nil
+}
+
+// mergeJSONObjects returns overlay with the members of base that overlay
+// lacks appended, recursing into objects both have. Overlay keeps its member
+// order; anything that is not an object pair is taken from overlay.
+func mergeJSONObjects(base, overlay json.RawMessage) (json.RawMessage, error) {
+	keys, members, ok := orderedJSONMembers(overlay)
+	var baseMembers settingsDocument
+	if !ok || json.Unmarshal(base, &baseMembers) != nil || baseMembers == nil {
+		return overlay, nil
+	}
+	var extra []string
+	for key := range baseMembers {
+		if _, exists := members[key]; !exists {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	var output bytes.Buffer
+	output.WriteByte('{')
+	for index, key := range append(keys, extra...) {
+		value, exists := members[key]
+		if !exists {
+			value = baseMembers[key]
+		} else if baseValue, shared := baseMembers[key]; shared {
+			merged, err := mergeJSONObjects(baseValue, value)
+			if err != nil {
+				return nil, err
+			}
+			value = merged
+		}
+		if index > 0 {
+			output.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		output.Write(name)
+		output.WriteByte(':')
+		output.Write(value)
+	}
+	output.WriteByte('}')
+	return output.Bytes(), nil
+}
+
+// orderedJSONMembers splits a JSON object into its members in file order,
+// or reports false when data is not an object.
+func orderedJSONMembers(data json.RawMessage) ([]string, map[string]json.RawMessage, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, false
+	}
+	var keys []string
+	members := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, false
+		}
+		if _, exists := members[key]; !exists {
+			keys = append(keys, key)
+		}
+		members[key] = value
+	}
+	return keys, members, true
+}
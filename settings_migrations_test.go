@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestSettingsMigrationsCoverEveryVersion(t *testing.T) {
+	if len(settingsMigrations) != settingsFileVersion-1 {
+		t.Fatalf("%d migrations registered for %d settings versions", len(settingsMigrations), settingsFileVersion)
+	}
+	for index, migration := range settingsMigrations {
+		if migration.to != index+2 || migration.description == "" {
+			t.Fatalf("migration %d = %+v, want a described step to version %d", index, migration, index+2)
+		}
+	}
+}
+
+// TestSettingsMigrationGoldenFiles migrates testdata/settings/vN.json, a file
+// as written by settings version N, and compares the result with
+// vN.golden.json. Run with -update to rewrite the golden files.
+func TestSettingsMigrationGoldenFiles(t *testing.T) {
+	for version := 1; version <= settingsFileVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			input := filepath.Join("testdata", "settings", fmt.Sprintf("v%d.json", version))
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, fileVersion, err := decodeSettingsDocument(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fileVersion != version {
+				t.Fatalf("%s has version %d", input, fileVersion)
+			}
+			if err := migrateSettings(doc, version); err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "settings", fmt.Sprintf("v%d.golden.json", version))
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("migrated %s:\n%s\nwant %s:\n%s", input, got, golden, want)
+			}
+			if _, err := loadSettings(input); err != nil {
+				t.Fatalf("loadSettings(%s) error = %v", input, err)
+			}
+		})
+	}
+}
+
+func TestSavingOlderSettingsBacksThemUp(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	legacy, err := os.ReadFile(filepath.Join("testdata", "settings", "v6.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, legacy, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newApp(settingsPath)
+	if err := app.SetProfile(app.GetProfile()); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(settingsBackupPath(settingsPath, 6))
+	if err != nil {
+		t.Fatalf("version 6 settings were not backed up: %v", err)
+	}
+	if !bytes.Equal(backup, legacy) {
+		t.Fatal("the backup differs from the version 6 settings")
+	}
+	saved, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc settingsDocument
+	if err := json.Unmarshal(saved, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, kept := doc["keyBindings"]; kept || string(doc["version"]) != fmt.Sprint(settingsFileVersion) {
+		t.Fatalf("upgraded settings = %s", saved)
+	}
+}
+
+func TestSavingKeepsSettingsFromNewerBuilds(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	newer := fmt.Sprintf(`{
+  "version": %d,
+  "skipHidden": true,
+  "minFileSize": 4096,
+  "appearance": {"palette": "earth", "zoomFactor": 1, "reliefStrength": 0.1, "hoverBrightness": 0.1, "glowRadius": 3},
+  "cloudSync": {"enabled": true, "account": "alex"}
+}`, settingsFileVersion+1)
+	if err := os.WriteFile(settingsPath, []byte(newer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newApp(settingsPath)
+	profile := app.GetProfile()
+	if !profile.SkipHidden || profile.MinFileSize != 4096 || profile.Appearance.Palette != "earth" {
+		t.Fatalf("known settings of a newer file were not loaded: %+v", profile)
+	}
+	profile.MinFileSize = 8192
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version     int             `json:"version"`
+		MinFileSize int64           `json:"minFileSize"`
+		CloudSync   json.RawMessage `json:"cloudSync"`
+		Appearance  struct {
+			GlowRadius int `json:"glowRadius"`
+		} `json:"appearance"`
+	}
+	if err := json.Unmarshal(saved, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != settingsFileVersion+1 || doc.MinFileSize != 8192 || doc.CloudSync == nil || doc.Appearance.GlowRadius != 3 {
+		t.Fatalf("settings saved over a newer file = %s", saved)
+	}
+	if _, err := os.Stat(settingsBackupPath(settingsPath, settingsFileVersion+1)); !os.IsNotExist(err) {
+		t.Fatal("a newer settings file was backed up as if it were upgraded")
+	}
+}
@@ -25,6 +25,7 @@ func TestSettingsPersistAcrossAppInstances(t *testing.T) {
 		AllowDelete:          true,
 		AllowPermanentDelete: true,
 		RescanOnDelete:       false,
+		DeleteStrategy:       deleteStrategyTrash,
 		Appearance: AppearanceSettings{
 			Palette:         "ocean",
 			ZoomFactor:      1.4,
@@ -251,6 +252,9 @@ func TestVersionTwoSettingsGainDefaultDeletionSettings(t *testing.T) {
 		t.Fatalf("deletion settings = (%v, %v), want defaults (%v, %v)",
 			got.AllowDelete, got.RescanOnDelete, defaults.AllowDelete, defaults.RescanOnDelete)
 	}
+	if got.DeleteStrategy != deleteStrategyTrash {
+		t.Fatalf("delete strategy = %q, want %q", got.DeleteStrategy, deleteStrategyTrash)
+	}
 }
 
 func TestVersionOneSettingsGainDefaultAppearance(t *testing.T) {
@@ -268,7 +272,7 @@ func TestVersionOneSettingsGainDefaultAppearance(t *testing.T) {
 	}
 
 	got := newApp(settingsPath).GetProfile()
-	if got.Appearance != defaultAppearanceSettings() {
+	if !reflect.DeepEqual(got.Appearance, defaultAppearanceSettings()) {
 		t.Fatalf("appearance = %#v, want defaults %#v", got.Appearance, defaultAppearanceSettings())
 	}
 }
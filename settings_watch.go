@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const settingsWatchInterval = 2 * time.Second
+
+// Events sent to the frontend when the active settings file changes on disk.
+// The first carries the reloaded Profile, the second the reason the change
+// was ignored.
+const (
+	settingsReloadedEvent     = "settings-reloaded"
+	settingsReloadFailedEvent = "settings-reload-failed"
+)
+
+// ownSettingsWrites maps each path writeSettingsFile wrote to the checksum
+// of its last contents, so the watcher does not reload our own saves.
+var ownSettingsWrites sync.Map
+
+func recordSettingsWrite(path string, data []byte) {
+	ownSettingsWrites.Store(filepath.Clean(path), sha256.Sum256(data))
+}
+
+func isOwnSettingsWrite(path string, sum [sha256.Size]byte) bool {
+	recorded, ok := ownSettingsWrites.Load(filepath.Clean(path))
+	return ok && recorded.([sha256.Size]byte) == sum
+}
+
+// settingsWatch is what the watcher last saw of the active settings file.
+type settingsWatch struct {
+	path    string
+	exists  bool
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+}
+
+// checkSettingsFile reloads the active settings file when another program
+// changed it since the previous check, and reports whether it did. The first
+// check of a path only records it. Changes that do not load, or that the
+// system policy does not allow, are returned as errors and leave the current
+// settings in place.
+func (a *App) checkSettingsFile(watch *settingsWatch) (bool, error) {
+	path := a.GetSettingsPath()
+	if path == "" {
+		return false, nil
+	}
+	first := path != watch.path
+	info, err := os.Stat(path)
+	if err != nil {
+		*watch = settingsWatch{path: path}
+		return false, nil
+	}
+	if !first && watch.exists && info.ModTime().Equal(watch.modTime) && info.Size() == watch.size {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Report an unreadable file once, not on every check.
+		*watch = settingsWatch{path: path, exists: true, modTime: info.ModTime(), size: info.Size()}
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	unchanged := watch.exists && sum == watch.sum
+	*watch = settingsWatch{path: path, exists: true, modTime: info.ModTime(), size: info.Size(), sum: sum}
+	if first || unchanged || isOwnSettingsWrite(path, sum) {
+		return false, nil
+	}
+
+	profile, err := loadSettingsWithFilesystem(path, a.filesystem)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	if a.settingsPath != path {
+		return false, nil
+	}
+	if err := a.policy.check(profile); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	a.profile = profile
+	a.store.SetProtection(newPathProtection(profile))
+	return true, nil
+}
+
+// watchSettingsFile polls the active settings file until ctx ends and tells
+// the frontend about external changes through emit. Polling also notices
+// edits that sync clients and network shares make without file system
+// notifications.
+func (a *App) watchSettingsFile(ctx context.Context, interval time.Duration, emit eventEmitter) {
+	var watch settingsWatch
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reloaded, err := a.checkSettingsFile(&watch)
+		if err != nil {
+			a.logger.Warningf("ignored settings file change: %v", err)
+			emit(settingsReloadFailedEvent, err.Error())
+		} else if reloaded {
+			a.logger.Infof("reloaded settings changed outside SpaceBrowser: %s", watch.path)
+			emit(settingsReloadedEvent, a.GetProfile())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
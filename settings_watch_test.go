@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"spacebrowser/internal/platform"
+)
+
+// editSettingsExternally rewrites the settings file the way another program
+// would, bypassing writeSettingsFile.
+func editSettingsExternally(t *testing.T, path string, edit func(settingsDocument)) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := decodeSettingsDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(doc)
+	if data, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	writeExternalSettings(t, path, data)
+}
+
+func writeExternalSettings(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Make the change visible even on file systems with coarse timestamps.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckSettingsFileReloadsExternalEdits(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	if err := app.SetProfile(app.GetProfile()); err != nil {
+		t.Fatal(err)
+	}
+
+	var watch settingsWatch
+	if reloaded, err := app.checkSettingsFile(&watch); reloaded || err != nil {
+		t.Fatalf("the first check should only record the file, got %v, %v", reloaded, err)
+	}
+
+	profile := app.GetProfile()
+	profile.MinFileSize = 8192
+	if err := app.SetProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := app.checkSettingsFile(&watch); reloaded || err != nil {
+		t.Fatalf("our own save was reloaded: %v, %v", reloaded, err)
+	}
+
+	editSettingsExternally(t, settingsPath, func(doc settingsDocument) {
+		if err := doc.set("minFileSize", 65536); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if reloaded, err := app.checkSettingsFile(&watch); !reloaded || err != nil {
+		t.Fatalf("the external edit was not reloaded: %v, %v", reloaded, err)
+	}
+	if got := app.GetProfile().MinFileSize; got != 65536 {
+		t.Fatalf("MinFileSize = %d after reload, want 65536", got)
+	}
+	if reloaded, err := app.checkSettingsFile(&watch); reloaded || err != nil {
+		t.Fatalf("an unchanged file was reloaded again: %v, %v", reloaded, err)
+	}
+}
+
+func TestCheckSettingsFileRejectsInvalidEdits(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	if err := app.SetProfile(app.GetProfile()); err != nil {
+		t.Fatal(err)
+	}
+	var watch settingsWatch
+	if _, err := app.checkSettingsFile(&watch); err != nil {
+		t.Fatal(err)
+	}
+	before := app.GetProfile()
+
+	editSettingsExternally(t, settingsPath, func(doc settingsDocument) {
+		if err := doc.set("tooltipDelayMs", 5000); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if reloaded, err := app.checkSettingsFile(&watch); reloaded || err == nil {
+		t.Fatalf("an invalid edit was applied: %v, %v", reloaded, err)
+	}
+	if app.GetProfile().TooltipDelayMS != before.TooltipDelayMS {
+		t.Fatal("the rejected edit changed the settings")
+	}
+	if _, err := app.checkSettingsFile(&watch); err != nil {
+		t.Fatalf("the rejected edit was reported twice: %v", err)
+	}
+
+	writeExternalSettings(t, settingsPath, []byte("{not json"))
+	if reloaded, err := app.checkSettingsFile(&watch); reloaded || err == nil {
+		t.Fatalf("a damaged file was applied: %v, %v", reloaded, err)
+	}
+}
+
+func TestCheckSettingsFileRejectsEditsThePolicyLocks(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	app := newAppWithDependencies(settingsPath, settingsPath, NewSeverityLogger(verbosityInfo, io.Discard), platform.Impl, nil, nil)
+	forbidden := false
+	app.setSystemPolicy(systemPolicy{AllowDelete: &forbidden, path: "/etc/spacebrowser/policy.json"})
+	if err := app.SetProfile(app.GetProfile()); err != nil {
+		t.Fatal(err)
+	}
+	var watch settingsWatch
+	if _, err := app.checkSettingsFile(&watch); err != nil {
+		t.Fatal(err)
+	}
+	before := app.GetProfile()
+
+	editSettingsExternally(t, settingsPath, func(doc settingsDocument) {
+		if err := doc.set("allowDelete", true); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.set("minFileSize", 65536); err != nil {
+			t.Fatal(err)
+		}
+	})
+	reloaded, err := app.checkSettingsFile(&watch)
+	if reloaded || !errors.Is(err, errLockedByPolicy) {
+		t.Fatalf("an edit the policy locks was applied: %v, %v", reloaded, err)
+	}
+	if after := app.GetProfile(); after.AllowDelete || after.MinFileSize != before.MinFileSize {
+		t.Fatalf("the rejected edit changed the settings: %+v", after)
+	}
+}
@@ -11,19 +11,23 @@ import (
 )
 
 // TreeStore owns the currently scanned tree and its dense node index.
-// It also applies successful filesystem deletions to the in-memory model.
+// It also applies successful filesystem deletions to the in-memory model and
+// refuses those that would remove a protected path.
 type TreeStore struct {
-	mu        sync.RWMutex
-	root      *Node
-	nodes     []*Node // nodes[id] == *Node
-	fileCount int
-	dirCount  int
+	mu         sync.RWMutex
+	root       *Node
+	nodes      []*Node // nodes[id] == *Node
+	fileCount  int
+	dirCount   int
+	protection pathProtection
 }
 
 type DeleteResult struct {
-	FileCount      int  `json:"fileCount"`
-	DirCount       int  `json:"dirCount"`
-	RescanRequired bool `json:"rescanRequired"`
+	FileCount      int                `json:"fileCount"`
+	DirCount       int                `json:"dirCount"`
+	RescanRequired bool               `json:"rescanRequired"`
+	Items          []DeleteItemResult `json:"items,omitempty"` // only for batch deletions and undo
+	UndoCount      int                `json:"undoCount"`
 	trashRefreshes []trashRefreshTarget
 }
 
@@ -41,6 +45,44 @@ func (s *TreeStore) NodePath(nodeID int) (string, error) {
 	return s.nodes[nodeID].FullPath, nil
 }
 
+// NodeSizes maps the paths of the available nodeIDs to their sizes, so that
+// audit entries can report the size of items about to be removed.
+func (s *TreeStore) NodeSizes(nodeIDs []int) map[string]int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sizes := make(map[string]int64, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if nodeID >= 0 && nodeID < len(s.nodes) && s.nodes[nodeID] != nil && s.nodes[nodeID].FullPath != "" {
+			sizes[s.nodes[nodeID].FullPath] = s.nodes[nodeID].Size
+		}
+	}
+	return sizes
+}
+
+// PathSize returns the size of path when it is part of the current tree.
+func (s *TreeStore) PathSize(path string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if node := s.nodeByPath(path); node != nil {
+		return node.Size
+	}
+	return 0
+}
+
+// SetProtection replaces the policy consulted before every deletion.
+func (s *TreeStore) SetProtection(protection pathProtection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protection = protection
+}
+
+// Protects reports whether deleting path would remove a protected path.
+func (s *TreeStore) Protects(path string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protection.protects(path)
+}
+
 func (s *TreeStore) Counts() (files, dirs int) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -54,6 +96,16 @@ func (s *TreeStore) Replace(root *Node, nodes []*Node, fileCount, dirCount int)
 	s.mu.Unlock()
 }
 
+// RootPath returns the path of the scanned root, or "" before a scan.
+func (s *TreeStore) RootPath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.root == nil {
+		return ""
+	}
+	return s.root.FullPath
+}
+
 func (s *TreeStore) DiskUsageRootPath() (string, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -62,32 +114,70 @@ func (s *TreeStore) DiskUsageRootPath() (string, bool) {
 	}
 	for _, child := range s.root.Children {
 		if child.IsFreeSpace {
+			if s.root.DiskPath != "" {
+				return s.root.DiskPath, true
+			}
 			return s.root.FullPath, true
 		}
 	}
 	return "", false
 }
 
-func (s *TreeStore) UpdateDiskUsage(total, free int64) bool {
+// UpdateDiskUsage refreshes the free-space node and recomputes the
+// [Unaccounted Space] node against the current scanned total.
+func (s *TreeStore) UpdateDiskUsage(total, free, reserved int64) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.root == nil {
 		return false
 	}
+	updated := false
 	for _, child := range s.root.Children {
-		if !child.IsFreeSpace {
-			continue
+		if child.IsFreeSpace {
+			child.Size = free
+			child.DiskTotal = total
+			updated = true
 		}
-		s.root.DiskTotal = total
-		s.root.DiskFree = free
-		child.Size = free
-		child.DiskTotal = total
-		sort.Slice(s.root.Children, func(i, j int) bool {
-			return s.root.Children[i].Size > s.root.Children[j].Size
-		})
-		return true
 	}
-	return false
+	if !updated {
+		return false
+	}
+	s.root.DiskTotal = total
+	s.root.DiskFree = free
+	for _, child := range s.root.Children {
+		if child.IsUnaccounted {
+			child.Size = unaccountedSpace(total, free, volumeScannedSize(s.root))
+			child.ReservedSpace = reserved
+		}
+	}
+	sort.Slice(s.root.Children, func(i, j int) bool {
+		return s.root.Children[i].Size > s.root.Children[j].Size
+	})
+	return true
+}
+
+// unaccountedSpace is the used volume space that the scan did not attribute
+// to any file or folder.
+func unaccountedSpace(total, free, scanned int64) int64 {
+	return max(0, total-free-scanned)
+}
+
+// volumeScannedSize is the scanned size of root without the subtrees of other
+// filesystems mounted below it, which do not consume space on root's volume.
+func volumeScannedSize(root *Node) int64 {
+	size := root.Size
+	var visit func(*Node)
+	visit = func(current *Node) {
+		for _, child := range current.Children {
+			if child.Mount != nil {
+				size -= child.Size
+				continue
+			}
+			visit(child)
+		}
+	}
+	visit(root)
+	return max(0, size)
 }
 
 func (s *TreeStore) Layout(nodeID, width, height int, scale float64, showFreeSpace bool) ([]Rect, error) {
@@ -109,52 +199,33 @@ func (s *TreeStore) Layout(nodeID, width, height int, scale float64, showFreeSpa
 	if !showFreeSpace {
 		viewRoot.Children = make([]*Node, 0, len(node.Children))
 		for _, child := range node.Children {
-			if !child.IsFreeSpace {
+			if !child.isVolumeContext() {
 				viewRoot.Children = append(viewRoot.Children, child)
 			}
 		}
 	}
-	return ComputeTreemapRects(&viewRoot, float64(width), float64(height), scale), nil
+	rects := ComputeTreemapRects(&viewRoot, float64(width), float64(height), scale)
+	for index := range rects {
+		rects[index].IsProtected = s.protection.protects(rects[index].FullPath)
+	}
+	return rects, nil
 }
 
 func (s *TreeStore) DeleteNode(nodeID int, isTrashRoot, isInTrash func(string) bool, moveToTrash func(string) error) (DeleteResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if nodeID < 0 || nodeID >= len(s.nodes) || s.nodes[nodeID] == nil {
-		return DeleteResult{}, fmt.Errorf("selected item is no longer available")
-	}
-	node := s.nodes[nodeID]
-	if node.ParentID < 0 || node.FullPath == "" || node.IsFreeSpace || node.IsSmallFiles {
-		return DeleteResult{}, fmt.Errorf("the scan root and virtual items cannot be deleted")
-	}
-	if isTrashRoot != nil && isTrashRoot(node.FullPath) {
-		return DeleteResult{}, fmt.Errorf("the Trash root cannot be deleted; use Empty Trash instead")
-	}
-	if isInTrash != nil && isInTrash(node.FullPath) {
-		return DeleteResult{}, fmt.Errorf("items inside Trash cannot be deleted; restore them using the system Trash")
-	}
-	if node.ParentID >= len(s.nodes) || s.nodes[node.ParentID] == nil {
-		return DeleteResult{}, fmt.Errorf("selected item's parent is no longer available")
-	}
-	if _, err := os.Lstat(node.FullPath); err != nil {
-		if os.IsNotExist(err) {
-			return DeleteResult{}, fmt.Errorf("selected path no longer exists")
-		}
-		return DeleteResult{}, fmt.Errorf("inspect selected path: %w", err)
+	node, err := s.deletionTarget(nodeID, isTrashRoot, isInTrash)
+	if err != nil {
+		return DeleteResult{}, err
 	}
-	if err := moveToTrash(node.FullPath); err != nil {
+	if err := movePathToTrash(node.FullPath, moveToTrash); err != nil {
 		return DeleteResult{}, err
 	}
 	trashRefreshes := displayedTrashNodes(s.root, node, isTrashRoot)
 
 	parent := s.nodes[node.ParentID]
-	for index, child := range parent.Children {
-		if child == node {
-			parent.Children = append(parent.Children[:index], parent.Children[index+1:]...)
-			break
-		}
-	}
+	removeChild(parent, node)
 
 	deletedSize := node.Size
 	s.adjustAncestorSizes(parent, -deletedSize)
@@ -172,6 +243,49 @@ func (s *TreeStore) DeleteNode(nodeID int, isTrashRoot, isInTrash func(string) b
 	}, nil
 }
 
+// deletionTarget returns the node for nodeID if it may be moved to Trash.
+func (s *TreeStore) deletionTarget(nodeID int, isTrashRoot, isInTrash func(string) bool) (*Node, error) {
+	if nodeID < 0 || nodeID >= len(s.nodes) || s.nodes[nodeID] == nil {
+		return nil, fmt.Errorf("selected item is no longer available")
+	}
+	node := s.nodes[nodeID]
+	if node.ParentID < 0 || node.FullPath == "" || node.IsFreeSpace || node.IsSmallFiles || node.IsUnaccounted {
+		return nil, fmt.Errorf("the scan root and virtual items cannot be deleted")
+	}
+	if s.protection.protects(node.FullPath) {
+		return nil, errProtectedPath
+	}
+	if isTrashRoot != nil && isTrashRoot(node.FullPath) {
+		return nil, fmt.Errorf("the Trash root cannot be deleted; use Empty Trash instead")
+	}
+	if isInTrash != nil && isInTrash(node.FullPath) {
+		return nil, fmt.Errorf("items inside Trash cannot be deleted; restore them using the system Trash")
+	}
+	if node.ParentID >= len(s.nodes) || s.nodes[node.ParentID] == nil {
+		return nil, fmt.Errorf("selected item's parent is no longer available")
+	}
+	return node, nil
+}
+
+func movePathToTrash(path string, moveToTrash func(string) error) error {
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("selected path no longer exists")
+		}
+		return fmt.Errorf("inspect selected path: %w", err)
+	}
+	return moveToTrash(path)
+}
+
+func removeChild(parent, node *Node) {
+	for index, child := range parent.Children {
+		if child == node {
+			parent.Children = append(parent.Children[:index], parent.Children[index+1:]...)
+			return
+		}
+	}
+}
+
 func (s *TreeStore) ReplaceSubtree(nodeID int, scanned *Node, scannedFiles, scannedDirs int) (DeleteResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -199,6 +313,7 @@ func (s *TreeStore) ReplaceSubtree(nodeID int, scanned *Node, scannedFiles, scan
 	target.IsFolder = true
 	target.IsFreeSpace = false
 	target.IsSmallFiles = false
+	target.IsUnaccounted = false
 	target.SmallFileCount = 0
 	target.SmallFileLimit = 0
 	target.FullPath = scanned.FullPath
@@ -206,8 +321,27 @@ func (s *TreeStore) ReplaceSubtree(nodeID int, scanned *Node, scannedFiles, scan
 	target.LinkCount = scanned.LinkCount
 	target.EntryFiles = scannedFiles
 	target.EntryDirs = scannedDirs
-	target.Children = nil
+	target.Children = s.adoptSubtrees(scanned.Children, target.ID, target.Depth+1)
 
+	if target.ParentID >= 0 && target.ParentID < len(s.nodes) {
+		s.adjustAncestorSizes(s.nodes[target.ParentID], target.Size-oldSize)
+	}
+	newDescendantDirs := max(0, scannedDirs-1)
+	if target.ParentID >= 0 && target.ParentID < len(s.nodes) {
+		s.adjustAncestorEntryCounts(s.nodes[target.ParentID], scannedFiles-oldFiles, newDescendantDirs-oldDirs)
+	}
+	s.fileCount = max(0, s.fileCount-oldFiles+scannedFiles)
+	s.dirCount = max(0, s.dirCount-oldDirs+newDescendantDirs)
+	return DeleteResult{
+		FileCount:      s.fileCount,
+		DirCount:       s.dirCount,
+		RescanRequired: subtreeHasSharedAllocation(scanned),
+	}, nil
+}
+
+// adoptSubtrees copies scanned subtrees into the store under parentID,
+// assigning free node IDs. Virtual nodes keep ID -1.
+func (s *TreeStore) adoptSubtrees(sources []*Node, parentID, depth int) []*Node {
 	nextFreeID := 0
 	allocateID := func(node *Node) int {
 		for nextFreeID < len(s.nodes) && s.nodes[nextFreeID] != nil {
@@ -229,7 +363,7 @@ func (s *TreeStore) ReplaceSubtree(nodeID int, scanned *Node, scannedFiles, scan
 		node.ParentID = parentID
 		node.Depth = depth
 		node.Children = make([]*Node, 0, len(source.Children))
-		if source.IsFreeSpace || source.IsSmallFiles {
+		if source.IsFreeSpace || source.IsSmallFiles || source.IsUnaccounted {
 			node.ID = -1
 		} else {
 			node.ID = allocateID(&node)
@@ -239,24 +373,11 @@ func (s *TreeStore) ReplaceSubtree(nodeID int, scanned *Node, scannedFiles, scan
 		}
 		return &node
 	}
-	for _, child := range scanned.Children {
-		target.Children = append(target.Children, adopt(child, target.ID, target.Depth+1))
+	adopted := make([]*Node, 0, len(sources))
+	for _, source := range sources {
+		adopted = append(adopted, adopt(source, parentID, depth))
 	}
-
-	if target.ParentID >= 0 && target.ParentID < len(s.nodes) {
-		s.adjustAncestorSizes(s.nodes[target.ParentID], target.Size-oldSize)
-	}
-	newDescendantDirs := max(0, scannedDirs-1)
-	if target.ParentID >= 0 && target.ParentID < len(s.nodes) {
-		s.adjustAncestorEntryCounts(s.nodes[target.ParentID], scannedFiles-oldFiles, newDescendantDirs-oldDirs)
-	}
-	s.fileCount = max(0, s.fileCount-oldFiles+scannedFiles)
-	s.dirCount = max(0, s.dirCount-oldDirs+newDescendantDirs)
-	return DeleteResult{
-		FileCount:      s.fileCount,
-		DirCount:       s.dirCount,
-		RescanRequired: subtreeHasSharedAllocation(scanned),
-	}, nil
+	return adopted
 }
 
 func (s *TreeStore) NodePathMatches(nodeID int, predicate func(string) bool) bool {
@@ -276,6 +397,9 @@ func (s *TreeStore) EmptyTrashNode(nodeID int, isTrashRoot func(string) bool, em
 	if !node.IsFolder || node.FullPath == "" || isTrashRoot == nil || !isTrashRoot(node.FullPath) {
 		return DeleteResult{}, fmt.Errorf("the selected item is not a supported Trash root")
 	}
+	if s.protection.protects(node.FullPath) {
+		return DeleteResult{}, errProtectedPath
+	}
 	if _, err := os.Lstat(node.FullPath); err != nil {
 		if os.IsNotExist(err) {
 			return DeleteResult{}, fmt.Errorf("selected Trash no longer exists")
@@ -333,7 +457,7 @@ func subtreeEntryCounts(current *Node) (files, dirs int) {
 	}
 	if current.IsSmallFiles {
 		files += int(current.SmallFileCount)
-	} else if !current.IsFreeSpace {
+	} else if !current.IsFreeSpace && !current.IsUnaccounted {
 		if current.IsFolder {
 			dirs++
 		} else {
@@ -445,7 +569,7 @@ func subtreeHasSharedAllocation(root *Node) bool {
 	if root == nil {
 		return false
 	}
-	if !root.IsFolder && !root.IsFreeSpace && !root.IsSmallFiles && root.LinkCount > 1 {
+	if !root.IsFolder && !root.IsFreeSpace && !root.IsSmallFiles && !root.IsUnaccounted && root.LinkCount > 1 {
 		return true
 	}
 	for _, child := range root.Children {
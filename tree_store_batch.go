@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DeleteItemResult reports the outcome for one item of a batch deletion.
+type DeleteItemResult struct {
+	NodeID int    `json:"nodeId"`
+	Path   string `json:"path"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SelectionInfo summarizes a validated multi-selection for confirmation.
+type SelectionInfo struct {
+	Count int      `json:"count"`
+	Size  int64    `json:"size"`
+	Paths []string `json:"paths"`
+}
+
+// selectedNodes validates a batch selection up front, so no item is moved to
+// Trash when any other selected item could not be. Nested selections are
+// rejected because the outer folder already contains the inner item.
+func (s *TreeStore) selectedNodes(nodeIDs []int, isTrashRoot, isInTrash func(string) bool) ([]*Node, error) {
+	if len(nodeIDs) == 0 {
+		return nil, fmt.Errorf("no items are selected")
+	}
+	selected := make(map[*Node]struct{}, len(nodeIDs))
+	nodes := make([]*Node, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		node, err := s.deletionTarget(nodeID, isTrashRoot, isInTrash)
+		if err != nil {
+			if nodeID >= 0 && nodeID < len(s.nodes) && s.nodes[nodeID] != nil && s.nodes[nodeID].FullPath != "" {
+				return nil, fmt.Errorf("%s: %w", s.nodes[nodeID].FullPath, err)
+			}
+			return nil, err
+		}
+		if _, duplicate := selected[node]; duplicate {
+			return nil, fmt.Errorf("%s is selected more than once", node.FullPath)
+		}
+		selected[node] = struct{}{}
+		nodes = append(nodes, node)
+	}
+	for _, node := range nodes {
+		for current := node; current.ParentID >= 0 && current.ParentID < len(s.nodes) && s.nodes[current.ParentID] != nil; {
+			current = s.nodes[current.ParentID]
+			if _, nested := selected[current]; nested {
+				return nil, fmt.Errorf("%s is inside the selected folder %s", node.FullPath, current.FullPath)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// Selection validates nodeIDs as a batch deletion and summarizes it.
+func (s *TreeStore) Selection(nodeIDs []int, isTrashRoot, isInTrash func(string) bool) (SelectionInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	nodes, err := s.selectedNodes(nodeIDs, isTrashRoot, isInTrash)
+	if err != nil {
+		return SelectionInfo{}, err
+	}
+	info := SelectionInfo{Count: len(nodes), Paths: make([]string, len(nodes))}
+	for index, node := range nodes {
+		info.Size += node.Size
+		info.Paths[index] = node.FullPath
+	}
+	return info, nil
+}
+
+// DeleteNodes moves every selected item to Trash. The selection is validated
+// as a whole first; afterwards each item succeeds or fails on its own and the
+// result lists every outcome. Sizes of all affected ancestors are adjusted and
+// re-sorted once for the whole batch.
+func (s *TreeStore) DeleteNodes(nodeIDs []int, isTrashRoot, isInTrash func(string) bool, moveToTrash func(string) error) (DeleteResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.selectedNodes(nodeIDs, isTrashRoot, isInTrash)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	items := make([]DeleteItemResult, len(nodes))
+	deleted := make([]*Node, 0, len(nodes))
+	for index, node := range nodes {
+		items[index] = DeleteItemResult{NodeID: node.ID, Path: node.FullPath}
+		if err := movePathToTrash(node.FullPath, moveToTrash); err != nil {
+			items[index].Error = err.Error()
+			continue
+		}
+		deleted = append(deleted, node)
+	}
+
+	rescanRequired := false
+	sizeDeltas := make(map[*Node]int64)
+	for _, node := range deleted {
+		parent := s.nodes[node.ParentID]
+		removeChild(parent, node)
+		for current := parent; current != nil; {
+			sizeDeltas[current] -= node.Size
+			if current.ParentID < 0 || current.ParentID >= len(s.nodes) {
+				break
+			}
+			current = s.nodes[current.ParentID]
+		}
+		rescanRequired = rescanRequired || subtreeHasSharedAllocation(node)
+		deletedFiles, deletedDirs := s.detachSubtree(node)
+		s.adjustAncestorEntryCounts(parent, -deletedFiles, -deletedDirs)
+		s.fileCount = max(0, s.fileCount-deletedFiles)
+		s.dirCount = max(0, s.dirCount-deletedDirs)
+	}
+	// Every size must be final before any folder is sorted, or a parent could
+	// be ordered by the old size of a child the map visits later.
+	for current, delta := range sizeDeltas {
+		current.Size = max(0, current.Size+delta)
+	}
+	for current := range sizeDeltas {
+		sort.Slice(current.Children, func(i, j int) bool {
+			return current.Children[i].Size > current.Children[j].Size
+		})
+	}
+
+	var trashRefreshes []trashRefreshTarget
+	if len(deleted) > 0 {
+		trashRefreshes = displayedTrashNodes(s.root, nil, isTrashRoot)
+	}
+	return DeleteResult{
+		FileCount:      s.fileCount,
+		DirCount:       s.dirCount,
+		RescanRequired: rescanRequired,
+		Items:          items,
+		trashRefreshes: trashRefreshes,
+	}, nil
+}
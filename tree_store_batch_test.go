@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func batchTestStore(t *testing.T) (*TreeStore, map[string]*Node) {
+	t.Helper()
+	base := t.TempDir()
+	for _, dir := range []string{"vms", "Trash"} {
+		if err := os.Mkdir(filepath.Join(base, dir), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"vms/old.qcow2", "vms/older.qcow2", "vms/keep.qcow2", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(base, file), []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := &Node{ID: 0, ParentID: -1, Name: "root", FullPath: base, Size: 1000, IsFolder: true, EntryFiles: 4, EntryDirs: 3}
+	vms := &Node{ID: 1, ParentID: 0, Name: "vms", FullPath: filepath.Join(base, "vms"), Size: 900, IsFolder: true, EntryFiles: 3, EntryDirs: 1}
+	old := &Node{ID: 2, ParentID: 1, Name: "old.qcow2", FullPath: filepath.Join(base, "vms", "old.qcow2"), Size: 500}
+	older := &Node{ID: 3, ParentID: 1, Name: "older.qcow2", FullPath: filepath.Join(base, "vms", "older.qcow2"), Size: 300}
+	keep := &Node{ID: 4, ParentID: 1, Name: "keep.qcow2", FullPath: filepath.Join(base, "vms", "keep.qcow2"), Size: 100}
+	notes := &Node{ID: 5, ParentID: 0, Name: "notes.txt", FullPath: filepath.Join(base, "notes.txt"), Size: 100}
+	trash := &Node{ID: 6, ParentID: 0, Name: "Trash", FullPath: filepath.Join(base, "Trash"), IsFolder: true}
+	free := &Node{ID: -1, ParentID: 0, Name: "[Free Disk Space]", Size: 50, IsFreeSpace: true}
+	root.Children = []*Node{vms, notes, free, trash}
+	vms.Children = []*Node{old, older, keep}
+	store := &TreeStore{root: root, nodes: []*Node{root, vms, old, older, keep, notes, trash}, fileCount: 4, dirCount: 3}
+	return store, map[string]*Node{"root": root, "vms": vms, "old": old, "older": older, "keep": keep, "notes": notes, "trash": trash}
+}
+
+func TestTreeStoreBatchDeleteValidatesWholeSelection(t *testing.T) {
+	store, nodes := batchTestStore(t)
+	isTrashRoot := func(path string) bool { return path == nodes["trash"].FullPath }
+	moved := 0
+	moveToTrash := func(string) error {
+		moved++
+		return nil
+	}
+
+	for name, selection := range map[string][]int{
+		"empty":     nil,
+		"nested":    {nodes["old"].ID, nodes["vms"].ID},
+		"duplicate": {nodes["old"].ID, nodes["old"].ID},
+		"root":      {nodes["notes"].ID, nodes["root"].ID},
+		"trash":     {nodes["notes"].ID, nodes["trash"].ID},
+		"missing":   {nodes["notes"].ID, 99},
+	} {
+		if _, err := store.DeleteNodes(selection, isTrashRoot, nil, moveToTrash); err == nil {
+			t.Errorf("%s selection was accepted", name)
+		}
+	}
+	if moved != 0 {
+		t.Fatalf("%d items were moved to Trash by rejected selections", moved)
+	}
+	if _, err := store.Selection([]int{nodes["older"].ID, nodes["vms"].ID}, isTrashRoot, nil); err == nil || !strings.Contains(err.Error(), "inside the selected folder") {
+		t.Fatalf("Selection() nested error = %v", err)
+	}
+
+	info, err := store.Selection([]int{nodes["old"].ID, nodes["notes"].ID}, isTrashRoot, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Count != 2 || info.Size != 600 || info.Paths[0] != nodes["old"].FullPath {
+		t.Fatalf("Selection() = %+v", info)
+	}
+}
+
+func TestTreeStoreBatchDeleteReportsEachItemAndAdjustsSizesOnce(t *testing.T) {
+	store, nodes := batchTestStore(t)
+	failure := errors.New("permission denied")
+	moveToTrash := func(path string) error {
+		if path == nodes["older"].FullPath {
+			return failure
+		}
+		return os.Remove(path)
+	}
+
+	result, err := store.DeleteNodes([]int{nodes["old"].ID, nodes["older"].ID, nodes["notes"].ID}, nil, nil, moveToTrash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 3 || result.Items[0].Error != "" || result.Items[1].Error != failure.Error() || result.Items[2].Error != "" {
+		t.Fatalf("batch items = %+v", result.Items)
+	}
+	root, vms := nodes["root"], nodes["vms"]
+	if vms.Size != 400 || root.Size != 400 {
+		t.Fatalf("sizes after batch = vms %d, root %d; want 400, 400", vms.Size, root.Size)
+	}
+	if result.FileCount != 2 || result.DirCount != 3 || vms.EntryFiles != 2 || root.EntryFiles != 2 {
+		t.Fatalf("counts after batch = result %+v, vms %d, root %d files", result, vms.EntryFiles, root.EntryFiles)
+	}
+	if len(vms.Children) != 2 || vms.Children[0] != nodes["older"] || vms.Children[1] != nodes["keep"] {
+		t.Fatal("the failed item was removed or children were not kept in size order")
+	}
+	if root.Children[0] != vms {
+		t.Fatal("root children were not re-sorted after the batch")
+	}
+	if _, err := store.NodePath(nodes["old"].ID); err == nil {
+		t.Fatal("a trashed item is still addressable")
+	}
+	if _, err := store.NodePath(nodes["older"].ID); err != nil {
+		t.Fatalf("the failed item is no longer addressable: %v", err)
+	}
+}
+
+func TestTreeStoreBatchDeleteSortsAncestorsBySizesAfterTheBatch(t *testing.T) {
+	// Map iteration order varies between runs, so repeat the batch to catch
+	// a parent sorted before its children's sizes were adjusted.
+	for range 20 {
+		base := t.TempDir()
+		root := &Node{ID: 0, ParentID: -1, Name: "root", FullPath: base, Size: 1000, IsFolder: true}
+		nodes := []*Node{root}
+		folder := func(name string, size int64, files map[string]int64) *Node {
+			dir := &Node{ID: len(nodes), ParentID: root.ID, Name: name, FullPath: filepath.Join(base, name), Size: size, IsFolder: true}
+			if err := os.Mkdir(dir.FullPath, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			nodes = append(nodes, dir)
+			root.Children = append(root.Children, dir)
+			for fileName, fileSize := range files {
+				file := &Node{ID: len(nodes), ParentID: dir.ID, Name: fileName, FullPath: filepath.Join(dir.FullPath, fileName), Size: fileSize}
+				if err := os.WriteFile(file.FullPath, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+				nodes = append(nodes, file)
+				dir.Children = append(dir.Children, file)
+			}
+			sort.Slice(dir.Children, func(i, j int) bool { return dir.Children[i].Size > dir.Children[j].Size })
+			return dir
+		}
+		photos := folder("photos", 600, map[string]int64{"raw.tar": 500, "thumbs.db": 100})
+		music := folder("music", 400, map[string]int64{"album.flac": 350, "cover.jpg": 50})
+		store := &TreeStore{root: root, nodes: nodes}
+
+		deleteIDs := []int{photos.Children[0].ID, music.Children[1].ID}
+		if _, err := store.DeleteNodes(deleteIDs, nil, nil, func(string) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if photos.Size != 100 || music.Size != 350 || root.Size != 450 {
+			t.Fatalf("sizes after the batch: photos %d, music %d, root %d", photos.Size, music.Size, root.Size)
+		}
+		if root.Children[0] != music || root.Children[1] != photos {
+			t.Fatalf("root children are ordered %q, %q", root.Children[0].Name, root.Children[1].Name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// reclaimEstimate splits the size of a subtree into bytes that deleting the
+// queued paths frees and bytes of files with links that are not queued, which
+// stay allocated until every link is gone. queued reports whether a path lies
+// inside a queued item.
+func reclaimEstimate(node *Node, queued func(string) bool) (reclaimable, hardLinked int64) {
+	if node.IsSmallFiles && len(node.SmallFiles) > 0 {
+		for _, entry := range node.SmallFiles {
+			if entry.LinkCount > 1 && !allLinksQueued(entry.HardLinks, entry.LinkCount, queued) {
+				hardLinked += entry.Size
+			} else {
+				reclaimable += entry.Size
+			}
+		}
+		return reclaimable, hardLinked
+	}
+	if len(node.Children) == 0 {
+		if !node.IsFolder && node.LinkCount > 1 && !allLinksQueued(node.HardLinks, node.LinkCount, queued) {
+			return 0, node.Size
+		}
+		return node.Size, 0
+	}
+	for _, child := range node.Children {
+		if child.IsFreeSpace || child.IsUnaccounted {
+			continue
+		}
+		childReclaimable, childHardLinked := reclaimEstimate(child, queued)
+		reclaimable += childReclaimable
+		hardLinked += childHardLinked
+	}
+	return reclaimable, hardLinked
+}
+
+// allLinksQueued reports whether the scan reached a file through as many
+// queued paths as the file has links. Files whose links were not recorded are
+// assumed to have links elsewhere.
+func allLinksQueued(links *hardLinkSet, linkCount uint64, queued func(string) bool) bool {
+	if links == nil {
+		return false
+	}
+	var inside uint64
+	for _, path := range links.paths {
+		if queued(path) {
+			inside++
+		}
+	}
+	return inside >= linkCount
+}
+
+// queuedPaths reports whether a path lies inside one of paths.
+func queuedPaths(paths []string) func(string) bool {
+	return func(path string) bool {
+		for _, queued := range paths {
+			if pathWithin(path, queued) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func cleanupBasketItemForNode(node *Node, queued func(string) bool) CleanupBasketItem {
+	reclaimable, hardLinked := reclaimEstimate(node, queued)
+	return CleanupBasketItem{
+		Path:            node.FullPath,
+		IsFolder:        node.IsFolder,
+		Size:            node.Size,
+		ReclaimableSize: reclaimable,
+		HardLinkedSize:  hardLinked,
+		InCurrentScan:   true,
+	}
+}
+
+// CleanupItems validates nodeIDs like a batch deletion, except that items
+// inside Trash are allowed, and returns them as cleanup basket entries.
+func (s *TreeStore) CleanupItems(nodeIDs []int, isTrashRoot func(string) bool, now time.Time) ([]CleanupBasketItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	nodes, err := s.selectedNodes(nodeIDs, isTrashRoot, nil)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(nodes))
+	for index, node := range nodes {
+		paths[index] = node.FullPath
+	}
+	queued := queuedPaths(paths)
+	items := make([]CleanupBasketItem, len(nodes))
+	for index, node := range nodes {
+		items[index] = cleanupBasketItemForNode(node, queued)
+		items[index].AddedAt = now.Unix()
+	}
+	return items, nil
+}
+
+// nodeByPath walks from the scan root to path, or returns nil when path is not
+// part of the current tree.
+func (s *TreeStore) nodeByPath(path string) *Node {
+	if s.root == nil || s.root.FullPath == "" || !pathWithin(path, s.root.FullPath) {
+		return nil
+	}
+	relative, err := filepath.Rel(s.root.FullPath, path)
+	if err != nil {
+		return nil
+	}
+	current := s.root
+	if relative == "." {
+		return current
+	}
+	for _, name := range strings.Split(relative, string(filepath.Separator)) {
+		var next *Node
+		for _, child := range current.Children {
+			if child.Name == name && child.FullPath != "" && !child.IsSmallFiles {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	if current.FullPath != path {
+		return nil
+	}
+	return current
+}
+
+// RefreshCleanupItems updates the recorded sizes of queued items that are
+// part of the current scan and marks the others as outside it. Files whose
+// links are all queued count as reclaimable.
+func (s *TreeStore) RefreshCleanupItems(items []CleanupBasketItem) []CleanupBasketItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	paths := make([]string, len(items))
+	for index, item := range items {
+		paths[index] = item.Path
+	}
+	queued := queuedPaths(paths)
+	refreshed := make([]CleanupBasketItem, len(items))
+	for index, item := range items {
+		node := s.nodeByPath(item.Path)
+		if node == nil {
+			item.InCurrentScan = false
+			refreshed[index] = item
+			continue
+		}
+		current := cleanupBasketItemForNode(node, queued)
+		current.AddedAt = item.AddedAt
+		refreshed[index] = current
+	}
+	return refreshed
+}
+
+// CleanupNodeID returns the node ID of a queued path in the current scan.
+func (s *TreeStore) CleanupNodeID(path string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node := s.nodeByPath(path)
+	if node == nil {
+		return -1, fmt.Errorf("not part of the current scan; scan its location before cleaning up")
+	}
+	return node.ID, nil
+}
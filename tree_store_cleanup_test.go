@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTreeStoreCleanupItemsSeparateHardLinkedBytes(t *testing.T) {
+	store, nodes := batchTestStore(t)
+	nodes["older"].LinkCount = 2
+	small := &Node{ID: -1, ParentID: 1, Name: "[Small Files]", IsSmallFiles: true, Size: 30, SmallFiles: []SmallFileEntry{
+		{Name: "a", Size: 10},
+		{Name: "b", Size: 20, LinkCount: 3},
+	}}
+	nodes["vms"].Children = append(nodes["vms"].Children, small)
+
+	items, err := store.CleanupItems([]int{nodes["vms"].ID, nodes["notes"].ID}, nil, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].ReclaimableSize != 610 || items[0].HardLinkedSize != 320 || items[0].AddedAt != 1700000000 {
+		t.Fatalf("folder item = %+v; want 610 reclaimable and 320 hard-linked bytes", items[0])
+	}
+	if items[1].ReclaimableSize != 100 || items[1].HardLinkedSize != 0 {
+		t.Fatalf("file item = %+v", items[1])
+	}
+	if _, err := store.CleanupItems([]int{nodes["old"].ID, nodes["vms"].ID}, nil, time.Now()); err == nil {
+		t.Fatal("a nested selection was queued")
+	}
+
+	gone := CleanupBasketItem{Path: filepath.Join(nodes["root"].FullPath, "gone"), Size: 7, AddedAt: 1}
+	refreshed := store.RefreshCleanupItems([]CleanupBasketItem{{Path: nodes["old"].FullPath, AddedAt: 5}, gone})
+	if !refreshed[0].InCurrentScan || refreshed[0].Size != 500 || refreshed[0].AddedAt != 5 {
+		t.Fatalf("refreshed item = %+v", refreshed[0])
+	}
+	if refreshed[1].InCurrentScan || refreshed[1].Size != 7 {
+		t.Fatalf("item outside the scan = %+v", refreshed[1])
+	}
+	if id, err := store.CleanupNodeID(nodes["keep"].FullPath); err != nil || id != nodes["keep"].ID {
+		t.Fatalf("CleanupNodeID() = %d, %v", id, err)
+	}
+}
+
+func TestRefreshCleanupItemsCountsFilesWithEveryLinkQueued(t *testing.T) {
+	store, nodes := batchTestStore(t)
+	backup := filepath.Join(nodes["root"].FullPath, "backup")
+	nodes["older"].LinkCount = 2
+	nodes["older"].HardLinks = &hardLinkSet{paths: []string{nodes["older"].FullPath, filepath.Join(backup, "older.qcow2")}}
+	small := &Node{ID: -1, ParentID: 1, Name: "[Small Files]", IsSmallFiles: true, Size: 20, SmallFiles: []SmallFileEntry{
+		{Name: "a", Size: 20, LinkCount: 2, HardLinks: &hardLinkSet{paths: []string{
+			filepath.Join(nodes["vms"].FullPath, "a"),
+			filepath.Join(nodes["vms"].FullPath, "b"),
+		}}},
+		{Name: "b", LinkCount: 2},
+	}}
+	nodes["vms"].Children = append(nodes["vms"].Children, small)
+
+	items := store.RefreshCleanupItems([]CleanupBasketItem{{Path: nodes["vms"].FullPath}})
+	if items[0].ReclaimableSize != 620 || items[0].HardLinkedSize != 300 {
+		t.Fatalf("folder with a link outside the basket = %+v; want 620 reclaimable and 300 hard-linked bytes", items[0])
+	}
+	items = store.RefreshCleanupItems([]CleanupBasketItem{{Path: nodes["vms"].FullPath}, {Path: backup}})
+	if items[0].ReclaimableSize != 920 || items[0].HardLinkedSize != 0 {
+		t.Fatalf("folder whose links are all queued = %+v; want 920 reclaimable bytes", items[0])
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// maximumFolderSizeDepth bounds the depth option of folder size exports.
+const maximumFolderSizeDepth = 1024
+
+// FolderSizeOptions selects the rows of a folder size export. Path, when
+// set, picks the subtree instead of NodeID. MaxDepth counts levels below the
+// subtree root, which is level 0; zero means unlimited. Rows smaller than
+// MinSize allocated bytes are left out, but still count towards their
+// parents.
+type FolderSizeOptions struct {
+	NodeID       int    `json:"nodeId"`
+	Path         string `json:"path,omitempty"`
+	Format       string `json:"format"`
+	MaxDepth     int    `json:"maxDepth"`
+	MinSize      int64  `json:"minSize"`
+	IncludeFiles bool   `json:"includeFiles"`
+}
+
+// FolderSizeRow is one folder, or file when requested, of an export. Files
+// and Folders count the entries below a folder.
+type FolderSizeRow struct {
+	Path          string `json:"path"`
+	IsFolder      bool   `json:"isFolder"`
+	Depth         int    `json:"depth"`
+	AllocatedSize int64  `json:"allocatedSize"`
+	ApparentSize  int64  `json:"apparentSize"`
+	Files         int    `json:"files"`
+	Folders       int    `json:"folders"`
+	ModTime       int64  `json:"mtime"`
+	LinkCount     uint64 `json:"linkCount,omitempty"`
+}
+
+// FolderSizes lists the folders of a subtree, parents before children, with
+// their allocated and apparent sizes and entry counts. Free space,
+// unaccounted space and [Small Files] aggregates are not rows of their own.
+func (s *TreeStore) FolderSizes(options FolderSizeOptions) ([]FolderSizeRow, error) {
+	if options.MaxDepth < 0 || options.MinSize < 0 {
+		return nil, fmt.Errorf("the depth and minimum size cannot be negative")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var root *Node
+	if options.Path != "" {
+		absPath, err := filepath.Abs(options.Path)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", options.Path, err)
+		}
+		if root = s.nodeByPath(filepath.Clean(absPath)); root == nil {
+			return nil, fmt.Errorf("%s is not part of the current scan", options.Path)
+		}
+	} else if options.NodeID >= 0 && options.NodeID < len(s.nodes) {
+		root = s.nodes[options.NodeID]
+	}
+	if root == nil {
+		return nil, fmt.Errorf("selected item is no longer available")
+	}
+
+	var rows []FolderSizeRow
+	// visit returns the apparent size of current, which folders only learn
+	// after their children, so a folder's row is filled in afterwards.
+	var visit func(current *Node, depth int) int64
+	visit = func(current *Node, depth int) int64 {
+		if current.IsFreeSpace || current.IsUnaccounted {
+			return 0
+		}
+		if current.IsSmallFiles {
+			return current.ApparentSize
+		}
+		included := (current.IsFolder || options.IncludeFiles) &&
+			(options.MaxDepth == 0 || depth <= options.MaxDepth) &&
+			current.Size >= options.MinSize
+		row := -1
+		if included {
+			row = len(rows)
+			rows = append(rows, FolderSizeRow{
+				Path:          current.FullPath,
+				IsFolder:      current.IsFolder,
+				Depth:         depth,
+				AllocatedSize: current.Size,
+				ModTime:       current.ModTime,
+				LinkCount:     current.LinkCount,
+			})
+			if current.IsFolder {
+				files, dirs := subtreeEntryCounts(current)
+				rows[row].Files, rows[row].Folders = files, max(0, dirs-1)
+			}
+		}
+		apparent := current.ApparentSize
+		for _, child := range current.Children {
+			apparent += visit(child, depth+1)
+		}
+		if row >= 0 {
+			rows[row].ApparentSize = apparent
+		}
+		return apparent
+	}
+	visit(root, 0)
+	return rows, nil
+}
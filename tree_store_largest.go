@@ -0,0 +1,103 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+const (
+	largestItemsFiles   = "files"
+	largestItemsFolders = "folders"
+
+	maximumLargestItems = 10000
+)
+
+// LargestItem describes one entry of a largest-files or largest-folders
+// report. NodeID remains valid until the next mutation of the tree store.
+type LargestItem struct {
+	NodeID    int    `json:"nodeId"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	IsFolder  bool   `json:"isFolder"`
+	ModTime   int64  `json:"mtime"`
+	LinkCount uint64 `json:"linkCount,omitempty"`
+}
+
+// largestItemHeap is a min-heap on size so the smallest retained item can be
+// evicted in O(log n) while walking arbitrarily large trees.
+type largestItemHeap []*Node
+
+func (h largestItemHeap) Len() int           { return len(h) }
+func (h largestItemHeap) Less(i, j int) bool { return largerItem(h[j], h[i]) }
+func (h largestItemHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *largestItemHeap) Push(x any)        { *h = append(*h, x.(*Node)) }
+func (h *largestItemHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+func largerItem(left, right *Node) bool {
+	if left.Size != right.Size {
+		return left.Size > right.Size
+	}
+	return left.FullPath < right.FullPath
+}
+
+// Largest returns up to limit of the largest files or folders below rootID.
+// The report is computed from the live node index on every call, so it
+// reflects deletions, Trash refreshes and emptied Trash folders without a
+// separately maintained index. The subtree root itself is never reported.
+func (s *TreeStore) Largest(rootID, limit int, kind string) ([]LargestItem, error) {
+	if kind != largestItemsFiles && kind != largestItemsFolders {
+		return nil, fmt.Errorf("unknown largest item kind %q", kind)
+	}
+	if limit <= 0 || limit > maximumLargestItems {
+		return nil, fmt.Errorf("item count must be between 1 and %d", maximumLargestItems)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if rootID < 0 || rootID >= len(s.nodes) || s.nodes[rootID] == nil {
+		return nil, fmt.Errorf("selected item is no longer available")
+	}
+
+	wantFolders := kind == largestItemsFolders
+	selected := make(largestItemHeap, 0, limit)
+	stack := append(make([]*Node, 0, 64), s.nodes[rootID].Children...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == nil || current.IsFreeSpace || current.IsSmallFiles || current.IsUnaccounted || current.FullPath == "" {
+			continue
+		}
+		stack = append(stack, current.Children...)
+		if current.IsFolder != wantFolders {
+			continue
+		}
+		if len(selected) < limit {
+			heap.Push(&selected, current)
+		} else if largerItem(current, selected[0]) {
+			selected[0] = current
+			heap.Fix(&selected, 0)
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool { return largerItem(selected[i], selected[j]) })
+	result := make([]LargestItem, len(selected))
+	for index, node := range selected {
+		result[index] = LargestItem{
+			NodeID:    node.ID,
+			Name:      node.Name,
+			Path:      node.FullPath,
+			Size:      node.Size,
+			IsFolder:  node.IsFolder,
+			ModTime:   node.ModTime,
+			LinkCount: node.LinkCount,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func largestItemPaths(t *testing.T, store *TreeStore, rootID, limit int, kind string) []string {
+	t.Helper()
+	items, err := store.Largest(rootID, limit, kind)
+	if err != nil {
+		t.Fatalf("Largest(%d, %d, %q) error = %v", rootID, limit, kind, err)
+	}
+	paths := make([]string, len(items))
+	for index, item := range items {
+		paths[index] = item.Path
+	}
+	return paths
+}
+
+func TestTreeStoreLargestRanksFilesAndFoldersSeparately(t *testing.T) {
+	root := &Node{ID: 0, ParentID: -1, Name: "root", FullPath: "/root", Size: 1000, IsFolder: true}
+	media := &Node{ID: 1, ParentID: 0, Name: "media", FullPath: "/root/media", Size: 700, IsFolder: true}
+	movie := &Node{ID: 2, ParentID: 1, Name: "movie.mkv", FullPath: "/root/media/movie.mkv", Size: 500}
+	nested := &Node{ID: 3, ParentID: 1, Name: "nested", FullPath: "/root/media/nested", Size: 200, IsFolder: true}
+	song := &Node{ID: 4, ParentID: 3, Name: "song.flac", FullPath: "/root/media/nested/song.flac", Size: 200}
+	notes := &Node{ID: 5, ParentID: 0, Name: "notes.txt", FullPath: "/root/notes.txt", Size: 250}
+	small := &Node{ID: -1, ParentID: 0, Name: "[Small Files]", Size: 900, IsSmallFiles: true}
+	free := &Node{ID: -1, ParentID: 0, Name: "[Free Disk Space]", Size: 5000, IsFreeSpace: true}
+	root.Children = []*Node{free, small, media, notes}
+	media.Children = []*Node{movie, nested}
+	nested.Children = []*Node{song}
+	store := &TreeStore{root: root, nodes: []*Node{root, media, movie, nested, song, notes}}
+
+	if got, want := largestItemPaths(t, store, root.ID, 2, largestItemsFiles), []string{movie.FullPath, notes.FullPath}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("largest files = %v, want %v", got, want)
+	}
+	if got, want := largestItemPaths(t, store, root.ID, 10, largestItemsFolders), []string{media.FullPath, nested.FullPath}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("largest folders = %v, want %v", got, want)
+	}
+	if got, want := largestItemPaths(t, store, nested.ID, 10, largestItemsFiles), []string{song.FullPath}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("largest files below a subtree = %v, want %v", got, want)
+	}
+	if _, err := store.Largest(root.ID, 0, largestItemsFiles); err == nil {
+		t.Fatal("a zero item count was accepted")
+	}
+	if _, err := store.Largest(root.ID, 1, "links"); err == nil {
+		t.Fatal("an unknown item kind was accepted")
+	}
+}
+
+func TestTreeStoreLargestFollowsTreeMutations(t *testing.T) {
+	base := t.TempDir()
+	trashPath := filepath.Join(base, "Trash")
+	largePath := filepath.Join(base, "large.bin")
+	for _, path := range []string{trashPath, filepath.Join(trashPath, "old")} {
+		if err := os.MkdirAll(path, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(largePath, []byte("large"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &Node{ID: 0, ParentID: -1, Name: "root", FullPath: base, Size: 1000, IsFolder: true}
+	large := &Node{ID: 1, ParentID: 0, Name: "large.bin", FullPath: largePath, Size: 600}
+	trash := &Node{ID: 2, ParentID: 0, Name: "Trash", FullPath: trashPath, Size: 400, IsFolder: true, EntryDirs: 2, EntryFiles: 1}
+	old := &Node{ID: 3, ParentID: 2, Name: "old", FullPath: filepath.Join(trashPath, "old"), Size: 400, IsFolder: true}
+	oldFile := &Node{ID: 4, ParentID: 3, Name: "old.bin", FullPath: filepath.Join(trashPath, "old", "old.bin"), Size: 400}
+	root.Children = []*Node{large, trash}
+	trash.Children = []*Node{old}
+	old.Children = []*Node{oldFile}
+	store := &TreeStore{root: root, nodes: []*Node{root, large, trash, old, oldFile}, fileCount: 2, dirCount: 3}
+	isTrashRoot := func(path string) bool { return path == trashPath }
+
+	if _, err := store.DeleteNode(large.ID, isTrashRoot, nil, func(string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := largestItemPaths(t, store, root.ID, 5, largestItemsFiles), []string{oldFile.FullPath}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("largest files after delete = %v, want %v", got, want)
+	}
+
+	refreshed := &Node{ID: 0, ParentID: -1, Name: "Trash", FullPath: trashPath, Size: 900, IsFolder: true}
+	refreshed.Children = []*Node{{ID: 1, ParentID: 0, Name: "moved.bin", FullPath: filepath.Join(trashPath, "moved.bin"), Size: 900}}
+	if _, err := store.ReplaceSubtree(trash.ID, refreshed, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	files, err := store.Largest(root.ID, 5, largestItemsFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != filepath.Join(trashPath, "moved.bin") || files[0].Size != 900 {
+		t.Fatalf("largest files after subtree refresh = %+v", files)
+	}
+	if store.nodes[files[0].NodeID] == nil || store.nodes[files[0].NodeID].FullPath != files[0].Path {
+		t.Fatal("reported node ID does not address the refreshed node")
+	}
+
+	if _, err := store.EmptyTrashNode(trash.ID, isTrashRoot, func(string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got := largestItemPaths(t, store, root.ID, 5, largestItemsFiles); len(got) != 0 {
+		t.Fatalf("largest files after emptying Trash = %v, want none", got)
+	}
+	if got, want := largestItemPaths(t, store, root.ID, 5, largestItemsFolders), []string{trashPath}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("largest folders after emptying Trash = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import "fmt"
+
+func (s *TreeStore) mountNode(nodeID int) (*Node, error) {
+	if nodeID < 0 || nodeID >= len(s.nodes) || s.nodes[nodeID] == nil {
+		return nil, fmt.Errorf("selected item is no longer available")
+	}
+	node := s.nodes[nodeID]
+	if node.Mount == nil {
+		return nil, fmt.Errorf("the selected folder is not a mount point")
+	}
+	if node.ParentID < 0 {
+		return nil, fmt.Errorf("the scan root cannot be collapsed or excluded")
+	}
+	return node, nil
+}
+
+// SetMountCollapsed draws a mounted filesystem as a single block, or expands
+// it again, without changing its size or contents.
+func (s *TreeStore) SetMountCollapsed(nodeID int, collapsed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, err := s.mountNode(nodeID)
+	if err != nil {
+		return err
+	}
+	node.MountCollapsed = collapsed
+	return nil
+}
+
+// ExcludeMount removes a mounted filesystem from the in-memory tree as if it
+// had been excluded from the scan. Nothing on disk is changed.
+func (s *TreeStore) ExcludeMount(nodeID int) (DeleteResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, err := s.mountNode(nodeID)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	if node.ParentID >= len(s.nodes) || s.nodes[node.ParentID] == nil {
+		return DeleteResult{}, fmt.Errorf("selected item's parent is no longer available")
+	}
+
+	parent := s.nodes[node.ParentID]
+	removeChild(parent, node)
+	s.adjustAncestorSizes(parent, -node.Size)
+	excludedFiles, excludedDirs := s.detachSubtree(node)
+	s.adjustAncestorEntryCounts(parent, -excludedFiles, -excludedDirs)
+	s.fileCount = max(0, s.fileCount-excludedFiles)
+	s.dirCount = max(0, s.dirCount-excludedDirs)
+	return DeleteResult{FileCount: s.fileCount, DirCount: s.dirCount}, nil
+}
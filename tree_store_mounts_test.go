@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"spacebrowser/internal/platform"
+)
+
+func mountTestStore() (*TreeStore, *Node, *Node) {
+	root := &Node{ID: 0, ParentID: -1, Name: "/", FullPath: "/", Size: 1000, IsFolder: true, EntryFiles: 3, EntryDirs: 3}
+	home := &Node{ID: 1, ParentID: 0, Name: "home", FullPath: "/home", Size: 200, IsFolder: true}
+	notes := &Node{ID: 2, ParentID: 1, Name: "notes.txt", FullPath: "/home/notes.txt", Size: 200}
+	srv := &Node{ID: 3, ParentID: 0, Name: "srv", FullPath: "/srv", Size: 800, IsFolder: true, Mount: &platform.MountInfo{MountPoint: "/srv", FilesystemType: "xfs", Device: "8:17", Source: "/dev/sdb1"}}
+	backup := &Node{ID: 4, ParentID: 3, Name: "backup.img", FullPath: "/srv/backup.img", Size: 500}
+	media := &Node{ID: 5, ParentID: 3, Name: "media.mkv", FullPath: "/srv/media.mkv", Size: 300}
+	root.Children = []*Node{srv, home}
+	home.Children = []*Node{notes}
+	srv.Children = []*Node{backup, media}
+	store := &TreeStore{root: root, nodes: []*Node{root, home, notes, srv, backup, media}, fileCount: 3, dirCount: 3}
+	return store, root, srv
+}
+
+func TestTreeStoreCollapsesMountWithoutChangingTree(t *testing.T) {
+	store, root, srv := mountTestStore()
+	if err := store.SetMountCollapsed(1, true); err == nil {
+		t.Fatal("a folder that is not a mount point was collapsed")
+	}
+	if err := store.SetMountCollapsed(srv.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	rects, err := store.Layout(root.ID, 400, 300, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srvRect *Rect
+	for index := range rects {
+		if rects[index].FullPath == "/srv/backup.img" {
+			t.Fatal("a collapsed mount still laid out its contents")
+		}
+		if rects[index].NodeID == srv.ID {
+			srvRect = &rects[index]
+		}
+	}
+	if srvRect == nil || !srvRect.MountCollapsed || srvRect.Mount == nil || srvRect.Mount.FilesystemType != "xfs" {
+		t.Fatalf("collapsed mount rect = %+v", srvRect)
+	}
+
+	rects, err = store.Layout(srv.ID, 400, 300, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rects) != 3 {
+		t.Fatalf("viewing a collapsed mount laid out %d rects, want its two children and itself", len(rects))
+	}
+}
+
+func TestTreeStoreExcludesMountSubtree(t *testing.T) {
+	store, root, srv := mountTestStore()
+	if got := volumeScannedSize(root); got != 200 {
+		t.Fatalf("volume scanned size = %d, want the 200 bytes outside the mount", got)
+	}
+
+	result, err := store.ExcludeMount(srv.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FileCount != 1 || result.DirCount != 2 || root.EntryFiles != 1 || root.EntryDirs != 2 {
+		t.Fatalf("counts after exclusion = result %+v, root entries (%d, %d)", result, root.EntryFiles, root.EntryDirs)
+	}
+	if root.Size != 200 || len(root.Children) != 1 || volumeScannedSize(root) != 200 {
+		t.Fatalf("root after exclusion = %d bytes with %d children", root.Size, len(root.Children))
+	}
+	if _, err := store.NodePath(4); err == nil {
+		t.Fatal("an excluded mount's descendant is still addressable")
+	}
+	if _, err := store.ExcludeMount(srv.ID); err == nil {
+		t.Fatal("an excluded mount was excluded twice")
+	}
+}
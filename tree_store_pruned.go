@@ -0,0 +1,107 @@
+package main
+
+import "fmt"
+
+const (
+	defaultPrunedDepth    = 6
+	defaultPrunedChildren = 50
+	maximumPrunedDepth    = 64
+	maximumPrunedChildren = 1000
+	// maximumPrunedNodes bounds the whole pruned tree, so a wide tree stays a
+	// file that opens quickly.
+	maximumPrunedNodes = 20000
+)
+
+// PrunedNode is a compact copy of part of the scanned tree. Kind marks the
+// free space, unaccounted space, small-file and grouped placeholders;
+// Grouped counts the items merged into a grouped placeholder.
+type PrunedNode struct {
+	Name     string        `json:"n"`
+	Path     string        `json:"p,omitempty"`
+	Size     int64         `json:"s"`
+	IsFolder bool          `json:"d,omitempty"`
+	Kind     string        `json:"k,omitempty"`
+	Grouped  int           `json:"g,omitempty"`
+	ModTime  int64         `json:"m,omitempty"`
+	Children []*PrunedNode `json:"c,omitempty"`
+}
+
+// PrunedTree copies the subtree at nodeID down to maxDepth levels, keeping
+// the maxChildren largest children of every folder and merging the rest into
+// one placeholder. Folders are expanded breadth first until the tree holds
+// maximumPrunedNodes nodes; deeper folders keep their size but no children.
+func (s *TreeStore) PrunedTree(nodeID, maxDepth, maxChildren int, showFreeSpace bool) (*PrunedNode, error) {
+	if maxDepth < 1 || maxDepth > maximumPrunedDepth {
+		return nil, fmt.Errorf("depth must be between 1 and %d", maximumPrunedDepth)
+	}
+	if maxChildren < 1 || maxChildren > maximumPrunedChildren {
+		return nil, fmt.Errorf("items per folder must be between 1 and %d", maximumPrunedChildren)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if nodeID < 0 || nodeID >= len(s.nodes) || s.nodes[nodeID] == nil {
+		return nil, fmt.Errorf("selected item is no longer available")
+	}
+
+	type pending struct {
+		source *Node
+		copy   *PrunedNode
+		depth  int
+	}
+	root := prunedCopy(s.nodes[nodeID])
+	queue := []pending{{source: s.nodes[nodeID], copy: root}}
+	budget := maximumPrunedNodes - 1
+	for len(queue) > 0 && budget > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.depth >= maxDepth || (current.source.MountCollapsed && current.depth > 0) {
+			continue
+		}
+		var others *PrunedNode
+		for _, child := range current.source.Children {
+			if child == nil || child.Size <= 0 || (!showFreeSpace && child.isVolumeContext()) {
+				continue
+			}
+			// Children are sorted by size, so everything after the first
+			// maxChildren, or after the budget runs out, is grouped.
+			if len(current.copy.Children) >= maxChildren || budget <= 1 {
+				if others == nil {
+					others = &PrunedNode{Kind: "grouped"}
+				}
+				others.Size += child.Size
+				others.Grouped++
+				continue
+			}
+			copied := prunedCopy(child)
+			current.copy.Children = append(current.copy.Children, copied)
+			budget--
+			if child.IsFolder && len(child.Children) > 0 {
+				queue = append(queue, pending{source: child, copy: copied, depth: current.depth + 1})
+			}
+		}
+		if others != nil {
+			others.Name = fmt.Sprintf("%d more items", others.Grouped)
+			if others.Grouped == 1 {
+				others.Name = "1 more item"
+			}
+			current.copy.Children = append(current.copy.Children, others)
+			budget--
+		}
+	}
+	return root, nil
+}
+
+func prunedCopy(node *Node) *PrunedNode {
+	copied := &PrunedNode{Name: node.Name, Path: node.FullPath, Size: node.Size, IsFolder: node.IsFolder, ModTime: node.ModTime}
+	switch {
+	case node.IsFreeSpace:
+		copied.Kind = "free"
+	case node.IsUnaccounted:
+		copied.Kind = "unaccounted"
+	case node.IsSmallFiles:
+		copied.Kind = "small"
+		copied.Grouped = int(node.SmallFileCount)
+	}
+	return copied
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// InsertSubtree adds a freshly scanned item at path, such as an item restored
+// from Trash, without rescanning its parent folder. A small file arrives as a
+// one-file [Small Files] aggregate and is merged into the parent's aggregate.
+// Nothing changes when path lies outside the scanned tree, and RescanRequired
+// is set when its parent folder is not part of the tree.
+func (s *TreeStore) InsertSubtree(path string, scanned *Node, scannedFiles, scannedDirs int) (DeleteResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scanned == nil {
+		return DeleteResult{}, fmt.Errorf("the restored item was not scanned")
+	}
+	result := DeleteResult{FileCount: s.fileCount, DirCount: s.dirCount}
+	if s.root == nil || s.root.FullPath == "" || path == s.root.FullPath || !pathWithin(path, s.root.FullPath) {
+		return result, nil
+	}
+	if s.nodeByPath(path) != nil {
+		return DeleteResult{}, fmt.Errorf("%s is already part of the scanned tree", path)
+	}
+	parent := s.nodeByPath(filepath.Dir(path))
+	if parent == nil || !parent.IsFolder {
+		result.RescanRequired = true
+		return result, nil
+	}
+
+	if scanned.IsSmallFiles {
+		mergeSmallFiles(parent, scanned)
+		for _, entry := range scanned.SmallFiles {
+			result.RescanRequired = result.RescanRequired || entry.LinkCount > 1
+		}
+	} else {
+		parent.Children = append(parent.Children, s.adoptSubtrees([]*Node{scanned}, parent.ID, parent.Depth+1)...)
+		result.RescanRequired = subtreeHasSharedAllocation(scanned)
+	}
+	s.adjustAncestorSizes(parent, scanned.Size)
+	s.adjustAncestorEntryCounts(parent, scannedFiles, scannedDirs)
+	s.fileCount += scannedFiles
+	s.dirCount += scannedDirs
+	result.FileCount = s.fileCount
+	result.DirCount = s.dirCount
+	return result, nil
+}
+
+// mergeSmallFiles adds the files of the aggregate scanned to parent's
+// [Small Files] aggregate. Per-file details are kept only while they still
+// describe every aggregated file.
+func mergeSmallFiles(parent, scanned *Node) {
+	for _, child := range parent.Children {
+		if !child.IsSmallFiles {
+			continue
+		}
+		complete := len(child.SmallFiles) == int(child.SmallFileCount) && len(scanned.SmallFiles) == int(scanned.SmallFileCount)
+		child.Size += scanned.Size
+		child.ApparentSize += scanned.ApparentSize
+		child.SmallFileCount += scanned.SmallFileCount
+		if complete {
+			child.SmallFiles = append(child.SmallFiles, scanned.SmallFiles...)
+		} else {
+			child.SmallFiles = nil
+		}
+		return
+	}
+	aggregate := *scanned
+	aggregate.ID = -1
+	aggregate.ParentID = parent.ID
+	aggregate.Depth = parent.Depth + 1
+	aggregate.FullPath = ""
+	parent.Children = append(parent.Children, &aggregate)
+}
+
+// DisplayedTrash lists the Trash roots shown in the current tree so they can be
+// refreshed after items leave them.
+func (s *TreeStore) DisplayedTrash(isTrashRoot func(string) bool) []trashRefreshTarget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return displayedTrashNodes(s.root, nil, isTrashRoot)
+}
+
+// DisplayedFolder returns path as a refresh target when it is a folder shown
+// in the current tree, for staging folders that are not named like a Trash.
+func (s *TreeStore) DisplayedFolder(path string) []trashRefreshTarget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node := s.nodeByPath(path)
+	if node == nil || node == s.root || !node.IsFolder {
+		return nil
+	}
+	return []trashRefreshTarget{{NodeID: node.ID, Path: node.FullPath}}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTreeStoreInsertSubtreeAddsRestoredItems(t *testing.T) {
+	store, nodes := batchTestStore(t)
+	root, vms := nodes["root"], nodes["vms"]
+	base := root.FullPath
+
+	restored := &Node{Name: "iso", FullPath: filepath.Join(base, "vms", "iso"), IsFolder: true, Size: 700, EntryDirs: 1, EntryFiles: 1}
+	restored.Children = []*Node{{Name: "disk.iso", FullPath: filepath.Join(base, "vms", "iso", "disk.iso"), Size: 700}}
+	result, err := store.InsertSubtree(restored.FullPath, restored, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RescanRequired || result.FileCount != 5 || result.DirCount != 4 {
+		t.Fatalf("insert result = %+v", result)
+	}
+	if vms.Size != 1600 || root.Size != 1700 || vms.Children[0].Name != "iso" || vms.EntryFiles != 4 || vms.EntryDirs != 2 {
+		t.Fatalf("vms after insert = %d bytes, first child %q, entries (%d, %d)", vms.Size, vms.Children[0].Name, vms.EntryFiles, vms.EntryDirs)
+	}
+	inserted := store.nodeByPath(filepath.Join(base, "vms", "iso", "disk.iso"))
+	if inserted == nil || inserted.ID < 0 || store.nodes[inserted.ID] != inserted || inserted.ParentID != vms.Children[0].ID {
+		t.Fatal("the restored subtree was not given addressable node IDs")
+	}
+	if _, err := store.InsertSubtree(restored.FullPath, restored, 1, 1); err == nil {
+		t.Fatal("an item already in the tree was inserted again")
+	}
+
+	small := func() *Node {
+		return &Node{Name: "[Small Files]", Size: 10, IsSmallFiles: true, SmallFileCount: 1, SmallFiles: []SmallFileEntry{{Name: "a.txt", Size: 10}}}
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := store.InsertSubtree(filepath.Join(base, name), small(), 1, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var aggregate *Node
+	for _, child := range root.Children {
+		if child.IsSmallFiles {
+			aggregate = child
+		}
+	}
+	if aggregate == nil || aggregate.SmallFileCount != 2 || aggregate.Size != 20 || len(aggregate.SmallFiles) != 2 || aggregate.ParentID != root.ID {
+		t.Fatalf("small files aggregate after restore = %+v", aggregate)
+	}
+
+	outside, err := store.InsertSubtree(filepath.Join(filepath.Dir(base), "elsewhere"), small(), 1, 0)
+	if err != nil || outside.RescanRequired || outside.FileCount != 7 {
+		t.Fatalf("insert outside the tree = %+v, %v", outside, err)
+	}
+	missingParent, err := store.InsertSubtree(filepath.Join(base, "gone", "file"), small(), 1, 0)
+	if err != nil || !missingParent.RescanRequired {
+		t.Fatalf("insert below a folder missing from the tree = %+v, %v", missingParent, err)
+	}
+}
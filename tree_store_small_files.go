@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// SmallFileInfo describes one file folded into a folder's [Small Files]
+// aggregate.
+type SmallFileInfo struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"mtime"`
+	LinkCount uint64 `json:"linkCount,omitempty"`
+}
+
+func smallFilesAggregate(folder *Node) *Node {
+	for _, child := range folder.Children {
+		if child.IsSmallFiles {
+			return child
+		}
+	}
+	return nil
+}
+
+func (s *TreeStore) smallFilesFolder(folderID int) (*Node, *Node, error) {
+	if folderID < 0 || folderID >= len(s.nodes) || s.nodes[folderID] == nil {
+		return nil, nil, fmt.Errorf("selected folder is no longer available")
+	}
+	folder := s.nodes[folderID]
+	aggregate := smallFilesAggregate(folder)
+	if !folder.IsFolder || folder.FullPath == "" || aggregate == nil {
+		return nil, nil, fmt.Errorf("the selected folder has no small files")
+	}
+	if len(aggregate.SmallFiles) == 0 {
+		return nil, nil, fmt.Errorf("small-file details were not recorded; enable Keep small-file details in Settings and rescan")
+	}
+	return folder, aggregate, nil
+}
+
+// SmallFiles expands the [Small Files] aggregate of folderID, largest first.
+func (s *TreeStore) SmallFiles(folderID int) ([]SmallFileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	folder, aggregate, err := s.smallFilesFolder(folderID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SmallFileInfo, len(aggregate.SmallFiles))
+	for index, entry := range aggregate.SmallFiles {
+		result[index] = SmallFileInfo{
+			Name:      entry.Name,
+			Path:      filepath.Join(folder.FullPath, entry.Name),
+			Size:      entry.Size,
+			ModTime:   entry.ModTime,
+			LinkCount: entry.LinkCount,
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// DeleteSmallFile moves one aggregated small file to Trash and removes its
+// record, size and file count from the aggregate and its ancestors.
+func (s *TreeStore) DeleteSmallFile(folderID int, name string, isTrashRoot, isInTrash func(string) bool, moveToTrash func(string) error) (DeleteResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder, aggregate, err := s.smallFilesFolder(folderID)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	if name == "" || filepath.Base(name) != name {
+		return DeleteResult{}, fmt.Errorf("invalid small file name %q", name)
+	}
+	index := -1
+	for candidate, entry := range aggregate.SmallFiles {
+		if entry.Name == name {
+			index = candidate
+			break
+		}
+	}
+	if index < 0 {
+		return DeleteResult{}, fmt.Errorf("selected small file is no longer available")
+	}
+	entry := aggregate.SmallFiles[index]
+	path := filepath.Join(folder.FullPath, entry.Name)
+	if s.protection.protects(path) {
+		return DeleteResult{}, errProtectedPath
+	}
+	if isInTrash != nil && isInTrash(path) {
+		return DeleteResult{}, fmt.Errorf("items inside Trash cannot be deleted; restore them using the system Trash")
+	}
+	if err := movePathToTrash(path, moveToTrash); err != nil {
+		return DeleteResult{}, err
+	}
+	trashRefreshes := displayedTrashNodes(s.root, nil, isTrashRoot)
+
+	aggregate.SmallFiles = append(aggregate.SmallFiles[:index], aggregate.SmallFiles[index+1:]...)
+	aggregate.SmallFileCount = max(0, aggregate.SmallFileCount-1)
+	aggregate.Size = max(0, aggregate.Size-entry.Size)
+	aggregate.ApparentSize = max(0, aggregate.ApparentSize-entry.ApparentSize)
+	if aggregate.SmallFileCount == 0 {
+		removeChild(folder, aggregate)
+	}
+	s.adjustAncestorSizes(folder, -entry.Size)
+	s.adjustAncestorEntryCounts(folder, -1, 0)
+	s.fileCount = max(0, s.fileCount-1)
+	return DeleteResult{
+		FileCount:      s.fileCount,
+		DirCount:       s.dirCount,
+		RescanRequired: entry.LinkCount > 1,
+		trashRefreshes: trashRefreshes,
+	}, nil
+}
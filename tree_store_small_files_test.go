@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func scanSmallFilesStore(t *testing.T, rootPath string, keepDetails bool) (*TreeStore, *Node) {
+	t.Helper()
+	profile := defaultProfile()
+	profile.MinFileSize = 1024
+	profile.SkipNetworkFS = false
+	profile.KeepSmallFileDetails = keepDetails
+	scanner := NewScanner(profile, 1)
+	var files, dirs int64
+	root, err := scanner.buildTree(rootPath, 0, -1, &files, &dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &TreeStore{}
+	store.Replace(root, scanner.Nodes(), int(files), int(dirs))
+	return store, root
+}
+
+func TestTreeStoreExpandsAndDeletesRecordedSmallFiles(t *testing.T) {
+	rootPath := t.TempDir()
+	writeFixtureFiles(t, rootPath, map[string]int{"a.txt": 100, "b.txt": 300, "large.bin": 4096})
+	store, root := scanSmallFilesStore(t, rootPath, true)
+	aggregate := smallFilesAggregate(root)
+	if aggregate == nil || len(aggregate.SmallFiles) != 2 {
+		t.Fatalf("small-files aggregate = %+v, want two recorded entries", aggregate)
+	}
+
+	entries, err := store.SmallFiles(root.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Size < entries[1].Size || entries[0].Path != filepath.Join(rootPath, entries[0].Name) || entries[0].ModTime == 0 {
+		t.Fatalf("expanded small files = %+v", entries)
+	}
+
+	rootSize, aggregateSize := root.Size, aggregate.Size
+	var trashed string
+	result, err := store.DeleteSmallFile(root.ID, entries[0].Name, nil, nil, func(path string) error {
+		trashed = path
+		return os.Remove(path)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trashed != entries[0].Path {
+		t.Fatalf("moveToTrash called with %q, want %q", trashed, entries[0].Path)
+	}
+	if result.FileCount != 2 || aggregate.SmallFileCount != 1 || root.EntryFiles != 2 {
+		t.Fatalf("counts after delete = (%d, %d, %d), want (2, 1, 2)", result.FileCount, aggregate.SmallFileCount, root.EntryFiles)
+	}
+	if aggregate.Size != aggregateSize-entries[0].Size || root.Size != rootSize-entries[0].Size {
+		t.Fatalf("sizes after delete = (%d, %d), want (%d, %d)", aggregate.Size, root.Size, aggregateSize-entries[0].Size, rootSize-entries[0].Size)
+	}
+
+	if _, err := store.DeleteSmallFile(root.ID, "../large.bin", nil, nil, func(string) error { return nil }); err == nil {
+		t.Fatal("a path outside the aggregate was accepted")
+	}
+	if _, err := store.DeleteSmallFile(root.ID, entries[1].Name, nil, nil, func(path string) error { return os.Remove(path) }); err != nil {
+		t.Fatal(err)
+	}
+	if smallFilesAggregate(root) != nil {
+		t.Fatal("the empty small-files aggregate was not removed")
+	}
+}
+
+func TestTreeStoreSmallFilesRequireRecordedDetails(t *testing.T) {
+	rootPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rootPath, "tiny.txt"), make([]byte, 10), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, root := scanSmallFilesStore(t, rootPath, false)
+	if aggregate := smallFilesAggregate(root); aggregate == nil || aggregate.SmallFiles != nil {
+		t.Fatalf("small-files aggregate = %+v, want no recorded entries", aggregate)
+	}
+	if _, err := store.SmallFiles(root.ID); err == nil || !strings.Contains(err.Error(), "rescan") {
+		t.Fatalf("SmallFiles() error = %v, want a rescan hint", err)
+	}
+}
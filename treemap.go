@@ -3,6 +3,8 @@ package main
 
 import (
 	"math"
+
+	"spacebrowser/internal/platform"
 )
 
 // Treemap rendering constants
@@ -32,21 +34,39 @@ type Rect struct {
 	ParentID *int  `json:"parent_id,omitempty"`
 	Children []int `json:"children,omitempty"` // indices into THIS rects array
 
-	FullPath       string `json:"full_path"`
-	Name           string `json:"name"`
-	Size           int64  `json:"size"`
-	IsFolder       bool   `json:"is_folder"`
-	IsTrashRoot    bool   `json:"is_trash_root,omitempty"`
-	IsInTrash      bool   `json:"is_in_trash,omitempty"`
-	IsFree         bool   `json:"is_free_space"`
-	IsSmallFiles   bool   `json:"is_small_files"`
-	SmallFileCount int64  `json:"small_file_count,omitempty"`
-	SmallFileLimit int64  `json:"small_file_limit,omitempty"`
-	Depth          int    `json:"depth"`
+	FullPath         string `json:"full_path"`
+	Name             string `json:"name"`
+	Size             int64  `json:"size"`
+	IsFolder         bool   `json:"is_folder"`
+	IsTrashRoot      bool   `json:"is_trash_root,omitempty"`
+	IsInTrash        bool   `json:"is_in_trash,omitempty"`
+	IsProtected      bool   `json:"is_protected,omitempty"`
+	IsFree           bool   `json:"is_free_space"`
+	IsSmallFiles     bool   `json:"is_small_files"`
+	SmallFileCount   int64  `json:"small_file_count,omitempty"`
+	SmallFileLimit   int64  `json:"small_file_limit,omitempty"`
+	SmallFileDetails bool   `json:"small_file_details,omitempty"`
+	IsUnaccounted    bool   `json:"is_unaccounted,omitempty"`
+	Depth            int    `json:"depth"`
 
 	// on root rect when scanning a mount
-	DiskTotal int64 `json:"disk_total,omitempty"`
-	DiskFree  int64 `json:"disk_free,omitempty"`
+	DiskTotal int64  `json:"disk_total,omitempty"`
+	DiskFree  int64  `json:"disk_free,omitempty"`
+	DiskPath  string `json:"disk_path,omitempty"`
+
+	// on [Unaccounted Space] rects
+	ReservedSpace  int64 `json:"reserved_space,omitempty"`
+	ScanErrorCount int64 `json:"scan_error_count,omitempty"`
+	SkippedCount   int64 `json:"skipped_count,omitempty"`
+
+	// on folders where another filesystem is mounted
+	Mount          *platform.MountInfo `json:"mount,omitempty"`
+	MountCollapsed bool                `json:"mount_collapsed,omitempty"`
+
+	// on Trash contents: the deletion date of the enclosing top-level Trash
+	// item, and on that item the location it was deleted from
+	TrashOriginalPath string `json:"trash_original_path,omitempty"`
+	TrashDeletedAt    int64  `json:"trash_deleted_at,omitempty"`
 
 	// on leaf rects
 	MTime int64 `json:"mtime"`
@@ -77,8 +97,9 @@ func ComputeTreemapRects(root *Node, W, H, scale float64) []Rect {
 
 		parentRectIdx := f.rect
 
-		// If this node has no visible inner area or no children, continue
-		if !f.n.IsFolder || len(f.n.Children) == 0 {
+		// If this node has no visible inner area or no children, continue.
+		// Collapsed mounts are drawn as a single block unless they are the view root.
+		if !f.n.IsFolder || len(f.n.Children) == 0 || (f.n.MountCollapsed && f.n != root) {
 			continue
 		}
 
@@ -130,6 +151,13 @@ func ComputeTreemapRects(root *Node, W, H, scale float64) []Rect {
 	return out
 }
 
+// isVolumeContext reports whether n only describes the volume around the
+// scanned tree: its free space, or the used rest of the volume around a
+// folder scan.
+func (n *Node) isVolumeContext() bool {
+	return n.IsFreeSpace || (n.IsUnaccounted && n.DiskPath != "")
+}
+
 // emitRect appends a Rect to 'out' using drawing-space rounding and returns its index.
 func emitRect(out *[]Rect, n *Node, x, y, w, h float64) int {
 	// Snap to integer pixels for crisp rendering
@@ -154,18 +182,28 @@ func emitRect(out *[]Rect, n *Node, x, y, w, h float64) int {
 		ParentID: parentPtr,
 		Children: nil,
 
-		FullPath:       n.FullPath,
-		Name:           n.Name,
-		Size:           n.Size,
-		IsFolder:       n.IsFolder,
-		IsFree:         n.IsFreeSpace,
-		IsSmallFiles:   n.IsSmallFiles,
-		SmallFileCount: n.SmallFileCount,
-		SmallFileLimit: n.SmallFileLimit,
-		Depth:          n.Depth,
+		FullPath:         n.FullPath,
+		Name:             n.Name,
+		Size:             n.Size,
+		IsFolder:         n.IsFolder,
+		IsFree:           n.IsFreeSpace,
+		IsSmallFiles:     n.IsSmallFiles,
+		SmallFileCount:   n.SmallFileCount,
+		SmallFileLimit:   n.SmallFileLimit,
+		SmallFileDetails: len(n.SmallFiles) > 0,
+		IsUnaccounted:    n.IsUnaccounted,
+		Depth:            n.Depth,
 
 		DiskTotal: n.DiskTotal,
 		DiskFree:  n.DiskFree,
+		DiskPath:  n.DiskPath,
+
+		ReservedSpace:  n.ReservedSpace,
+		ScanErrorCount: n.ScanErrorCount,
+		SkippedCount:   n.SkippedCount,
+
+		Mount:          n.Mount,
+		MountCollapsed: n.MountCollapsed,
 
 		MTime: n.ModTime,
 	})
@@ -299,17 +337,17 @@ func layoutRow(nodes []*Node, areas []float64, x, y, w, h float64, depth int, pa
 	}
 	thickness := total / length
 
-	// Keep free space at the outer end of its row. This preserves every area and
-	// aspect ratio while ensuring the free-space tile touches the right edge of a
-	// horizontal row, or the bottom edge of a vertical column.
+	// Keep free space and the rest of the volume at the outer end of its row.
+	// This preserves every area and aspect ratio while ensuring these tiles touch
+	// the right edge of a horizontal row, or the bottom edge of a vertical column.
 	order := make([]int, 0, len(nodes))
 	for i, n := range nodes {
-		if !n.IsFreeSpace {
+		if !n.isVolumeContext() {
 			order = append(order, i)
 		}
 	}
 	for i, n := range nodes {
-		if n.IsFreeSpace {
+		if n.isVolumeContext() {
 			order = append(order, i)
 		}
 	}
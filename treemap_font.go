@@ -0,0 +1,40 @@
+package main
+
+import (
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// labelFont is Go Regular, a sans-serif vector font that covers Latin, Greek
+// and Cyrillic. PNG exports draw labels with it, as the SVG's sans-serif
+// family does in the viewer; characters it lacks are drawn as boxes.
+var labelFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// labelFaces caches faces of one font by pixel size.
+type labelFaces struct {
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+func (l *labelFaces) face(fontSize float64) font.Face {
+	face, ok := l.faces[fontSize]
+	if !ok {
+		// NewFace only records the options and never fails.
+		face, _ = opentype.NewFace(l.font, &opentype.FaceOptions{Size: fontSize, DPI: 72, Hinting: font.HintingFull})
+		if l.faces == nil {
+			l.faces = make(map[float64]font.Face)
+		}
+		l.faces[fontSize] = face
+	}
+	return face
+}
+
+func fixedToFloat(value fixed.Int26_6) float64 {
+	return float64(value) / 64
+}
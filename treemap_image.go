@@ -0,0 +1,588 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	treemapImagePNG = "png"
+	treemapImageSVG = "svg"
+
+	// maximumTreemapImageSide bounds each dimension of an exported image and
+	// maximumTreemapImagePixels their product, so a typo cannot allocate more
+	// than 256 MiB for a PNG canvas.
+	maximumTreemapImageSide   = 16384
+	maximumTreemapImagePixels = 64 << 20
+	treemapFontSize           = 10.0 // matches FONT_SIZE in web/state.js
+)
+
+// treemapImageFormat picks the image format from the extension of path.
+func treemapImageFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return treemapImagePNG, nil
+	case ".svg":
+		return treemapImageSVG, nil
+	}
+	return "", fmt.Errorf("treemap images must be .png or .svg files, not %q", filepath.Base(path))
+}
+
+func validTreemapImageSize(width, height int) error {
+	if width < 1 || height < 1 || width > maximumTreemapImageSide || height > maximumTreemapImageSide {
+		return fmt.Errorf("treemap images must be between 1 and %d pixels on each side", maximumTreemapImageSide)
+	}
+	if width*height > maximumTreemapImagePixels {
+		return fmt.Errorf("treemap images must not exceed %d megapixels", maximumTreemapImagePixels>>20)
+	}
+	return nil
+}
+
+// treemapImageStyle is the part of the appearance settings, plus the scan
+// totals shown on free space, that the window draws rectangles with.
+type treemapImageStyle struct {
+	Colors          []string
+	CornerRadius    float64
+	ReliefStrength  float64
+	ColorTrashByAge bool
+	Scale           float64
+	FileCount       int
+	DirCount        int
+	Now             time.Time
+}
+
+// treemapCanvas is the drawing surface renderTreemap paints on. Coordinates
+// are in image pixels; text is placed by its baseline.
+type treemapCanvas interface {
+	fill(x, y, w, h, radius float64, c color.RGBA)
+	// stroke paints a band of the given width just inside the rectangle edge.
+	stroke(x, y, w, h, radius, width float64, c color.RGBA)
+	text(x, baseline float64, text string, fontSize float64, c color.RGBA)
+	textWidth(text string, fontSize float64) float64
+	// fontBounds returns the ascent and total height of a line of text.
+	fontBounds(fontSize float64) (ascent, height float64)
+}
+
+// writeTreemapImage renders rects, laid out for a width×height view, as a
+// PNG or SVG image.
+func writeTreemapImage(w io.Writer, format string, rects []Rect, width, height int, style treemapImageStyle) error {
+	if err := validTreemapImageSize(width, height); err != nil {
+		return err
+	}
+	switch format {
+	case treemapImagePNG:
+		labels, err := labelFont()
+		if err != nil {
+			return fmt.Errorf("load label font: %w", err)
+		}
+		canvas := &pngCanvas{image: image.NewRGBA(image.Rect(0, 0, width, height)), labels: labelFaces{font: labels}}
+		draw.Draw(canvas.image, canvas.image.Bounds(), image.White, image.Point{}, draw.Src)
+		renderTreemap(canvas, rects, style)
+		if err := png.Encode(w, canvas.image); err != nil {
+			return fmt.Errorf("encode PNG: %w", err)
+		}
+		return nil
+	case treemapImageSVG:
+		canvas := &svgCanvas{}
+		fmt.Fprintf(&canvas.buffer, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\">\n", width, height, width, height)
+		renderTreemap(canvas, rects, style)
+		canvas.buffer.WriteString("</svg>\n")
+		if _, err := w.Write(canvas.buffer.Bytes()); err != nil {
+			return fmt.Errorf("write SVG: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported treemap image format %q", format)
+}
+
+// renderTreemap paints rects the way web/treemap-view.js draws them, without
+// selection and hover highlights. Parents precede their children in rects, so
+// painting in order nests them correctly.
+func renderTreemap(canvas treemapCanvas, rects []Rect, style treemapImageStyle) {
+	scale := style.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	pxI := func(base float64) float64 { return max(1, math.Round(base*scale)) }
+	strokeWidth := max(0.5, scale)
+	radius := style.CornerRadius * scale
+	colors := style.Colors
+	if len(colors) == 0 {
+		colors = builtinPalettes[0].Colors
+	}
+	pad, fontSize := pxI(4), pxI(treemapFontSize)
+	ascent, textHeight := canvas.fontBounds(fontSize)
+	lines := textLines{canvas: canvas, fontSize: fontSize, ascent: ascent, textHeight: textHeight, lineHeight: textHeight + pxI(2)}
+	black := color.RGBA{A: 0xff}
+
+	for _, rect := range rects {
+		if rect.W <= 0 || rect.H <= 0 {
+			continue
+		}
+		isRoot := rect.ParentID == nil
+		var fill color.RGBA
+		switch {
+		case rect.IsFree || isRoot:
+			fill = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		case rect.IsSmallFiles:
+			fill = color.RGBA{0xe6, 0xda, 0xc5, 0xff}
+		case rect.IsUnaccounted:
+			fill = color.RGBA{0xd6, 0xd6, 0xd6, 0xff}
+		case style.ColorTrashByAge && rect.TrashDeletedAt != 0:
+			fill = trashAgeColor(rect.TrashDeletedAt, style.Now)
+		default:
+			fill = parseHexColor(colors[rect.Depth%len(colors)])
+		}
+		canvas.fill(rect.X, rect.Y, rect.W, rect.H, radius, fill)
+		canvas.stroke(rect.X, rect.Y, rect.W, rect.H, radius, strokeWidth, color.RGBA{0x22, 0x22, 0x22, 0xff})
+		paintRelief(canvas, rect, fill, strokeWidth, scale, style.ReliefStrength)
+
+		if rect.W < pxI(40) || rect.H < pxI(treemapFontSize+4) {
+			continue
+		}
+		size := formatDisplaySize(rect.Size, -1)
+		switch {
+		case rect.IsFree:
+			percent := 0.0
+			if rect.DiskTotal > 0 {
+				percent = 100 * float64(rect.Size) / float64(rect.DiskTotal)
+			}
+			lines.centered(rect, []textLine{
+				{text: fmt.Sprintf("Free Space: %.1f%%", percent)},
+				{text: formatDisplaySize(rect.Size, 1) + " Free"},
+				{text: "Files: " + formatCount(style.FileCount)},
+				{text: "Folders: " + formatCount(style.DirCount)},
+			}, black)
+		case rect.IsSmallFiles:
+			lines.centered(rect, []textLine{
+				{text: fmt.Sprintf("%s <%s files", formatCount(int(rect.SmallFileCount)), formatCompactSize(rect.SmallFileLimit))},
+				{text: size},
+			}, black)
+		case rect.IsUnaccounted:
+			label := "Unaccounted"
+			if rect.DiskPath != "" {
+				label = "Rest of volume"
+			}
+			lines.centered(rect, []textLine{{text: label}, {text: size}}, black)
+		case rect.IsFolder:
+			if rect.W <= pxI(60) || rect.H <= pxI(15) {
+				continue
+			}
+			title := rect.Name
+			if rect.Mount != nil {
+				fsType := rect.Mount.FilesystemType
+				if fsType == "" {
+					fsType = "mount"
+				}
+				if rect.MountCollapsed {
+					fsType += ", collapsed"
+				}
+				title += " [" + fsType + "]"
+			}
+			title += " (" + size + ")"
+			if isRoot && rect.DiskTotal > 0 {
+				used := formatDisplaySize(max(0, rect.DiskTotal-rect.DiskFree), -1)
+				if rect.DiskPath != "" {
+					title = fmt.Sprintf("%s (%s; volume %s / %s)", rect.Name, size, used, formatDisplaySize(rect.DiskTotal, -1))
+				} else {
+					title = fmt.Sprintf("%s (%s / %s)", rect.Name, used, formatDisplaySize(rect.DiskTotal, -1))
+				}
+			}
+			if label := lines.ellipsize(title, rect.W-2*pad); label != "" {
+				canvas.text(math.Round(rect.X+pad), math.Round(rect.Y+pad+ascent), label, fontSize, black)
+			}
+		default:
+			modified := ""
+			if rect.MTime != 0 {
+				modified = time.Unix(rect.MTime, 0).Format("2006-01-02")
+			}
+			lines.centered(rect, []textLine{{text: rect.Name, ellipsize: true}, {text: size}, {text: modified}}, black)
+		}
+	}
+}
+
+// paintRelief draws the light top-left and dark bottom-right bands just
+// inside the border.
+func paintRelief(canvas treemapCanvas, rect Rect, fill color.RGBA, strokeWidth, scale, strength float64) {
+	amount := min(1, math.Abs(strength))
+	if amount == 0 {
+		return
+	}
+	inset, width := strokeWidth, scale
+	x, y := rect.X+inset, rect.Y+inset
+	w, h := rect.W-2*inset, rect.H-2*inset
+	if w <= 2*width || h <= 2*width {
+		return
+	}
+	light, dark := blendColor(fill, 0xff, amount), blendColor(fill, 0, amount)
+	canvas.fill(x, y, width, h, 0, light)
+	canvas.fill(x, y, w, width, 0, light)
+	canvas.fill(x+w-width, y, width, h, 0, dark)
+	canvas.fill(x, y+h-width, w, width, 0, dark)
+}
+
+type textLine struct {
+	text      string
+	ellipsize bool
+}
+
+// textLines lays out label text like writeCenteredLinesInRect in the window:
+// lines that do not fit are dropped unless they may be shortened.
+type textLines struct {
+	canvas     treemapCanvas
+	fontSize   float64
+	ascent     float64
+	textHeight float64
+	lineHeight float64
+}
+
+func (l textLines) ellipsize(text string, maxWidth float64) string {
+	if text == "" || l.canvas.textWidth(text, l.fontSize) <= maxWidth {
+		return text
+	}
+	const ellipsis = "…"
+	if l.canvas.textWidth(ellipsis, l.fontSize) > maxWidth {
+		return ""
+	}
+	runes := []rune(text)
+	low, high := 0, len(runes)
+	for low < high {
+		middle := (low + high) / 2
+		if l.canvas.textWidth(string(runes[:middle])+ellipsis, l.fontSize) <= maxWidth {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	if low-1 <= 0 {
+		return ""
+	}
+	return string(runes[:low-1]) + ellipsis
+}
+
+func (l textLines) centered(rect Rect, lines []textLine, c color.RGBA) {
+	maxWidth, maxHeight := rect.W-2, rect.H-2
+	if maxWidth <= 0 || maxHeight <= 0 {
+		return
+	}
+	maxLines := int(math.Floor((maxHeight + l.lineHeight - l.textHeight) / l.lineHeight))
+	fitting := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(fitting) >= maxLines {
+			break
+		}
+		if line.ellipsize {
+			if text := l.ellipsize(line.text, maxWidth); text != "" {
+				fitting = append(fitting, text)
+			}
+		} else if l.canvas.textWidth(line.text, l.fontSize) <= maxWidth {
+			fitting = append(fitting, line.text)
+		}
+	}
+	blockHeight := float64(len(fitting))*l.lineHeight - (l.lineHeight - l.textHeight)
+	baseline := rect.Y + (rect.H-blockHeight)/2 + l.ascent
+	for index, text := range fitting {
+		if text == "" {
+			continue
+		}
+		x := math.Round(rect.X + (rect.W-l.canvas.textWidth(text, l.fontSize))/2)
+		l.canvas.text(x, math.Round(baseline+float64(index)*l.lineHeight), text, l.fontSize, c)
+	}
+}
+
+// formatDisplaySize matches formatSize in web/format.js so images carry the
+// same labels as the window. digits < 0 picks one decimal below 10.
+func formatDisplaySize(size int64, digits int) string {
+	if size <= 0 {
+		return "0 B"
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	index := min(len(units)-1, int(math.Floor(math.Log(float64(size))/math.Log(1024))))
+	value := float64(size) / math.Pow(1024, float64(index))
+	if digits < 0 {
+		digits = 0
+		if value < 10 {
+			digits = 1
+		}
+	}
+	return strconv.FormatFloat(value, 'f', digits, 64) + " " + units[index]
+}
+
+func formatCompactSize(size int64) string {
+	return strings.Replace(strings.Replace(formatDisplaySize(size, -1), ".0 ", " ", 1), " ", "", 1)
+}
+
+// formatCount groups digits in threes with spaces, like formatCount in
+// web/format.js.
+func formatCount(count int) string {
+	digits := strconv.Itoa(max(0, count))
+	var grouped strings.Builder
+	for index, digit := range digits {
+		if index > 0 && (len(digits)-index)%3 == 0 {
+			grouped.WriteByte(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	return grouped.String()
+}
+
+// Trash contents deleted today are green; the color moves through yellow to
+// red on a logarithmic scale and stops changing after a year, as in the window.
+var trashAgeColors = []color.RGBA{{0x9b, 0xe3, 0x9b, 0xff}, {0xf3, 0xd8, 0x6b, 0xff}, {0xe8, 0x84, 0x6b, 0xff}}
+
+const trashAgeMaximumDays = 365
+
+func trashAgeColor(deletedAt int64, now time.Time) color.RGBA {
+	days := max(0, now.Sub(time.Unix(deletedAt, 0)).Hours()/24)
+	position := min(1, math.Log1p(days)/math.Log1p(trashAgeMaximumDays)) * float64(len(trashAgeColors)-1)
+	index := min(len(trashAgeColors)-2, int(position))
+	amount := position - float64(index)
+	from, to := trashAgeColors[index], trashAgeColors[index+1]
+	mix := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*amount)) }
+	return color.RGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), 0xff}
+}
+
+func blendColor(c color.RGBA, target uint8, amount float64) color.RGBA {
+	mix := func(channel uint8) uint8 {
+		return uint8(math.Round(float64(channel) + (float64(target)-float64(channel))*amount))
+	}
+	return color.RGBA{mix(c.R), mix(c.G), mix(c.B), 0xff}
+}
+
+// parseHexColor reads a #rrggbb color; anything else is drawn gray.
+func parseHexColor(hex string) color.RGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.RGBA{0x99, 0x99, 0x99, 0xff}
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// pngCanvas paints shapes without anti-aliasing and draws text with the
+// embedded label font, so no font files or graphics stack are needed.
+type pngCanvas struct {
+	image  *image.RGBA
+	labels labelFaces
+}
+
+func (c *pngCanvas) fill(x, y, w, h, radius float64, fill color.RGBA) {
+	c.paint(x, y, w, h, radius, 0, fill)
+}
+
+func (c *pngCanvas) stroke(x, y, w, h, radius, width float64, stroke color.RGBA) {
+	c.paint(x, y, w, h, radius, max(1, math.Round(width)), stroke)
+}
+
+// paint fills a rounded rectangle, or only a band along its edge when band is
+// positive.
+func (c *pngCanvas) paint(x, y, w, h, radius, band float64, fill color.RGBA) {
+	bounds := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	area := bounds.Intersect(c.image.Bounds())
+	if area.Empty() {
+		return
+	}
+	if radius <= 0 && band <= 0 {
+		draw.Draw(c.image, area, image.NewUniform(fill), image.Point{}, draw.Src)
+		return
+	}
+	b := int(band)
+	inner := image.Rect(bounds.Min.X+b, bounds.Min.Y+b, bounds.Max.X-b, bounds.Max.Y-b)
+	corner := int(math.Ceil(radius)) + b
+	for py := area.Min.Y; py < area.Max.Y; py++ {
+		for px := area.Min.X; px < area.Max.X; px++ {
+			// Inside the band, rows away from the corners only need their ends.
+			if b > 0 && py >= bounds.Min.Y+corner && py < bounds.Max.Y-corner && px >= bounds.Min.X+corner && px < bounds.Max.X-corner {
+				px = bounds.Max.X - corner - 1
+				continue
+			}
+			if !insideRoundedRect(px, py, bounds, radius) {
+				continue
+			}
+			if b > 0 && insideRoundedRect(px, py, inner, radius-band) {
+				continue
+			}
+			c.image.SetRGBA(px, py, fill)
+		}
+	}
+}
+
+func insideRoundedRect(px, py int, rect image.Rectangle, radius float64) bool {
+	if !(image.Point{px, py}).In(rect) {
+		return false
+	}
+	r := min(radius, float64(rect.Dx())/2, float64(rect.Dy())/2)
+	if r <= 0 {
+		return true
+	}
+	cx, cy := float64(px)+0.5, float64(py)+0.5
+	dx := max(float64(rect.Min.X)+r-cx, cx-float64(rect.Max.X)+r, 0)
+	dy := max(float64(rect.Min.Y)+r-cy, cy-float64(rect.Max.Y)+r, 0)
+	return dx*dx+dy*dy <= r*r
+}
+
+func (c *pngCanvas) fontBounds(fontSize float64) (float64, float64) {
+	metrics := c.labels.face(fontSize).Metrics()
+	return fixedToFloat(metrics.Ascent), fixedToFloat(metrics.Ascent + metrics.Descent)
+}
+
+func (c *pngCanvas) textWidth(text string, fontSize float64) float64 {
+	return fixedToFloat(font.MeasureString(c.labels.face(fontSize), text))
+}
+
+func (c *pngCanvas) text(x, baseline float64, text string, fontSize float64, ink color.RGBA) {
+	drawer := font.Drawer{
+		Dst:  c.image,
+		Src:  image.NewUniform(ink),
+		Face: c.labels.face(fontSize),
+		Dot:  fixed.P(int(math.Round(x)), int(math.Round(baseline))),
+	}
+	drawer.DrawString(text)
+}
+
+// svgCanvas collects SVG elements; text keeps its real characters and is
+// measured with an average glyph width, since the viewer picks the font.
+type svgCanvas struct {
+	buffer bytes.Buffer
+}
+
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+func (c *svgCanvas) fill(x, y, w, h, radius float64, fill color.RGBA) {
+	fmt.Fprintf(&c.buffer, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"", svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h))
+	if radius > 0 {
+		fmt.Fprintf(&c.buffer, " rx=\"%s\"", svgNumber(radius))
+	}
+	fmt.Fprintf(&c.buffer, " fill=\"%s\"/>\n", hexColor(fill))
+}
+
+func (c *svgCanvas) stroke(x, y, w, h, radius, width float64, stroke color.RGBA) {
+	half := width / 2
+	fmt.Fprintf(&c.buffer, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"", svgNumber(x+half), svgNumber(y+half), svgNumber(w-width), svgNumber(h-width))
+	if radius > half {
+		fmt.Fprintf(&c.buffer, " rx=\"%s\"", svgNumber(radius-half))
+	}
+	fmt.Fprintf(&c.buffer, " fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>\n", hexColor(stroke), svgNumber(width))
+}
+
+func (c *svgCanvas) fontBounds(fontSize float64) (float64, float64) {
+	return math.Round(fontSize * 0.8), fontSize
+}
+
+func (c *svgCanvas) textWidth(text string, fontSize float64) float64 {
+	return 0.6 * fontSize * float64(utf8.RuneCountInString(text))
+}
+
+func (c *svgCanvas) text(x, baseline float64, text string, fontSize float64, ink color.RGBA) {
+	var escaped strings.Builder
+	for _, character := range text {
+		switch character {
+		case '&':
+			escaped.WriteString("&amp;")
+		case '<':
+			escaped.WriteString("&lt;")
+		case '>':
+			escaped.WriteString("&gt;")
+		default:
+			if character >= ' ' {
+				escaped.WriteRune(character)
+			}
+		}
+	}
+	fmt.Fprintf(&c.buffer, "<text x=\"%s\" y=\"%s\" font-size=\"%s\" fill=\"%s\">%s</text>\n", svgNumber(x), svgNumber(baseline), svgNumber(fontSize), hexColor(ink), escaped.String())
+}
+
+// treemapImageStyle returns the current appearance for rendering images with
+// labels sized by scale.
+func (a *App) treemapImageStyle(scale float64) treemapImageStyle {
+	a.settingsMu.RLock()
+	appearance := a.profile.Appearance
+	a.settingsMu.RUnlock()
+	files, dirs := a.store.Counts()
+	return treemapImageStyle{
+		Colors:          paletteColors(appearance),
+		CornerRadius:    float64(appearance.CornerRadius),
+		ReliefStrength:  appearance.ReliefStrength,
+		ColorTrashByAge: appearance.ColorTrashByAge,
+		Scale:           scale,
+		FileCount:       files,
+		DirCount:        dirs,
+		Now:             time.Now(),
+	}
+}
+
+// saveTreemapImage lays out nodeID at width×height and writes it to path in
+// the format its extension names.
+func (a *App) saveTreemapImage(path string, nodeID, width, height int, scale float64) error {
+	format, err := treemapImageFormat(path)
+	if err != nil {
+		return err
+	}
+	if err := validTreemapImageSize(width, height); err != nil {
+		return err
+	}
+	rects, err := a.Layout(nodeID, width, height, scale)
+	if err != nil {
+		return err
+	}
+	var encoded bytes.Buffer
+	if err := writeTreemapImage(&encoded, format, rects, width, height, a.treemapImageStyle(scale)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, encoded.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write treemap image: %w", err)
+	}
+	return nil
+}
+
+// ExportTreemapImage asks where to save the treemap of nodeID as a PNG or
+// SVG image of width×height pixels and returns the written path. scale sizes
+// borders and labels like the window's device pixel scale.
+func (a *App) ExportTreemapImage(nodeID, width, height int, scale float64) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("app not initialized")
+	}
+	if err := validTreemapImageSize(width, height); err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Save treemap image",
+		DefaultFilename:      "spacebrowser-treemap.png",
+		CanCreateDirectories: true,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PNG images (*.png)", Pattern: "*.png"},
+			{DisplayName: "SVG images (*.svg)", Pattern: "*.svg"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if filepath.Ext(path) == "" {
+		path += ".png"
+	}
+	if err := a.saveTreemapImage(path, nodeID, width, height, scale); err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func treemapImageRects() []Rect {
+	root := 0
+	return []Rect{
+		{X: 0, Y: 0, W: 200, H: 100, Name: "data", Size: 3 << 20, IsFolder: true, Children: []int{1, 2}},
+		{X: 10, Y: 20, W: 120, H: 70, ParentID: &root, Name: "notes & <drafts>.txt", Size: 2 << 20, Depth: 1},
+		{X: 140, Y: 20, W: 50, H: 70, ParentID: &root, Name: "old-server.log", Size: 1 << 20, Depth: 1, TrashDeletedAt: 1},
+	}
+}
+
+func TestWriteTreemapImagePNGUsesPaletteAndRelief(t *testing.T) {
+	style := treemapImageStyle{Colors: []string{"#102030", "#c0a080"}, ReliefStrength: 0.5, Scale: 1, Now: time.Unix(1, 0)}
+	var encoded bytes.Buffer
+	if err := writeTreemapImage(&encoded, treemapImagePNG, treemapImageRects(), 200, 100, style); err != nil {
+		t.Fatal(err)
+	}
+	picture, err := png.Decode(&encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := picture.Bounds(); bounds.Dx() != 200 || bounds.Dy() != 100 {
+		t.Fatalf("image bounds = %v", bounds)
+	}
+	rgba := func(x, y int) color.RGBA {
+		r, g, b, a := picture.At(x, y).RGBA()
+		return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+	for name, check := range map[string]struct {
+		x, y int
+		want color.RGBA
+	}{
+		"root fill":     {5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		"border":        {10, 50, color.RGBA{0x22, 0x22, 0x22, 0xff}},
+		"light relief":  {11, 50, blendColor(parseHexColor("#c0a080"), 0xff, 0.5)},
+		"dark relief":   {128, 50, blendColor(parseHexColor("#c0a080"), 0, 0.5)},
+		"palette color": {20, 85, parseHexColor("#c0a080")},
+	} {
+		if got := rgba(check.x, check.y); got != check.want {
+			t.Errorf("%s at (%d,%d) = %v, want %v", name, check.x, check.y, got, check.want)
+		}
+	}
+
+	style.ColorTrashByAge = true
+	encoded.Reset()
+	if err := writeTreemapImage(&encoded, treemapImagePNG, treemapImageRects(), 200, 100, style); err != nil {
+		t.Fatal(err)
+	}
+	if picture, err = png.Decode(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := rgba(145, 85); got != trashAgeColors[0] {
+		t.Fatalf("Trash contents deleted just now = %v, want %v", got, trashAgeColors[0])
+	}
+}
+
+func TestWriteTreemapImageSVGHasLabels(t *testing.T) {
+	style := treemapImageStyle{Colors: []string{"#102030", "#c0a080"}, CornerRadius: 4, Scale: 1}
+	var encoded bytes.Buffer
+	if err := writeTreemapImage(&encoded, treemapImageSVG, treemapImageRects(), 200, 100, style); err != nil {
+		t.Fatal(err)
+	}
+	svg := encoded.String()
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100"`,
+		`<rect x="10" y="20" width="120" height="70" rx="4" fill="#c0a080"/>`,
+		`>data (3.0 MB)</text>`,
+		`>notes &amp; &lt;drafts&gt;.t…</text>`,
+		`>2.0 MB</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG is missing %s:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "old-server.log") {
+		t.Errorf("a name wider than its rectangle should be shortened:\n%s", svg)
+	}
+}
+
+func TestTreemapImageRejectsUnknownFormatsAndSizes(t *testing.T) {
+	for _, path := range []string{"treemap.jpg", "treemap"} {
+		if _, err := treemapImageFormat(path); err == nil {
+			t.Errorf("treemapImageFormat(%q) succeeded", path)
+		}
+	}
+	if format, err := treemapImageFormat("Report.SVG"); err != nil || format != treemapImageSVG {
+		t.Fatalf("treemapImageFormat(Report.SVG) = %q, %v", format, err)
+	}
+	if err := writeTreemapImage(&bytes.Buffer{}, treemapImagePNG, nil, maximumTreemapImageSide+1, 10, treemapImageStyle{}); err == nil {
+		t.Fatal("an oversized image was accepted")
+	}
+	if err := validTreemapImageSize(maximumTreemapImageSide, maximumTreemapImageSide); err == nil {
+		t.Fatal("an image with too many pixels was accepted")
+	}
+	if err := validTreemapImageSize(8192, 8192); err != nil {
+		t.Fatalf("a 64 megapixel image was rejected: %v", err)
+	}
+}
+
+func TestCommandLineReportSavesTreemapImage(t *testing.T) {
+	scanPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scanPath, "data.bin"), make([]byte, 8192), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	imagePath := filepath.Join(t.TempDir(), "treemap.png")
+	options, err := parseCommandLine([]string{scanPath, "--render", imagePath, "--render-size=320x200"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output strings.Builder
+	if err := runCommandLineReport(newApp(filepath.Join(t.TempDir(), "settings.json")), options, &output); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	config, err := png.DecodeConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 320 || config.Height != 200 {
+		t.Fatalf("saved a %dx%d image", config.Width, config.Height)
+	}
+	if !strings.Contains(output.String(), imagePath) {
+		t.Fatalf("output %q does not name the image", output.String())
+	}
+}
+
+func TestPNGCanvasDrawsNonASCIILabels(t *testing.T) {
+	labels, err := labelFont()
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(text string) *image.RGBA {
+		canvas := &pngCanvas{image: image.NewRGBA(image.Rect(0, 0, 80, 20)), labels: labelFaces{font: labels}}
+		draw.Draw(canvas.image, canvas.image.Bounds(), image.White, image.Point{}, draw.Src)
+		ascent, _ := canvas.fontBounds(treemapFontSize)
+		canvas.text(2, 2+ascent, text, treemapFontSize, color.RGBA{A: 0xff})
+		return canvas.image
+	}
+	cyrillic, placeholder := render("Отчёт"), render("?????")
+	if bytes.Equal(cyrillic.Pix, render("").Pix) {
+		t.Fatal("a Cyrillic label drew nothing")
+	}
+	if bytes.Equal(cyrillic.Pix, placeholder.Pix) {
+		t.Fatal("a Cyrillic label was drawn as placeholders")
+	}
+}
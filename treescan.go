@@ -26,6 +26,7 @@ type Node struct {
 	IsFolder       bool    `json:"is_folder"`
 	IsFreeSpace    bool    `json:"is_free_space"`
 	IsSmallFiles   bool    `json:"is_small_files"`
+	IsUnaccounted  bool    `json:"is_unaccounted"`
 	SmallFileCount int64   `json:"small_file_count,omitempty"`
 	SmallFileLimit int64   `json:"small_file_limit,omitempty"`
 	Depth          int     `json:"depth"`
@@ -35,11 +36,52 @@ type Node struct {
 	// Only set on mount roots
 	DiskTotal int64 `json:"disk_total,omitempty"`
 	DiskFree  int64 `json:"disk_free,omitempty"`
+	// Containing mount when volume context is shown for a non-root scan
+	DiskPath string `json:"disk_path,omitempty"`
+
+	// Only set on [Unaccounted Space] nodes
+	ReservedSpace  int64 `json:"reserved_space,omitempty"`
+	ScanErrorCount int64 `json:"scan_error_count,omitempty"`
+	SkippedCount   int64 `json:"skipped_count,omitempty"`
 
 	ModTime    int64  `json:"-"`
 	LinkCount  uint64 `json:"-"`
 	EntryFiles int    `json:"-"`
 	EntryDirs  int    `json:"-"`
+	// ApparentSize is the file length, set on files and [Small Files]
+	// aggregates; like Size it counts hard-linked files once. Folder totals
+	// are summed when needed so tree mutations need not maintain them.
+	ApparentSize int64 `json:"-"`
+
+	// Only set on files with several links; shared with the other paths the
+	// scan reached the same file through
+	HardLinks *hardLinkSet `json:"-"`
+
+	// Only set on [Small Files] aggregates when the profile keeps details
+	SmallFiles []SmallFileEntry `json:"-"`
+
+	// Only set on folders where another filesystem is mounted
+	Mount          *platform.MountInfo `json:"-"`
+	MountCollapsed bool                `json:"-"`
+}
+
+// SmallFileEntry is the compact record kept for one file folded into a
+// [Small Files] aggregate. Size is zero for additional hard-link paths whose
+// allocation was already counted elsewhere.
+type SmallFileEntry struct {
+	Name         string
+	Size         int64
+	ApparentSize int64
+	ModTime      int64
+	LinkCount    uint64
+	HardLinks    *hardLinkSet
+}
+
+// hardLinkSet records every path through which a scan reached one file with
+// several links, so that callers can tell whether all of its links lie in a
+// given set of folders.
+type hardLinkSet struct {
+	paths []string
 }
 
 // ==============================
@@ -57,11 +99,13 @@ type Scanner struct {
 	nodesMu             sync.Mutex
 	idCounter           int64
 	seen                map[platform.FileIdentity]*Node
+	hardLinks           map[platform.FileIdentity]*hardLinkSet
 	untrustedSeen       map[platform.FileIdentity]untrustedIdentityReference
 	untrustedCollisions map[platform.FileIdentity]*untrustedIdentityBucket
 	seenMu              sync.Mutex
 	seenDirs            map[string]struct{}
 	seenDirsMu          sync.Mutex
+	mounts              map[string]platform.MountInfo // read-only once the scan starts
 
 	workDiscovered int64
 	workProcessed  int64
@@ -112,6 +156,7 @@ func NewScannerWithFilesystem(p *Profile, maxWorkers int, filesystem platform.Sc
 		sem:                 make(chan struct{}, maxWorkers),
 		maxWorkers:          maxWorkers,
 		seen:                make(map[platform.FileIdentity]*Node),
+		hardLinks:           make(map[platform.FileIdentity]*hardLinkSet),
 		untrustedSeen:       make(map[platform.FileIdentity]untrustedIdentityReference),
 		untrustedCollisions: make(map[platform.FileIdentity]*untrustedIdentityBucket),
 		seenDirs:            make(map[string]struct{}),
@@ -190,21 +235,33 @@ func updateNodeUsage(node *Node, usage platform.FileUsage) {
 	}
 }
 
-func (s *Scanner) registerFileIdentity(path string, info os.FileInfo, usage platform.FileUsage, node *Node) (platform.FileUsage, bool) {
+// registerFileIdentity reports whether the file at path was already reached
+// through another link, and returns the set of paths recorded for files with
+// several links. Files first reached without a link count get no set.
+func (s *Scanner) registerFileIdentity(path string, info os.FileInfo, usage platform.FileUsage, node *Node) (platform.FileUsage, *hardLinkSet, bool) {
 	if !usage.HasIdentity {
-		return usage, false
+		return usage, nil, false
 	}
 	updateNodeUsage(node, usage)
 	if usage.IdentityNeedsConfirmation {
-		return s.registerUntrustedFileIdentity(path, info, usage, node)
+		usage, duplicate := s.registerUntrustedFileIdentity(path, info, usage, node)
+		return usage, nil, duplicate
 	}
 
 	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
 	existing, ok := s.seen[usage.Identity]
 	if !ok {
 		s.seen[usage.Identity] = node
-		s.seenMu.Unlock()
-		return usage, false
+		if !usage.HasLinkCount || usage.LinkCount < 2 {
+			return usage, nil, false
+		}
+		links := &hardLinkSet{paths: []string{path}}
+		s.hardLinks[usage.Identity] = links
+		if node != nil {
+			node.HardLinks = links
+		}
+		return usage, links, false
 	}
 	linkCount := usage.LinkCount
 	if !usage.HasLinkCount || linkCount < 2 {
@@ -213,8 +270,11 @@ func (s *Scanner) registerFileIdentity(path string, info os.FileInfo, usage plat
 	if existing != nil && existing.LinkCount < linkCount {
 		existing.LinkCount = linkCount
 	}
-	s.seenMu.Unlock()
-	return usage, true
+	links := s.hardLinks[usage.Identity]
+	if links != nil {
+		links.paths = append(links.paths, path)
+	}
+	return usage, links, true
 }
 
 func (s *Scanner) registerUntrustedFileIdentity(path string, info os.FileInfo, usage platform.FileUsage, node *Node) (platform.FileUsage, bool) {
@@ -321,6 +381,21 @@ func (s *Scanner) Nodes() []*Node {
 	return out
 }
 
+// loadMountTable indexes mount points so folders where another filesystem is
+// mounted can be marked. Mount boundaries are informational, so a platform
+// without a readable mount table scans exactly as before.
+func (s *Scanner) loadMountTable(root string) {
+	mounts, err := platform.MountTable(s.filesystem)
+	if err != nil {
+		s.report.RecordError(scanErrorMountTable, root, err)
+	}
+	s.mounts = make(map[string]platform.MountInfo, len(mounts))
+	for _, mount := range mounts {
+		// Later entries are stacked over earlier ones on the same mount point.
+		s.mounts[mount.MountPoint] = mount
+	}
+}
+
 // buildTree scans 'path' and all descendants, assigning IDs.
 // Concurrency: subdirectories of a folder are scanned in parallel, bounded by s.sem.
 func (s *Scanner) buildTree(path string, depth int, parentID int, fileCount, dirCount *int64) (*Node, error) {
@@ -344,6 +419,9 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 		s.report.RecordSkip(scanSkipRepeatedDirectory)
 		return nil, nil
 	}
+	if depth == 0 {
+		s.loadMountTable(abs)
+	}
 	s.reportProgress(abs)
 
 	// directory node
@@ -358,6 +436,9 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 		ModTime:   modTime,
 		EntryDirs: 1,
 	}
+	if mount, ok := s.mounts[abs]; ok {
+		root.Mount = &mount
+	}
 	s.assignID(root)
 	atomic.AddInt64(dirCount, 1)
 	atomic.AddInt64(&s.dirCount, 1)
@@ -379,7 +460,8 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 		modTime int64
 	}
 	subdirs := make([]subdir, 0, 32)
-	var smallFilesSize, smallFileCount int64
+	var smallFilesSize, smallFilesApparentSize, smallFileCount int64
+	var smallFiles []SmallFileEntry
 	var processedBatch int64
 	flushProcessed := func() {
 		if processedBatch > 0 {
@@ -482,18 +564,20 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 			var child *Node
 			if !isSmall {
 				child = &Node{
-					ParentID:  root.ID,
-					Name:      name,
-					FullPath:  full,
-					Size:      usage.AllocatedSize,
-					IsFolder:  false,
-					Depth:     depth + 1,
-					ModTime:   info.ModTime().Unix(),
-					LinkCount: usage.LinkCount,
+					ParentID:     root.ID,
+					Name:         name,
+					FullPath:     full,
+					Size:         usage.AllocatedSize,
+					ApparentSize: info.Size(),
+					IsFolder:     false,
+					Depth:        depth + 1,
+					ModTime:      info.ModTime().Unix(),
+					LinkCount:    usage.LinkCount,
 				}
 			}
+			var links *hardLinkSet
 			var duplicate bool
-			usage, duplicate = s.registerFileIdentity(full, info, usage, child)
+			usage, links, duplicate = s.registerFileIdentity(full, info, usage, child)
 			if usage.MetadataError != nil {
 				s.report.RecordError(scanErrorUsageMetadata, full, usage.MetadataError)
 			}
@@ -506,11 +590,19 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 
 			if isSmall {
 				smallFileCount++
+				if s.profile.KeepSmallFileDetails {
+					entry := SmallFileEntry{Name: name, Size: sz, ApparentSize: info.Size(), ModTime: info.ModTime().Unix(), LinkCount: usage.LinkCount, HardLinks: links}
+					if duplicate {
+						entry.Size, entry.ApparentSize = 0, 0
+					}
+					smallFiles = append(smallFiles, entry)
+				}
 				if duplicate {
 					s.report.RecordSkip(scanSkipDuplicateIdentity)
 					return true
 				}
 				smallFilesSize += sz
+				smallFilesApparentSize += info.Size()
 				return true
 			}
 
@@ -537,9 +629,11 @@ func (s *Scanner) buildTreeWithModTime(path string, depth int, parentID int, fil
 			ParentID:       root.ID,
 			Name:           "[Small Files]",
 			Size:           smallFilesSize,
+			ApparentSize:   smallFilesApparentSize,
 			IsSmallFiles:   true,
 			SmallFileCount: smallFileCount,
 			SmallFileLimit: s.profile.MinFileSize,
+			SmallFiles:     smallFiles,
 			Depth:          root.Depth + 1,
 		})
 		root.Size += smallFilesSize
@@ -54,6 +54,15 @@ func (p networkRootPlatform) IsLikelyNetworkFS(path string) bool {
 	return filepath.Clean(path) == filepath.Clean(p.root)
 }
 
+type mountTablePlatform struct {
+	platform.API
+	mounts []platform.MountInfo
+}
+
+func (p mountTablePlatform) MountTable() ([]platform.MountInfo, error) {
+	return p.mounts, nil
+}
+
 type metadataFailureDirEntry struct {
 	os.DirEntry
 }
@@ -535,6 +544,33 @@ func TestPublishScanResultRejectsCancelledContext(t *testing.T) {
 	}
 }
 
+func TestScannerMarksMountBoundaries(t *testing.T) {
+	dir := t.TempDir()
+	mounted := filepath.Join(dir, "srv")
+	if err := os.MkdirAll(filepath.Join(mounted, "data"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	mount := platform.MountInfo{MountPoint: mounted, FilesystemType: "xfs", Device: "8:17", Source: "/dev/sdb1"}
+	profile := defaultProfile()
+	profile.SkipNetworkFS = false
+	scanner := NewScannerWithFilesystem(profile, 1, mountTablePlatform{API: platform.Impl, mounts: []platform.MountInfo{mount}})
+	var files, dirs int64
+	root, err := scanner.buildTree(dir, 0, -1, &files, &dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Mount != nil || len(root.Children) != 1 {
+		t.Fatalf("scan root = %+v, want one unmounted folder", root)
+	}
+	srv := root.Children[0]
+	if srv.Mount == nil || *srv.Mount != mount {
+		t.Fatalf("mount point recorded as %+v, want %+v", srv.Mount, mount)
+	}
+	if len(srv.Children) != 1 || srv.Children[0].Mount != nil {
+		t.Fatal("a folder inside the mount was marked as a mount point")
+	}
+}
+
 func TestScannerAggregatesSmallFiles(t *testing.T) {
 	dir := t.TempDir()
 	files := map[string][]byte{
@@ -822,6 +858,46 @@ func TestScannerCountsHardLinkEntriesInSmallFileAggregate(t *testing.T) {
 	}
 }
 
+func TestScannerRecordsEveryPathToHardLinkedFile(t *testing.T) {
+	rootPath := t.TempDir()
+	for name, size := range map[string]int{"a.bin": 4096, "tiny.txt": 100} {
+		if err := os.WriteFile(filepath.Join(rootPath, name), make([]byte, size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{"b.bin": "a.bin", "tiny-link.txt": "tiny.txt"} {
+		if err := os.Link(filepath.Join(rootPath, target), filepath.Join(rootPath, link)); err != nil {
+			t.Skipf("hard links are not supported: %v", err)
+		}
+	}
+
+	profile := defaultProfile()
+	profile.MinFileSize = 1024
+	profile.KeepSmallFileDetails = true
+	profile.SkipNetworkFS = false
+	scanner := NewScanner(profile, 1)
+	var fileCount, dirCount int64
+	root, err := scanner.buildTree(rootPath, 0, -1, &fileCount, &dirCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var large *Node
+	var small []SmallFileEntry
+	for _, child := range root.Children {
+		if child.IsSmallFiles {
+			small = child.SmallFiles
+		} else {
+			large = child
+		}
+	}
+	if large == nil || large.HardLinks == nil || len(large.HardLinks.paths) != 2 {
+		t.Fatalf("hard-linked file node = %+v, want both of its paths recorded", large)
+	}
+	if len(small) != 2 || small[0].HardLinks == nil || small[0].HardLinks != small[1].HardLinks || len(small[0].HardLinks.paths) != 2 {
+		t.Fatalf("small hard-linked entries = %+v, want one shared set of both paths", small)
+	}
+}
+
 func TestScanReportLoggingIsCompactAndInformative(t *testing.T) {
 	var output strings.Builder
 	app := &App{logger: NewSeverityLogger(verbosityInfo, &output)}
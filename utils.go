@@ -8,19 +8,38 @@ import (
 )
 
 type Profile struct {
-	PlatformSystem       string             `json:"platformSystem"`
-	ExcludedPaths        []string           `json:"excludedPaths"`
-	SkipHidden           bool               `json:"skipHidden"`
-	MinFileSize          int64              `json:"minFileSize"`
-	FollowSymlinks       bool               `json:"followSymlinks"`
-	SkipNetworkFS        bool               `json:"skipNetworkFS"`
-	ShowTooltips         bool               `json:"showTooltips"`
-	TooltipDelayMS       int                `json:"tooltipDelayMs"`
-	AllowDelete          bool               `json:"allowDelete"`
-	AllowPermanentDelete bool               `json:"allowPermanentDelete"`
-	RescanOnDelete       bool               `json:"rescanOnDelete"`
-	Appearance           AppearanceSettings `json:"appearance"`
-	Controls             ControlSettings    `json:"controls"`
+	PlatformSystem       string   `json:"platformSystem"`
+	ExcludedPaths        []string `json:"excludedPaths"`
+	SkipHidden           bool     `json:"skipHidden"`
+	MinFileSize          int64    `json:"minFileSize"`
+	FollowSymlinks       bool     `json:"followSymlinks"`
+	KeepSmallFileDetails bool     `json:"keepSmallFileDetails"`
+	ShowVolumeContext    bool     `json:"showVolumeContext"`
+	SkipNetworkFS        bool     `json:"skipNetworkFS"`
+	ShowTooltips         bool     `json:"showTooltips"`
+	TooltipDelayMS       int      `json:"tooltipDelayMs"`
+	AllowDelete          bool     `json:"allowDelete"`
+	AllowPermanentDelete bool     `json:"allowPermanentDelete"`
+	RescanOnDelete       bool     `json:"rescanOnDelete"`
+	// ProtectedPaths are never deleted, together with everything inside
+	// them, in addition to the built-in system locations.
+	ProtectedPaths []string `json:"protectedPaths"`
+	// RequireAuditLog keeps delete commands disabled whenever the audit log
+	// cannot be written.
+	RequireAuditLog bool `json:"requireAuditLog"`
+	// DeleteStrategy is "trash" or "quarantine". Quarantine moves deleted
+	// items into QuarantineDir and keeps them for as long as the system
+	// policy sets, or until they are restored.
+	DeleteStrategy string             `json:"deleteStrategy"`
+	QuarantineDir  string             `json:"quarantineDir"`
+	Appearance     AppearanceSettings `json:"appearance"`
+	Controls       ControlSettings    `json:"controls"`
+	// ScanProfiles override the scan settings above for the roots they are
+	// bound to.
+	ScanProfiles []ScanProfile `json:"scanProfiles"`
+	// Policy is reported by GetProfile when a system policy file is in
+	// effect. It is never saved, and SetProfile ignores it.
+	Policy *ProfilePolicy `json:"policy,omitempty"`
 }
 
 type AppearanceSettings struct {
@@ -30,6 +49,12 @@ type AppearanceSettings struct {
 	ReliefStrength  float64 `json:"reliefStrength"`
 	HoverBrightness float64 `json:"hoverBrightness"`
 	RollOverBoxes   bool    `json:"rollOverBoxes"`
+	// ColorTrashByAge colors Trash contents by how long ago they were
+	// deleted instead of by depth.
+	ColorTrashByAge bool `json:"colorTrashByAge"`
+	// CustomPalettes are user palettes that Palette can select next to the
+	// built-in ones.
+	CustomPalettes []Palette `json:"customPalettes,omitempty"`
 }
 
 type ControlSettings struct {
@@ -60,6 +85,7 @@ func defaultAppearanceSettings() AppearanceSettings {
 		ReliefStrength:  0.30,
 		HoverBrightness: 0.12,
 		RollOverBoxes:   false,
+		ColorTrashByAge: false,
 	}
 }
 
@@ -108,6 +134,7 @@ func defaultProfile() *Profile {
 		AllowDelete:          false,
 		AllowPermanentDelete: false,
 		RescanOnDelete:       true,
+		DeleteStrategy:       deleteStrategyTrash,
 		Appearance:           defaultAppearanceSettings(),
 		Controls:             defaultControlSettings(),
 	}
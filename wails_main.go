@@ -4,6 +4,7 @@ package main
 import (
 	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -25,6 +26,9 @@ func main() {
 	logOutput := terminalLogOutput()
 
 	cliOptions, err := parseCommandLine(os.Args[1:])
+	if err == nil {
+		err = cliOptions.applyEnvironment(os.Getenv)
+	}
 	if err != nil {
 		consoleLogger := NewSeverityLogger(defaultVerbosity, logOutput)
 		consoleLogger.Criticalf("%v", err)
@@ -41,8 +45,37 @@ func main() {
 	}
 
 	consoleLogger := NewSeverityLogger(cliOptions.verbosity, logOutput)
-	app := newAppWithLogger(consoleLogger)
+	app := newAppWithLogger(consoleLogger, cliOptions.settingsPath)
+	if err := app.setSessionOverrides(cliOptions.overrides); err != nil {
+		consoleLogger.Criticalf("%v", err)
+		os.Exit(2)
+	}
+	for _, override := range cliOptions.overrides.describe() {
+		consoleLogger.Infof("overridden for this session: %s", override)
+	}
+	if cliOptions.headless() {
+		if err := runCommandLineReport(app, cliOptions, os.Stdout); err != nil {
+			consoleLogger.Criticalf("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
 	app.initialScanPath = cliOptions.initialPath
+	if cliOptions.serveAddress != "" {
+		webAssets, err := fs.Sub(assets, "web")
+		if err == nil {
+			err = runServer(app, webAssets, serveOptions{
+				address:     cliOptions.serveAddress,
+				token:       cliOptions.serveToken,
+				allowDelete: cliOptions.serveAllowDelete,
+			}, os.Stdout)
+		}
+		if err != nil {
+			consoleLogger.Criticalf("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
 	consoleLogger.Infof("starting SpaceBrowser %s (verbosity %d)", applicationVersion(), cliOptions.verbosity)
 	if cliOptions.initialPath != "" {
 		consoleLogger.Infof("requested initial scan: %s", cliOptions.initialPath)
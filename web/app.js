@@ -1,13 +1,20 @@
 import { DefaultPath, GetInitialScanPath } from "./wailsjs/go/main/App.js";
+import { initAuditLog } from "./audit-log.js";
+import { initCleanupBasket, refreshCleanupBasket } from "./cleanup-basket.js";
 import { byId } from "./dom.js";
-import { hideContextMenu, initFileActions } from "./file-actions.js";
+import { hideContextMenu, initFileActions, requestBasketCleanup, requestQuarantineRestore, requestTrashBrowserAction } from "./file-actions.js";
 import { initFolderPicker } from "./folder-picker.js";
+import { initHTMLReport } from "./html-report.js";
+import { initFolderSizes } from "./folder-sizes.js";
 import { addControlEventListeners, eventMatchesShortcut, shortcutCanRun } from "./controls.js";
 import { logError } from "./logging.js";
 import { initLocationSelector } from "./locations.js";
 import { initNavigation, navigateToSelected } from "./navigation.js";
+import { initQuarantine } from "./quarantine.js";
 import { analyze, initScan } from "./scan.js";
 import { initSettings, loadSettingsState } from "./settings.js";
+import { initTrashBrowser } from "./trash-browser.js";
+import { initTreemapImage } from "./treemap-image.js";
 import { getSelectedRect, initTreemapView, isPassiveRect, redraw } from "./treemap-view.js";
 import { initZoom } from "./zoom.js";
 import { AppState } from "./state.js";
@@ -33,6 +40,13 @@ document.addEventListener("DOMContentLoaded", async () => {
   initNavigation({ redraw, getSelectedRect, isPassiveRect });
   initSettings({ redraw });
   initFileActions({ redraw, getSelectedRect, isPassiveRect });
+  initCleanupBasket({ requestCleanup: requestBasketCleanup });
+  initTrashBrowser({ requestAction: requestTrashBrowserAction });
+  initQuarantine({ requestRestore: requestQuarantineRestore });
+  initAuditLog();
+  initTreemapImage();
+  initHTMLReport();
+  initFolderSizes();
   initScan({ redraw, hideContextMenu });
   initLocationSelector({ analyze });
   initFolderPicker();
@@ -59,6 +73,7 @@ document.addEventListener("DOMContentLoaded", async () => {
   } catch (error) {
     logError("loading settings failed:", error);
   }
+  await refreshCleanupBasket();
 
   try {
     const initialPath = await GetInitialScanPath();
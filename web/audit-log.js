@@ -0,0 +1,87 @@
+import { GetAuditLog } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { formatModTime, formatSize } from "./format.js";
+import { showErrorToast } from "./notifications.js";
+
+const actionLabels = {
+  "trash": "Moved to Trash",
+  "quarantine": "Quarantined",
+  "empty-trash": "Emptied Trash",
+  "delete-permanently": "Deleted permanently",
+  "restore": "Restored",
+  "expire-quarantine": "Quarantine expired",
+};
+
+let queryTimer = 0;
+
+function auditLogListItem(entry) {
+  const row = document.createElement("li");
+  row.className = "trash-browser-row audit-log-row";
+  if (entry.outcome !== "succeeded") row.classList.add("audit-log-failed");
+  const action = document.createElement("span");
+  action.className = "audit-log-action";
+  action.textContent = actionLabels[entry.action] || entry.action;
+  const name = document.createElement("span");
+  name.className = "small-files-name";
+  name.textContent = entry.path;
+  name.title = [
+    entry.path,
+    entry.trashPath ? `${entry.action === "restore" ? "From" : "To"}: ${entry.trashPath}` : "",
+    `User: ${entry.user}`,
+    entry.error ? `Failed: ${entry.error}` : "",
+  ].filter(Boolean).join("\n");
+  const size = document.createElement("span");
+  size.className = "small-files-size";
+  size.textContent = entry.size ? formatSize(entry.size) : "";
+  const date = document.createElement("span");
+  date.className = "small-files-date";
+  date.textContent = formatModTime(Date.parse(entry.time) / 1000);
+  row.append(action, name, size, date);
+  return row;
+}
+
+async function refreshAuditLog() {
+  byId("auditLogSummary").textContent = "Reading audit log...";
+  let entries = [];
+  try {
+    entries = await GetAuditLog({
+      path: byId("auditLogPathFilter").value.trim(),
+      action: byId("auditLogActionFilter").value,
+      since: 0,
+      until: 0,
+      limit: 0,
+    }) || [];
+  } catch (error) {
+    showErrorToast(error);
+  }
+  byId("auditLogList").replaceChildren(...entries.map(auditLogListItem));
+  byId("auditLogList").hidden = entries.length === 0;
+  byId("auditLogSummary").textContent = entries.length
+    ? `${entries.length.toLocaleString()} ${entries.length === 1 ? "entry" : "entries"}, newest first`
+    : "No matching entries";
+}
+
+function showAuditLog() {
+  const dialog = byId("auditLogDialog");
+  if (!dialog.open) dialog.showModal();
+  refreshAuditLog();
+}
+
+function closeAuditLog() {
+  const dialog = byId("auditLogDialog");
+  if (dialog.open) dialog.close();
+}
+
+export function initAuditLog() {
+  byId("auditLogButton").addEventListener("click", showAuditLog);
+  byId("closeAuditLogButton").addEventListener("click", closeAuditLog);
+  byId("auditLogDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeAuditLog();
+  });
+  byId("auditLogActionFilter").addEventListener("change", refreshAuditLog);
+  byId("auditLogPathFilter").addEventListener("input", () => {
+    clearTimeout(queryTimer);
+    queryTimer = setTimeout(refreshAuditLog, 250);
+  });
+}
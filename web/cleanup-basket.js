@@ -0,0 +1,132 @@
+import {
+  AddToCleanupBasket,
+  ClearCleanupBasket,
+  ExportCleanupBasket,
+  GetCleanupBasket,
+  RemoveFromCleanupBasket,
+} from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { formatSize } from "./format.js";
+import { logError } from "./logging.js";
+import { mousePosition, showErrorToast, showToastAt } from "./notifications.js";
+
+let requestCleanup = () => {};
+let currentBasket = null;
+
+function updateBasketButton(basket) {
+  const button = byId("cleanupBasketButton");
+  const count = basket?.items?.length || 0;
+  button.textContent = count ? `Basket (${count.toLocaleString()})` : "Basket";
+  button.dataset.tooltip = count
+    ? `${formatSize(basket.reclaimableSize)} reclaimable in the cleanup basket`
+    : "Cleanup basket is empty";
+}
+
+function basketSummary(basket) {
+  const count = basket.items.length;
+  if (!count) return "Queue items from the treemap context menu to review them here before cleaning up.";
+  let summary = `${count.toLocaleString()} ${count === 1 ? "item" : "items"}, ${formatSize(basket.reclaimableSize)} reclaimable`;
+  if (basket.hardLinkedSize > 0) {
+    summary += `\n${formatSize(basket.hardLinkedSize)} has hard links outside the basket and is only freed when every link is deleted`;
+  }
+  return summary;
+}
+
+function basketListItem(item) {
+  const row = document.createElement("li");
+  const name = document.createElement("span");
+  name.className = "small-files-name";
+  name.textContent = item.path;
+  name.title = item.path;
+  const size = document.createElement("span");
+  size.className = "small-files-size";
+  size.textContent = formatSize(item.reclaimableSize);
+  const status = document.createElement("span");
+  status.className = "small-files-date";
+  status.textContent = !item.inCurrentScan ? "Not in this scan"
+    : item.hardLinkedSize > 0 ? `+${formatSize(item.hardLinkedSize)} linked` : "";
+  const remove = document.createElement("button");
+  remove.type = "button";
+  remove.textContent = "Remove";
+  remove.addEventListener("click", () => updateBasket(RemoveFromCleanupBasket([item.path])));
+  row.append(name, size, status, remove);
+  return row;
+}
+
+function renderBasket(basket) {
+  currentBasket = basket;
+  updateBasketButton(basket);
+  const empty = !basket.items.length;
+  byId("cleanupBasketSummary").textContent = basketSummary(basket);
+  byId("cleanupBasketList").replaceChildren(...basket.items.map(basketListItem));
+  byId("cleanupBasketList").hidden = empty;
+  for (const id of ["exportCleanupScriptButton", "exportCleanupPlanButton", "clearCleanupBasketButton", "executeCleanupBasketButton"]) {
+    byId(id).disabled = empty;
+  }
+}
+
+async function updateBasket(request) {
+  try {
+    renderBasket(await request);
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
+export async function refreshCleanupBasket() {
+  try {
+    renderBasket(await GetCleanupBasket());
+  } catch (error) {
+    logError("loading the cleanup basket failed:", error);
+  }
+}
+
+// addToCleanupBasket queues the given nodes; nothing is deleted until the
+// basket is reviewed and cleaned up.
+export async function addToCleanupBasket(nodeIds) {
+  try {
+    const basket = await AddToCleanupBasket(nodeIds);
+    renderBasket(basket);
+    showToastAt(mousePosition.x, mousePosition.y, `Added to cleanup basket · ${formatSize(basket.reclaimableSize)} reclaimable`, 1600);
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
+async function showCleanupBasket() {
+  await refreshCleanupBasket();
+  const dialog = byId("cleanupBasketDialog");
+  if (!dialog.open) dialog.showModal();
+}
+
+function closeCleanupBasket() {
+  const dialog = byId("cleanupBasketDialog");
+  if (dialog.open) dialog.close();
+}
+
+async function exportCleanupBasket(format) {
+  try {
+    const path = await ExportCleanupBasket(format);
+    if (path) showToastAt(mousePosition.x, mousePosition.y, `Cleanup plan saved to ${path}`, 2400);
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
+export function initCleanupBasket(options) {
+  requestCleanup = options.requestCleanup;
+  byId("cleanupBasketButton").addEventListener("click", showCleanupBasket);
+  byId("closeCleanupBasketButton").addEventListener("click", closeCleanupBasket);
+  byId("cleanupBasketDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeCleanupBasket();
+  });
+  byId("exportCleanupScriptButton").addEventListener("click", () => exportCleanupBasket("script"));
+  byId("exportCleanupPlanButton").addEventListener("click", () => exportCleanupBasket("json"));
+  byId("clearCleanupBasketButton").addEventListener("click", () => updateBasket(ClearCleanupBasket()));
+  byId("executeCleanupBasketButton").addEventListener("click", () => {
+    if (!currentBasket?.items.length) return;
+    closeCleanupBasket();
+    requestCleanup(currentBasket, refreshCleanupBasket);
+  });
+}
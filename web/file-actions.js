@@ -1,15 +1,29 @@
 import {
   DeleteNode,
+  DeleteNodes,
+  DeleteSmallFile,
+  DeleteTrashItems,
+  ExcludeMount,
+  ExecuteCleanupBasket,
   GetDefaultApplicationName,
+  GetSelectionInfo,
+  GetSmallFiles,
   GetTrashRestoreInfo,
+  GetTrashUndo,
   OpenInFileBrowser,
   OpenPath,
   OpenWith,
+  PurgeTrashOlderThan,
   RestoreNode,
+  RestoreQuarantinedItems,
+  RestoreTrashItems,
+  SetMountCollapsed,
   ShowProperties,
+  UndoMoveToTrash,
 } from "./wailsjs/go/main/App.js";
+import { addToCleanupBasket } from "./cleanup-basket.js";
 import { byId } from "./dom.js";
-import { detailedByteSize } from "./format.js";
+import { detailedByteSize, formatModTime, formatSize } from "./format.js";
 import { addControlEventListeners, eventMatchesShortcut, shortcutCanRun } from "./controls.js";
 import { trimInvalidForwardNavigation, updateNavButtons, visit } from "./navigation.js";
 import { hideRectToast, mousePosition, showErrorToast, showToastAt } from "./notifications.js";
@@ -25,12 +39,42 @@ let contextMenuRequest = 0;
 const defaultApplicationNames = new Map();
 
 function trashDestinationName() {
+  if (AppState.profile?.deleteStrategy === "quarantine") return "quarantine";
   return AppState.profile?.platformSystem === "windows" ? "Recycle Bin" : "Trash";
 }
 
+async function requestBatchDeletion() {
+  if (!AppState.profile?.allowDelete) {
+    showErrorToast("Delete commands are disabled. Enable Allow delete command in Settings");
+    return;
+  }
+  const nodeIds = [...AppState.selectedNodeIds];
+  try {
+    const selection = await GetSelectionInfo(nodeIds);
+    const shown = selection.paths.slice(0, 3);
+    const hidden = selection.count - shown.length;
+    pendingDeletion = { action: "batch", nodeIds, count: selection.count, size: selection.size };
+    showDeleteConfirmation(
+      `Move ${selection.count.toLocaleString()} items to ${trashDestinationName()}?`,
+      hidden > 0 ? `${shown.join("\n")}\nand ${hidden.toLocaleString()} more` : shown.join("\n"),
+      "Total size:",
+      selection.size,
+      "Delete",
+      true,
+    );
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
 function requestSelectedDeletion() {
   hideContextMenu();
   hideRectToast();
+  if (AppState.selectedNodeIds.size > 1) {
+    if (deletionInProgress) showErrorToast("Another deletion is already in progress");
+    else requestBatchDeletion();
+    return;
+  }
   const rect = getSelectedRect();
   if (!rect) return;
   if (deletionInProgress) {
@@ -54,6 +98,10 @@ function requestSelectedDeletion() {
     showErrorToast("This item cannot be deleted");
     return;
   }
+  if (rect.is_protected) {
+    showErrorToast("This path is protected, or contains a protected path, and cannot be deleted");
+    return;
+  }
   if (!emptyTrash && rect.node_id === AppState.node_id) {
     showErrorToast("The current view cannot be deleted. Go to its parent first");
     return;
@@ -61,21 +109,16 @@ function requestSelectedDeletion() {
 
   const action = emptyTrash ? "empty" : permanent ? "permanent" : "trash";
   pendingDeletion = { action, nodeId: rect.node_id, path: rect.full_path, size: rect.size };
-  byId("deleteConfirmTitle").textContent = emptyTrash
-    ? `Empty ${trashDestinationName()}?`
-    : permanent ? "Permanently delete this item?" : `Move this item to ${trashDestinationName()}?`;
-  byId("deleteConfirmPath").textContent = emptiesAllTrashLocations
-    ? "All Trash locations for the current user will be emptied."
-    : rect.full_path;
-  byId("deleteConfirmSizeLabel").textContent = emptiesAllTrashLocations
-    ? "Displayed size:"
-    : emptyTrash ? "Contents size:" : "Size:";
-  byId("deleteConfirmSize").textContent = detailedByteSize(rect.size);
-  const confirmButton = byId("confirmDeleteButton");
-  confirmButton.textContent = emptyTrash ? "Empty" : permanent ? "Delete permanently" : "Delete";
-  confirmButton.classList.add("danger-button");
-  const dialog = byId("deleteConfirmDialog");
-  if (!dialog.open) dialog.showModal();
+  showDeleteConfirmation(
+    emptyTrash
+      ? `Empty ${trashDestinationName()}?`
+      : permanent ? "Permanently delete this item?" : `Move this item to ${trashDestinationName()}?`,
+    emptiesAllTrashLocations ? "All Trash locations for the current user will be emptied." : rect.full_path,
+    emptiesAllTrashLocations ? "Displayed size:" : emptyTrash ? "Contents size:" : "Size:",
+    rect.size,
+    emptyTrash ? "Empty" : permanent ? "Delete permanently" : "Delete",
+    true,
+  );
 }
 
 async function requestSelectedRestore() {
@@ -90,20 +133,160 @@ async function requestSelectedRestore() {
   try {
     const details = await GetTrashRestoreInfo(rect.node_id);
     pendingDeletion = { action: "restore", nodeId: rect.node_id, path: rect.full_path, size: rect.size };
-    byId("deleteConfirmTitle").textContent = "Restore this item?";
-    byId("deleteConfirmPath").textContent = `Original location: ${details.originalPath}`;
-    byId("deleteConfirmSizeLabel").textContent = "Size:";
-    byId("deleteConfirmSize").textContent = detailedByteSize(rect.size);
-    const confirmButton = byId("confirmDeleteButton");
-    confirmButton.textContent = "Restore";
-    confirmButton.classList.remove("danger-button");
-    const dialog = byId("deleteConfirmDialog");
+    showDeleteConfirmation("Restore this item?", `Original location: ${details.originalPath}`, "Size:", rect.size, "Restore", false);
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
+// requestBasketCleanup confirms cleaning up the whole cleanup basket. Items in
+// the basket are moved to Trash, or deleted permanently when already in Trash
+// and permanent deletion is allowed.
+export function requestBasketCleanup(basket, onComplete) {
+  if (deletionInProgress) {
+    showErrorToast("Another deletion is already in progress");
+    return;
+  }
+  if (!AppState.profile?.allowDelete) {
+    showErrorToast("Delete commands are disabled. Enable Allow delete command in Settings");
+    return;
+  }
+  const count = basket.items.length;
+  const shown = basket.items.slice(0, 3).map(item => item.path);
+  const hidden = count - shown.length;
+  pendingDeletion = { action: "basket", count, onComplete };
+  showDeleteConfirmation(
+    `Clean up ${count.toLocaleString()} ${count === 1 ? "item" : "items"}?`,
+    hidden > 0 ? `${shown.join("\n")}\nand ${hidden.toLocaleString()} more` : shown.join("\n"),
+    "Reclaimable:",
+    basket.reclaimableSize,
+    "Clean up",
+    true,
+  );
+}
+
+// requestTrashBrowserAction confirms restoring, permanently deleting or
+// purging items chosen in the Trash browser. request.kind is "restore",
+// "delete" or "purge"; purge also carries the age in days.
+export function requestTrashBrowserAction(request, onComplete) {
+  if (deletionInProgress) {
+    showErrorToast("Another filesystem operation is already in progress");
+    return;
+  }
+  if (request.kind !== "restore" && !(AppState.profile?.allowDelete && AppState.profile?.allowPermanentDelete)) {
+    showErrorToast("Permanent deletion is disabled. Enable Allow delete command and Allow permanent deletion in Settings");
+    return;
+  }
+  const count = request.paths.length;
+  const shown = request.paths.slice(0, 3);
+  const hidden = count - shown.length;
+  const items = `${count.toLocaleString()} ${count === 1 ? "item" : "items"}`;
+  pendingDeletion = { action: `trash-${request.kind}`, paths: request.paths, days: request.days, count, onComplete };
+  showDeleteConfirmation(
+    request.kind === "restore" ? `Restore ${items}?`
+      : request.kind === "purge" ? `Permanently delete ${items} deleted more than ${request.days.toLocaleString()} days ago?`
+        : `Permanently delete ${items}?`,
+    hidden > 0 ? `${shown.join("\n")}\nand ${hidden.toLocaleString()} more` : shown.join("\n"),
+    "Total size:",
+    request.size,
+    request.kind === "restore" ? "Restore" : "Delete permanently",
+    request.kind !== "restore",
+  );
+}
+
+// requestQuarantineRestore confirms moving quarantined items, identified by
+// their manifest IDs, back to their original locations.
+export function requestQuarantineRestore(request, onComplete) {
+  if (deletionInProgress) {
+    showErrorToast("Another filesystem operation is already in progress");
+    return;
+  }
+  const count = request.ids.length;
+  const shown = request.paths.slice(0, 3);
+  const hidden = count - shown.length;
+  pendingDeletion = { action: "quarantine-restore", ids: request.ids, count, onComplete };
+  showDeleteConfirmation(
+    `Restore ${count.toLocaleString()} ${count === 1 ? "item" : "items"} from quarantine?`,
+    hidden > 0 ? `${shown.join("\n")}\nand ${hidden.toLocaleString()} more` : shown.join("\n"),
+    "Total size:",
+    request.size,
+    "Restore",
+    false,
+  );
+}
+
+function showDeleteConfirmation(title, path, sizeLabel, size, confirmText, danger) {
+  byId("deleteConfirmTitle").textContent = title;
+  byId("deleteConfirmPath").textContent = path;
+  byId("deleteConfirmSizeLabel").textContent = sizeLabel;
+  byId("deleteConfirmSize").textContent = detailedByteSize(size);
+  const confirmButton = byId("confirmDeleteButton");
+  confirmButton.textContent = confirmText;
+  confirmButton.classList.toggle("danger-button", danger);
+  const dialog = byId("deleteConfirmDialog");
+  if (!dialog.open) dialog.showModal();
+}
+
+function requestSmallFileDeletion(folderId, entry) {
+  if (deletionInProgress) {
+    showErrorToast("Another deletion is already in progress");
+    return;
+  }
+  if (!AppState.profile?.allowDelete) {
+    showErrorToast("Delete commands are disabled. Enable Allow delete command in Settings");
+    return;
+  }
+  closeSmallFiles();
+  pendingDeletion = { action: "small-file", nodeId: folderId, name: entry.name, path: entry.path, size: entry.size };
+  showDeleteConfirmation(`Move this item to ${trashDestinationName()}?`, entry.path, "Size:", entry.size, "Delete", true);
+}
+
+function smallFileListItem(folderId, entry) {
+  const item = document.createElement("li");
+  const name = document.createElement("span");
+  name.className = "small-files-name";
+  name.textContent = entry.name;
+  name.title = entry.path;
+  const size = document.createElement("span");
+  size.className = "small-files-size";
+  size.textContent = formatSize(entry.size);
+  const date = document.createElement("span");
+  date.className = "small-files-date";
+  date.textContent = entry.mtime ? formatModTime(entry.mtime) : "";
+  const remove = document.createElement("button");
+  remove.type = "button";
+  remove.className = "danger-button";
+  remove.textContent = "Delete";
+  remove.addEventListener("click", () => requestSmallFileDeletion(folderId, entry));
+  item.append(name, size, date, remove);
+  return item;
+}
+
+// showSmallFiles lists the files folded into a [Small Files] rect so they can
+// be deleted individually.
+export async function showSmallFiles(rect) {
+  if (!rect?.is_small_files || rect.parent_id == null) return;
+  hideContextMenu();
+  hideRectToast();
+  try {
+    const entries = await GetSmallFiles(rect.parent_id);
+    const folder = AppState.rects?.find(candidate => candidate.node_id === rect.parent_id);
+    byId("smallFilesTitle").textContent = `${entries.length.toLocaleString()} small files`;
+    byId("smallFilesFolder").textContent = folder?.full_path || "";
+    byId("smallFilesList").replaceChildren(...entries.map(entry => smallFileListItem(rect.parent_id, entry)));
+    const dialog = byId("smallFilesDialog");
     if (!dialog.open) dialog.showModal();
   } catch (error) {
     showErrorToast(error);
   }
 }
 
+function closeSmallFiles() {
+  const dialog = byId("smallFilesDialog");
+  if (dialog.open) dialog.close();
+  byId("smallFilesList").replaceChildren();
+}
+
 function closeDeleteConfirmation() {
   if (deletionInProgress) return;
   const dialog = byId("deleteConfirmDialog");
@@ -111,6 +294,72 @@ function closeDeleteConfirmation() {
   pendingDeletion = null;
 }
 
+// applyTreeChange clears the selection and shows the tree after a deletion or
+// restore. It returns false when a full rescan was started instead.
+async function applyTreeChange(result) {
+  AppState.selectedRectIndex = null;
+  AppState.selectedNodeId = null;
+  AppState.selectedNodeIds.clear();
+  updateUndoButton(result.undoCount);
+  if (AppState.profile?.rescanOnDelete || result.rescanRequired) {
+    if (AppState.scanRootPath) byId("pathInput").value = AppState.scanRootPath;
+    await analyze();
+    return false;
+  }
+  AppState.fileCount = result.fileCount;
+  AppState.dirCount = result.dirCount;
+  trimInvalidForwardNavigation();
+  await redraw();
+  updateNavButtons();
+  return true;
+}
+
+async function updateUndoButton(undoCount) {
+  const button = byId("undoTrashButton");
+  if (undoCount === undefined) return;
+  button.disabled = undoCount === 0;
+  if (!undoCount) {
+    button.dataset.tooltip = "Nothing to undo";
+    return;
+  }
+  try {
+    const paths = await GetTrashUndo();
+    button.dataset.tooltip = paths.length === 1
+      ? `Restore ${paths[0]}`
+      : `Restore ${paths.length.toLocaleString()} items moved to ${trashDestinationName()}`;
+  } catch (error) {
+    button.dataset.tooltip = "Undo move to Trash";
+  }
+}
+
+// undoMoveToTrash restores the most recent move to Trash made by the app.
+async function undoMoveToTrash() {
+  if (deletionInProgress || byId("undoTrashButton").disabled) return;
+  deletionInProgress = true;
+  hideContextMenu();
+  hideRectToast();
+  const dismissToast = showToastAt(mousePosition.x, mousePosition.y, "Restoring...", 30000);
+  try {
+    await waitForNextPaint();
+    const result = await UndoMoveToTrash();
+    dismissToast();
+    const failures = (result.items || []).filter(item => item.error);
+    if (await applyTreeChange(result) && !failures.length) {
+      showToastAt(mousePosition.x, mousePosition.y, "Restored", 1600);
+    }
+    if (failures.length) {
+      showErrorToast(`${failures.length.toLocaleString()} of ${result.items.length.toLocaleString()} items could not be restored. ${failures[0].path}: ${failures[0].error}`);
+    }
+  } catch (error) {
+    dismissToast();
+    showErrorToast(error);
+    updateUndoButton((await GetTrashUndo().catch(() => [])).length);
+  } finally {
+    dismissToast();
+    deletionInProgress = false;
+  }
+}
+
 function waitForNextPaint() {
   return new Promise(resolve => requestAnimationFrame(() => requestAnimationFrame(resolve)));
 }
@@ -128,35 +377,52 @@ async function confirmSelectedDeletion() {
   const actionText = target.action === "empty"
     ? `Emptying ${trashDestinationName()}...`
     : target.action === "permanent" ? "Deleting permanently..."
-      : target.action === "restore" ? "Restoring..." : `Moving to ${trashDestinationName()}...`;
+      : target.action === "restore" ? "Restoring..."
+        : target.action === "batch" ? `Moving ${target.count.toLocaleString()} items to ${trashDestinationName()}...`
+          : target.action === "basket" ? `Cleaning up ${target.count.toLocaleString()} items...`
+            : target.action === "trash-restore" || target.action === "quarantine-restore" ? `Restoring ${target.count.toLocaleString()} items...`
+              : target.action === "trash-delete" || target.action === "trash-purge" ? "Deleting permanently..."
+                : `Moving to ${trashDestinationName()}...`;
   const dismissMovingToast = showToastAt(mousePosition.x, mousePosition.y, actionText, 30000);
 
   try {
     await waitForNextPaint();
-    const result = target.action === "restore" ? await RestoreNode(target.nodeId) : await DeleteNode(target.nodeId);
+    const result = target.action === "restore" ? await RestoreNode(target.nodeId)
+      : target.action === "small-file" ? await DeleteSmallFile(target.nodeId, target.name)
+        : target.action === "batch" ? await DeleteNodes(target.nodeIds)
+          : target.action === "basket" ? await ExecuteCleanupBasket()
+            : target.action === "trash-restore" ? await RestoreTrashItems(target.paths)
+              : target.action === "quarantine-restore" ? await RestoreQuarantinedItems(target.ids)
+                : target.action === "trash-delete" ? await DeleteTrashItems(target.paths)
+                  : target.action === "trash-purge" ? await PurgeTrashOlderThan(target.days)
+                    : await DeleteNode(target.nodeId);
     dismissMovingToast();
-    AppState.selectedRectIndex = null;
-    AppState.selectedNodeId = null;
-    if (AppState.profile?.rescanOnDelete || result.rescanRequired) {
-      if (AppState.scanRootPath) byId("pathInput").value = AppState.scanRootPath;
-      await analyze();
-    } else {
-      AppState.fileCount = result.fileCount;
-      AppState.dirCount = result.dirCount;
-      trimInvalidForwardNavigation();
-      await redraw();
-      updateNavButtons();
+    const failures = (result.items || []).filter(item => item.error);
+    if (await applyTreeChange(result)) {
       const completedText = target.action === "empty"
         ? `${trashDestinationName()} emptied`
         : target.action === "permanent" ? "Permanently deleted"
-          : target.action === "restore" ? "Restored" : `Moved to ${trashDestinationName()}`;
-      showToastAt(mousePosition.x, mousePosition.y, completedText, 1600);
+          : target.action === "restore" ? "Restored"
+            : target.action === "batch" ? `Moved ${(result.items.length - failures.length).toLocaleString()} items to ${trashDestinationName()}`
+              : target.action === "basket" ? `Cleaned up ${(result.items.length - failures.length).toLocaleString()} items`
+                : target.action === "trash-restore" || target.action === "quarantine-restore" ? `Restored ${(result.items.length - failures.length).toLocaleString()} items`
+                  : target.action === "trash-delete" || target.action === "trash-purge" ? `Permanently deleted ${(result.items.length - failures.length).toLocaleString()} items`
+                    : `Moved to ${trashDestinationName()}`;
+      if (!failures.length) showToastAt(mousePosition.x, mousePosition.y, completedText, 1600);
+    }
+    if (failures.length) {
+      const failedText = target.action === "basket" ? "could not be cleaned up and stay in the basket"
+        : target.action === "trash-restore" || target.action === "quarantine-restore" ? "could not be restored"
+          : target.action === "trash-delete" || target.action === "trash-purge" ? "could not be deleted"
+            : `could not be moved to ${trashDestinationName()}`;
+      showErrorToast(`${failures.length.toLocaleString()} of ${result.items.length.toLocaleString()} items ${failedText}. ${failures[0].path}: ${failures[0].error}`);
     }
   } catch (error) {
     dismissMovingToast();
     showErrorToast(error);
   } finally {
     dismissMovingToast();
+    await target.onComplete?.();
     deletionInProgress = false;
     confirmButton.disabled = false;
     cancelButton.disabled = false;
@@ -200,14 +466,32 @@ export function showContextMenu(x, y) {
   const trashItem = !!rect?.is_in_trash && !rect?.is_trash_root;
   const restoreAction = menu.querySelector('[data-action="restore"]');
   if (restoreAction) restoreAction.hidden = !trashItem;
+  const nestedMount = !!rect?.mount && rect.parent_id != null && rect.node_id !== AppState.node_id;
+  const collapseMount = menu.querySelector('[data-action="collapse-mount"]');
+  if (collapseMount) {
+    collapseMount.hidden = !nestedMount;
+    collapseMount.querySelector("span").textContent = rect?.mount_collapsed ? "Expand mount" : "Collapse mount";
+  }
+  const excludeMount = menu.querySelector('[data-action="exclude-mount"]');
+  if (excludeMount) excludeMount.hidden = !nestedMount;
+  const basketAction = menu.querySelector('[data-action="basket"]');
+  if (basketAction) {
+    basketAction.classList.toggle("disabled", !rect?.full_path || isPassiveRect(rect) || rect.parent_id == null || !!rect.is_trash_root || !!rect.is_protected);
+    basketAction.querySelector("span").textContent = AppState.selectedNodeIds.size > 1
+      ? `Add ${AppState.selectedNodeIds.size.toLocaleString()} items to cleanup basket`
+      : "Add to cleanup basket";
+  }
+  const protectedItem = AppState.selectedNodeIds.size <= 1 && !!rect?.is_protected;
   if (deleteAction) {
-    deleteAction.classList.remove("disabled");
+    deleteAction.classList.toggle("disabled", protectedItem);
     deleteAction.classList.add("context-menu-delete");
   }
   if (deleteLabel) {
-    deleteLabel.textContent = rect?.is_trash_root
-      ? `Empty ${trashDestinationName()}`
-      : trashItem ? "Delete permanently" : "Delete";
+    deleteLabel.textContent = AppState.selectedNodeIds.size > 1
+      ? `Delete ${AppState.selectedNodeIds.size.toLocaleString()} items`
+      : protectedItem ? "Protected"
+        : rect?.is_trash_root ? `Empty ${trashDestinationName()}`
+          : trashItem ? "Delete permanently" : "Delete";
   }
   const defaultOpen = menu.querySelector('[data-action="open-default"]');
   const defaultOpenLabel = defaultOpen?.querySelector("span");
@@ -282,6 +566,34 @@ async function copySelectedPathAt(position) {
   showToastAt(point.x, point.y);
 }
 
+async function toggleMountCollapsed(rect) {
+  hideContextMenu();
+  try {
+    await SetMountCollapsed(rect.node_id, !rect.mount_collapsed);
+    await redraw();
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
+async function excludeMount(rect) {
+  hideContextMenu();
+  hideRectToast();
+  try {
+    const result = await ExcludeMount(rect.node_id);
+    AppState.selectedRectIndex = null;
+    AppState.selectedNodeId = null;
+    AppState.fileCount = result.fileCount;
+    AppState.dirCount = result.dirCount;
+    trimInvalidForwardNavigation();
+    await redraw();
+    updateNavButtons();
+    showToastAt(mousePosition.x, mousePosition.y, "Mount excluded from view", 1600);
+  } catch (error) {
+    showErrorToast(error);
+  }
+}
+
 async function handleContextMenuAction(event) {
   const item = event.target.closest("li");
   const rect = getSelectedRect();
@@ -292,6 +604,13 @@ async function handleContextMenuAction(event) {
     requestSelectedDeletion();
   } else if (item.dataset.action === "restore") {
     await requestSelectedRestore();
+  } else if (item.dataset.action === "basket" && !item.classList.contains("disabled")) {
+    hideContextMenu();
+    await addToCleanupBasket(AppState.selectedNodeIds.size > 1 ? [...AppState.selectedNodeIds] : [rect.node_id]);
+  } else if (item.dataset.action === "collapse-mount" && rect.mount) {
+    await toggleMountCollapsed(rect);
+  } else if (item.dataset.action === "exclude-mount" && rect.mount) {
+    await excludeMount(rect);
   } else if (item.dataset.action === "open" && rect.full_path) {
     await OpenInFileBrowser(rect.full_path);
   } else if (item.dataset.action === "open-default" && !rect.is_folder) {
@@ -319,6 +638,12 @@ export function initFileActions(options) {
     event.preventDefault();
     closeDeleteConfirmation();
   });
+  byId("closeSmallFilesButton").addEventListener("click", closeSmallFiles);
+  byId("smallFilesDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeSmallFiles();
+  });
+  byId("undoTrashButton").addEventListener("click", undoMoveToTrash);
   byId("contextMenu").addEventListener("click", handleContextMenuAction);
   window.addEventListener("click", hideContextMenu);
   const handleOpenShortcut = event => {
@@ -339,6 +664,10 @@ export function initFileActions(options) {
       event.preventDefault();
       copySelectedPathAt();
     }
+    if ((event.ctrlKey || event.metaKey) && !event.shiftKey && event.key.toLowerCase() === "z" && shortcutCanRun(event)) {
+      event.preventDefault();
+      undoMoveToTrash();
+    }
   });
   const handleDeleteShortcut = event => {
     if (!shortcutCanRun(event) || !eventMatchesShortcut(event, AppState.profile?.controls?.delete)) return;
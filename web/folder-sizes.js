@@ -0,0 +1,53 @@
+import { ExportFolderSizes } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { AppState } from "./state.js";
+import { mousePosition, showToastAt } from "./notifications.js";
+
+function showFolderSizes() {
+  if (AppState.node_id == null) return;
+  byId("folderSizesError").textContent = "";
+  const dialog = byId("folderSizesDialog");
+  if (!dialog.open) dialog.showModal();
+}
+
+function closeFolderSizes() {
+  const dialog = byId("folderSizesDialog");
+  if (dialog.open) dialog.close();
+}
+
+async function saveFolderSizes() {
+  const error = byId("folderSizesError");
+  error.textContent = "";
+  const depth = Math.round(Number(byId("folderSizesDepth").value));
+  const minimumMB = Number(byId("folderSizesMinSize").value);
+  if (!(depth >= 0) || !(minimumMB >= 0)) {
+    error.textContent = "Enter a depth and minimum size of 0 or more.";
+    return;
+  }
+  let path;
+  try {
+    path = await ExportFolderSizes({
+      nodeId: AppState.node_id,
+      format: byId("folderSizesFormat").value,
+      maxDepth: depth,
+      minSize: Math.round(minimumMB * 1024 * 1024),
+      includeFiles: byId("folderSizesIncludeFiles").checked,
+    });
+  } catch (saveError) {
+    error.textContent = String(saveError || "Unable to export folder sizes.");
+    return;
+  }
+  if (!path) return;
+  closeFolderSizes();
+  showToastAt(mousePosition.x, mousePosition.y, `Folder sizes saved to ${path}`, 2400);
+}
+
+export function initFolderSizes() {
+  byId("folderSizesButton").addEventListener("click", showFolderSizes);
+  byId("cancelFolderSizesButton").addEventListener("click", closeFolderSizes);
+  byId("saveFolderSizesButton").addEventListener("click", saveFolderSizes);
+  byId("folderSizesDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeFolderSizes();
+  });
+}
@@ -0,0 +1,44 @@
+import { ExportHTMLReport } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { AppState } from "./state.js";
+import { mousePosition, showToastAt } from "./notifications.js";
+
+function showHTMLReport() {
+  if (AppState.node_id == null) return;
+  byId("htmlReportError").textContent = "";
+  const dialog = byId("htmlReportDialog");
+  if (!dialog.open) dialog.showModal();
+}
+
+function closeHTMLReport() {
+  const dialog = byId("htmlReportDialog");
+  if (dialog.open) dialog.close();
+}
+
+async function saveHTMLReport() {
+  const error = byId("htmlReportError");
+  error.textContent = "";
+  let path;
+  try {
+    path = await ExportHTMLReport(AppState.node_id, {
+      depth: Math.round(Number(byId("htmlReportDepth").value)),
+      itemsPerFolder: Math.round(Number(byId("htmlReportItems").value)),
+    });
+  } catch (saveError) {
+    error.textContent = String(saveError || "Unable to save the report.");
+    return;
+  }
+  if (!path) return;
+  closeHTMLReport();
+  showToastAt(mousePosition.x, mousePosition.y, `Report saved to ${path}`, 2400);
+}
+
+export function initHTMLReport() {
+  byId("htmlReportButton").addEventListener("click", showHTMLReport);
+  byId("cancelHTMLReportButton").addEventListener("click", closeHTMLReport);
+  byId("saveHTMLReportButton").addEventListener("click", saveHTMLReport);
+  byId("htmlReportDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeHTMLReport();
+  });
+}
@@ -81,6 +81,9 @@ export function updateNavButtons() {
   byId("parentButton").disabled = !(AppState.rects?.length && AppState.rects[0].parent_id != null);
   byId("backwardButton").disabled = AppState.navIndex <= 0;
   byId("forwardButton").disabled = AppState.navIndex >= AppState.navHistory.length - 1;
+  byId("treemapImageButton").disabled = !AppState.rects?.length;
+  byId("htmlReportButton").disabled = !AppState.rects?.length;
+  byId("folderSizesButton").disabled = !AppState.rects?.length;
 }
 
 export function trimInvalidForwardNavigation() {
@@ -1,6 +1,6 @@
 import { GetAssociatedIcon } from "./wailsjs/go/main/App.js";
 import { byId } from "./dom.js";
-import { detailedByteSize, formatModTime } from "./format.js";
+import { detailedByteSize, formatCount, formatModTime, formatSize } from "./format.js";
 import { AppState } from "./state.js";
 
 let canvasCoords = null;
@@ -25,7 +25,19 @@ function cacheAssociatedIcon(key, value) {
 }
 
 function rectSupportsDetailsToast(rect) {
-  return !!(rect?.full_path && rect.parent_id != null && !rect.is_free_space);
+  return !!((rect?.full_path && rect.parent_id != null && !rect.is_free_space) || rect?.is_unaccounted);
+}
+
+function unaccountedBreakdown(rect) {
+  const reserved = Math.min(rect.size || 0, rect.reserved_space || 0);
+  const other = formatSize(Math.max(0, (rect.size || 0) - reserved));
+  const lines = rect.disk_path
+    ? [`Volume: ${rect.disk_path}`, `Reserved blocks: ${formatSize(reserved)}`, `Outside the scanned folder: ${other}`]
+    : [`Reserved blocks: ${formatSize(reserved)}`, `Other: ${other}`];
+  if (rect.scan_error_count) lines.push(`Scan errors: ${formatCount(rect.scan_error_count)}`);
+  if (rect.skipped_count) lines.push(`Skipped paths: ${formatCount(rect.skipped_count)}`);
+  if (!rect.disk_path) lines.push("Other space includes deleted files still held open and filesystem metadata");
+  return lines.join("\n");
 }
 
 function associatedIconKey(rect) {
@@ -106,6 +118,30 @@ export function hideRectToast() {
 function showRectToast(rect, rectIndex, clientX, clientY) {
   hoveredRect = rect;
   hoveredRectIndex = rectIndex;
+  const mount = byId("rectToastMount");
+  mount.hidden = !rect.mount;
+  if (rect.mount) {
+    const device = rect.mount.device ? ` (${rect.mount.device})` : "";
+    mount.textContent = `Mount: ${rect.mount.fsType || "unknown"} from ${rect.mount.source || "unknown source"}${device}`;
+  }
+  const trash = byId("rectToastTrash");
+  trash.hidden = !rect.trash_original_path && !rect.trash_deleted_at;
+  if (!trash.hidden) {
+    const origin = rect.trash_original_path ? `Deleted from ${rect.trash_original_path}` : "Deleted";
+    trash.textContent = rect.trash_deleted_at ? `${origin} on ${formatModTime(rect.trash_deleted_at)}` : origin;
+  }
+  if (rect.is_unaccounted) {
+    byId("rectToastPathPrefix").textContent = "";
+    byId("rectToastName").textContent = rect.name;
+    byId("rectToastSize").textContent = detailedByteSize(rect.size);
+    byId("rectToastCreated").textContent = unaccountedBreakdown(rect);
+    byId("rectToastCreated").classList.add("rect-toast-breakdown");
+    showFallbackIcon(true);
+    byId("rectToast").hidden = false;
+    placeRectToast(clientX, clientY);
+    return;
+  }
+  byId("rectToastCreated").classList.remove("rect-toast-breakdown");
   const name = String(rect.name || "");
   const fullPath = String(rect.full_path);
   const suffix = fullPath.slice(-name.length);
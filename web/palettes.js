@@ -0,0 +1,164 @@
+import { ExportPalettes, ImportPalettes } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { PALETTES, paletteColors } from "./state.js";
+
+// Custom palettes are edited as drafts on the Appearance tab and saved with
+// the rest of the settings. Their options are keyed by draft rather than by
+// name, so renaming keeps the selection.
+let drafts = [];
+let nextDraftKey = 0;
+let onChange = () => {};
+
+function newDraft(name, colors) {
+  return { key: `custom-${nextDraftKey++}`, name, colors: [...colors] };
+}
+
+function selectedDraft() {
+  return drafts.find(palette => palette.key === byId("settingsPalette").value);
+}
+
+function parseColors(text) {
+  return text.split(/[\s,;]+/).map(color => color.trim()).filter(Boolean);
+}
+
+function paletteOption(palette) {
+  const option = document.createElement("option");
+  option.value = palette.key || palette.id;
+  option.textContent = palette.name || "Unnamed palette";
+  return option;
+}
+
+function renderPaletteOptions(selected) {
+  const builtin = document.createElement("optgroup");
+  builtin.label = "Built-in";
+  builtin.append(...Array.from(PALETTES.values(), paletteOption));
+  const groups = [builtin];
+  if (drafts.length > 0) {
+    const custom = document.createElement("optgroup");
+    custom.label = "Custom";
+    custom.append(...drafts.map(paletteOption));
+    groups.push(custom);
+  }
+  const select = byId("settingsPalette");
+  select.replaceChildren(...groups);
+  select.value = selected;
+  if (select.value !== selected) select.value = "default";
+}
+
+function showSelectedPalette() {
+  const draft = selectedDraft();
+  byId("settingsCustomPalette").hidden = !draft;
+  byId("removePaletteButton").disabled = !draft;
+  if (draft) {
+    byId("settingsPaletteName").value = draft.name;
+    byId("settingsPaletteColors").value = draft.colors.join(" ");
+  }
+  onChange();
+}
+
+function uniqueName(base) {
+  const taken = new Set([...PALETTES.values(), ...drafts].flatMap(palette => [palette.id, palette.name].filter(Boolean).map(name => name.toLowerCase())));
+  let name = base;
+  for (let index = 2; taken.has(name.toLowerCase()); index++) name = `${base} ${index}`;
+  return name;
+}
+
+function addPalette() {
+  const draft = newDraft(uniqueName("Custom palette"), selectedPaletteColors());
+  drafts.push(draft);
+  renderPaletteOptions(draft.key);
+  showSelectedPalette();
+  byId("settingsPaletteName").focus();
+}
+
+function removePalette() {
+  const draft = selectedDraft();
+  if (!draft) return;
+  drafts = drafts.filter(palette => palette !== draft);
+  renderPaletteOptions("default");
+  showSelectedPalette();
+}
+
+function renamePalette() {
+  const draft = selectedDraft();
+  if (!draft) return;
+  draft.name = byId("settingsPaletteName").value.trim();
+  renderPaletteOptions(draft.key);
+}
+
+function recolorPalette() {
+  const draft = selectedDraft();
+  if (!draft) return;
+  draft.colors = parseColors(byId("settingsPaletteColors").value);
+  onChange();
+}
+
+async function importPalettes() {
+  const error = byId("settingsError");
+  error.textContent = "";
+  let imported;
+  try {
+    imported = await ImportPalettes();
+  } catch (importError) {
+    error.textContent = String(importError || "Unable to import palettes.");
+    return;
+  }
+  if (!imported?.length) return;
+  let first = null;
+  for (const palette of imported) {
+    let draft = drafts.find(existing => existing.name.toLowerCase() === palette.name.toLowerCase());
+    if (draft) draft.colors = [...palette.colors];
+    else drafts.push(draft = newDraft(palette.name, palette.colors));
+    first ||= draft;
+  }
+  renderPaletteOptions(first.key);
+  showSelectedPalette();
+}
+
+async function exportPalettes() {
+  const error = byId("settingsError");
+  error.textContent = "";
+  try {
+    await ExportPalettes(collectCustomPalettes());
+  } catch (exportError) {
+    error.textContent = String(exportError || "Unable to export palettes.");
+  }
+}
+
+// selectedPaletteColors returns the colors of the palette chosen in the
+// form, including unsaved custom palettes.
+export function selectedPaletteColors() {
+  const draft = selectedDraft();
+  return draft ? paletteColors(draft.key, [{ id: draft.key, colors: draft.colors }]) : paletteColors(byId("settingsPalette").value, []);
+}
+
+// selectedPaletteId returns the ID AppearanceSettings.Palette stores for the
+// chosen palette; custom palettes are identified by name.
+export function selectedPaletteId() {
+  return selectedDraft()?.name ?? byId("settingsPalette").value;
+}
+
+// populatePalettes loads the custom palettes of appearance into the form and
+// selects its palette.
+export function populatePalettes(appearance) {
+  drafts = (appearance.customPalettes || []).map(palette => newDraft(palette.name, palette.colors));
+  renderPaletteOptions(drafts.find(draft => draft.name === appearance.palette)?.key || appearance.palette);
+  showSelectedPalette();
+}
+
+export function collectCustomPalettes() {
+  return drafts.map(palette => ({ id: palette.name, name: palette.name, colors: palette.colors }));
+}
+
+// initPalettes wires the palette controls. options.onChange runs whenever
+// the selected palette or its colors change.
+export function initPalettes(options) {
+  onChange = options.onChange;
+  byId("settingsPalette").addEventListener("change", showSelectedPalette);
+  byId("addPaletteButton").addEventListener("click", addPalette);
+  byId("removePaletteButton").addEventListener("click", removePalette);
+  byId("settingsPaletteName").addEventListener("input", renamePalette);
+  byId("settingsPaletteColors").addEventListener("input", recolorPalette);
+  byId("importPalettesButton").addEventListener("click", importPalettes);
+  byId("exportPalettesButton").addEventListener("click", exportPalettes);
+}
@@ -0,0 +1,113 @@
+import { GetQuarantine } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { formatModTime, formatSize } from "./format.js";
+import { showErrorToast } from "./notifications.js";
+import { AppState } from "./state.js";
+
+let requestRestore = () => {};
+let listedItems = [];
+const selectedIds = new Set();
+
+function selectedItems() {
+  return listedItems.filter(item => selectedIds.has(item.id));
+}
+
+function totalSize(items) {
+  return items.reduce((total, item) => total + item.size, 0);
+}
+
+function updateQuarantineControls() {
+  const selected = selectedItems();
+  byId("restoreQuarantineButton").disabled = selected.length === 0;
+  const count = listedItems.length;
+  let summary = count
+    ? `${count.toLocaleString()} ${count === 1 ? "item" : "items"}, ${formatSize(totalSize(listedItems))} in ${AppState.profile?.quarantineDir || "quarantine"}`
+    : "Quarantine is empty";
+  if (selected.length) summary += `\n${selected.length.toLocaleString()} selected, ${formatSize(totalSize(selected))}`;
+  byId("quarantineSummary").textContent = summary;
+}
+
+function quarantineListItem(item) {
+  const row = document.createElement("li");
+  row.className = "trash-browser-row";
+  const checkbox = document.createElement("input");
+  checkbox.type = "checkbox";
+  checkbox.checked = selectedIds.has(item.id);
+  checkbox.addEventListener("change", () => {
+    if (checkbox.checked) selectedIds.add(item.id);
+    else selectedIds.delete(item.id);
+    updateQuarantineControls();
+  });
+  const name = document.createElement("span");
+  name.className = "small-files-name";
+  name.textContent = item.originalPath;
+  name.title = [
+    item.originalPath,
+    item.owner ? `Owner: ${item.owner}` : "",
+    `Quarantined: ${formatModTime(Date.parse(item.quarantinedAt) / 1000)}`,
+    item.expiresAt ? `Removed after: ${formatModTime(item.expiresAt)}` : "Kept until restored",
+  ].filter(Boolean).join("\n");
+  const size = document.createElement("span");
+  size.className = "small-files-size";
+  size.textContent = formatSize(item.size);
+  const date = document.createElement("span");
+  date.className = "small-files-date";
+  date.textContent = formatModTime(Date.parse(item.quarantinedAt) / 1000);
+  row.append(checkbox, name, size, date);
+  return row;
+}
+
+function renderQuarantine(items) {
+  listedItems = items || [];
+  const listed = new Set(listedItems.map(item => item.id));
+  for (const id of selectedIds) {
+    if (!listed.has(id)) selectedIds.delete(id);
+  }
+  byId("quarantineList").replaceChildren(...listedItems.map(quarantineListItem));
+  byId("quarantineList").hidden = listedItems.length === 0;
+  updateQuarantineControls();
+}
+
+async function showQuarantine() {
+  const dialog = byId("quarantineDialog");
+  if (!dialog.open) dialog.showModal();
+  byId("quarantineSummary").textContent = "Reading quarantine...";
+  try {
+    renderQuarantine(await GetQuarantine());
+  } catch (error) {
+    renderQuarantine([]);
+    showErrorToast(error);
+  }
+}
+
+function closeQuarantine() {
+  const dialog = byId("quarantineDialog");
+  if (dialog.open) dialog.close();
+}
+
+// updateQuarantineButton shows the toolbar button only while the quarantine
+// delete strategy is selected.
+export function updateQuarantineButton() {
+  byId("quarantineButton").hidden = AppState.profile?.deleteStrategy !== "quarantine";
+}
+
+export function initQuarantine(options) {
+  requestRestore = options.requestRestore;
+  byId("quarantineButton").addEventListener("click", showQuarantine);
+  byId("closeQuarantineButton").addEventListener("click", closeQuarantine);
+  byId("quarantineDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeQuarantine();
+  });
+  byId("restoreQuarantineButton").addEventListener("click", () => {
+    const items = selectedItems();
+    if (!items.length) return;
+    closeQuarantine();
+    requestRestore({
+      ids: items.map(item => item.id),
+      paths: items.map(item => item.originalPath),
+      size: totalSize(items),
+    }, showQuarantine);
+  });
+  updateQuarantineButton();
+}
@@ -0,0 +1,137 @@
+import { byId } from "./dom.js";
+import { SIZE_UNITS, splitSizeIntoUnit } from "./format.js";
+
+// The scan settings on the General tab edit one scan profile at a time.
+// Entry 0 is the default profile stored directly in the settings; the others
+// are the named profiles bound to roots.
+let drafts = [];
+let selectedIndex = 0;
+
+function splitLines(text) {
+  return text.split(/\r?\n/).map(line => line.trim()).filter(Boolean);
+}
+
+function draftFromProfile(profile, name) {
+  const threshold = splitSizeIntoUnit(profile.minFileSize ?? 0);
+  return {
+    name,
+    roots: (profile.roots || []).join("\n"),
+    excludedPaths: (profile.excludedPaths || []).join("\n"),
+    minFileSize: String(threshold.value),
+    minFileSizeUnit: threshold.unit,
+    skipHidden: !!profile.skipHidden,
+    followSymlinks: !!profile.followSymlinks,
+    keepSmallFileDetails: !!profile.keepSmallFileDetails,
+    skipNetworkFS: !!profile.skipNetworkFS,
+  };
+}
+
+function storeSelectedDraft() {
+  const draft = drafts[selectedIndex];
+  if (!draft) return;
+  if (selectedIndex > 0) {
+    draft.name = byId("settingsScanProfileName").value.trim();
+    draft.roots = byId("settingsScanProfileRoots").value;
+  }
+  draft.excludedPaths = byId("settingsExcludedPaths").value;
+  draft.minFileSize = byId("settingsMinFileSize").value;
+  draft.minFileSizeUnit = byId("settingsMinFileSizeUnit").value;
+  draft.skipHidden = byId("settingsSkipHidden").checked;
+  draft.followSymlinks = byId("settingsFollowSymlinks").checked;
+  draft.keepSmallFileDetails = byId("settingsKeepSmallFileDetails").checked;
+  draft.skipNetworkFS = byId("settingsSkipNetworkFS").checked;
+}
+
+function showSelectedDraft() {
+  const draft = drafts[selectedIndex];
+  const named = selectedIndex > 0;
+  byId("settingsScanProfileName").value = named ? draft.name : "";
+  byId("settingsScanProfileRoots").value = named ? draft.roots : "";
+  byId("settingsScanProfileBinding").hidden = !named;
+  byId("removeScanProfileButton").disabled = !named;
+  byId("settingsExcludedPaths").value = draft.excludedPaths;
+  byId("settingsMinFileSize").value = draft.minFileSize;
+  byId("settingsMinFileSizeUnit").value = draft.minFileSizeUnit;
+  byId("settingsSkipHidden").checked = draft.skipHidden;
+  byId("settingsFollowSymlinks").checked = draft.followSymlinks;
+  byId("settingsKeepSmallFileDetails").checked = draft.keepSmallFileDetails;
+  byId("settingsSkipNetworkFS").checked = draft.skipNetworkFS;
+}
+
+function renderScanProfileOptions() {
+  const select = byId("settingsScanProfile");
+  select.replaceChildren(...drafts.map((draft, index) => {
+    const option = document.createElement("option");
+    option.value = String(index);
+    option.textContent = index === 0 ? "Default" : draft.name || "Unnamed profile";
+    return option;
+  }));
+  select.value = String(selectedIndex);
+}
+
+function selectScanProfile(index) {
+  storeSelectedDraft();
+  selectedIndex = index;
+  renderScanProfileOptions();
+  showSelectedDraft();
+}
+
+// populateScanProfiles loads the default and named scan profiles of profile
+// into the General tab, showing the default one.
+export function populateScanProfiles(profile) {
+  drafts = [
+    draftFromProfile(profile, "Default"),
+    ...(profile.scanProfiles || []).map(scan => draftFromProfile(scan, scan.name)),
+  ];
+  selectedIndex = 0;
+  renderScanProfileOptions();
+  showSelectedDraft();
+}
+
+// collectScanProfiles returns the edited default scan settings and named
+// scan profiles, or an error message when a threshold is invalid.
+export function collectScanProfiles() {
+  storeSelectedDraft();
+  const collected = [];
+  for (const draft of drafts) {
+    const sizeValue = Number(draft.minFileSize);
+    const minFileSize = sizeValue * SIZE_UNITS[draft.minFileSizeUnit];
+    if (draft.minFileSize === "" || !Number.isFinite(sizeValue) || sizeValue < 0 || !Number.isSafeInteger(minFileSize)) {
+      return { error: `Small-file threshold of the ${draft.name || "unnamed"} scan profile must resolve to a non-negative whole number of bytes.` };
+    }
+    collected.push({
+      name: draft.name,
+      roots: splitLines(draft.roots),
+      excludedPaths: splitLines(draft.excludedPaths),
+      skipHidden: draft.skipHidden,
+      minFileSize,
+      followSymlinks: draft.followSymlinks,
+      keepSmallFileDetails: draft.keepSmallFileDetails,
+      skipNetworkFS: draft.skipNetworkFS,
+    });
+  }
+  const [defaults, ...scanProfiles] = collected;
+  return { defaults, scanProfiles };
+}
+
+export function initScanProfiles() {
+  byId("settingsScanProfile").addEventListener("change", event => selectScanProfile(Number(event.target.value)));
+  byId("settingsScanProfileName").addEventListener("input", () => {
+    drafts[selectedIndex].name = byId("settingsScanProfileName").value.trim();
+    renderScanProfileOptions();
+  });
+  byId("addScanProfileButton").addEventListener("click", () => {
+    storeSelectedDraft();
+    const draft = { ...drafts[0], name: `Profile ${drafts.length}`, roots: "" };
+    drafts.push(draft);
+    selectScanProfile(drafts.length - 1);
+    byId("settingsScanProfileName").focus();
+  });
+  byId("removeScanProfileButton").addEventListener("click", () => {
+    if (selectedIndex === 0) return;
+    drafts.splice(selectedIndex, 1);
+    selectedIndex = 0;
+    renderScanProfileOptions();
+    showSelectedDraft();
+  });
+}
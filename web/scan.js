@@ -50,6 +50,7 @@ function clearTreemapForScan() {
   replaceBrowserHistoryEntry(null, -1);
   AppState.selectedRectIndex = null;
   AppState.selectedNodeId = null;
+  AppState.selectedNodeIds.clear();
   hideContextMenu();
   updateNavButtons();
 }
@@ -59,6 +60,15 @@ function clearScanWarning() {
   byId("scanWarningIndicator").hidden = true;
 }
 
+// showScanProfile names the scan profile of the displayed tree when it is
+// not the default one.
+function showScanProfile(name) {
+  const badge = byId("scanProfileBadge");
+  badge.hidden = !name || name === "Default";
+  badge.textContent = name || "";
+  badge.dataset.tooltip = `Scanned with the ${name} scan profile`;
+}
+
 function showScanWarning(report) {
   const errorCount = Number(report?.errorCount || 0);
   if (errorCount <= 0) {
@@ -199,7 +209,7 @@ export async function analyze() {
     startScanProgress(canonicalPath);
     scanStarted = true;
 
-    const { rootId, fileCount, dirCount, scanReport } = await GetFullTree(canonicalPath);
+    const { rootId, fileCount, dirCount, scanProfile, scanReport } = await GetFullTree(canonicalPath);
     await completeScanProgress(fileCount, dirCount);
     scanStarted = false;
 
@@ -212,7 +222,9 @@ export async function analyze() {
     replaceBrowserHistoryEntry(rootId, 0);
     AppState.selectedRectIndex = null;
     AppState.selectedNodeId = null;
+    AppState.selectedNodeIds.clear();
     showScanWarning(scanReport);
+    showScanProfile(scanProfile);
     await redraw();
   } catch (error) {
     logError("analyze failed:", error);
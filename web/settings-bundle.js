@@ -0,0 +1,160 @@
+import {
+  ExportSettingsBundle,
+  ImportSettingsBundle,
+  PickSettingsBundle,
+  PreviewSettingsBundle,
+} from "./wailsjs/go/main/App.js";
+import { byId, queryAll } from "./dom.js";
+import { showToastAt } from "./notifications.js";
+
+const sectionLabels = {
+  appearance: "Appearance",
+  controls: "Controls",
+  exclusions: "Exclusions",
+  profiles: "Scan profiles",
+};
+
+let bundlePath = "";
+let previewTimer = 0;
+let onImported = async () => {};
+
+function selectedSections() {
+  return Array.from(queryAll("[data-bundle-section]")).filter(input => input.checked).map(input => input.dataset.bundleSection);
+}
+
+// parseMappings reads "from => to" lines, ignoring incomplete ones.
+function parseMappings(text) {
+  return text.split(/\r?\n/)
+    .map(line => line.split("=>").map(part => part.trim()))
+    .filter(parts => parts.length === 2 && parts[0] && parts[1])
+    .map(([from, to]) => ({ from, to }));
+}
+
+function importRequest() {
+  return {
+    path: bundlePath,
+    sections: selectedSections(),
+    pathMappings: parseMappings(byId("settingsBundleMappings").value),
+  };
+}
+
+function changeListItem(change) {
+  const row = document.createElement("li");
+  row.className = "settings-bundle-change";
+  const setting = document.createElement("span");
+  setting.className = "small-files-name";
+  setting.textContent = `${sectionLabels[change.section] || change.section}: ${change.setting}`;
+  const before = document.createElement("span");
+  before.className = "small-files-name small-files-size";
+  before.textContent = change.before || "(none)";
+  before.title = change.before;
+  const after = document.createElement("span");
+  after.className = "small-files-name";
+  after.textContent = change.after || "(removed)";
+  after.title = change.after;
+  row.append(setting, before, after);
+  return row;
+}
+
+async function refreshPreview() {
+  const error = byId("settingsBundleError");
+  const apply = byId("applySettingsBundleButton");
+  error.textContent = "";
+  apply.disabled = true;
+  let preview;
+  try {
+    preview = await PreviewSettingsBundle(importRequest());
+  } catch (previewError) {
+    byId("settingsBundleChanges").replaceChildren();
+    byId("settingsBundleChanges").hidden = true;
+    error.textContent = String(previewError || "Unable to read the settings bundle.");
+    return;
+  }
+  const changes = preview.changes || [];
+  const exported = preview.exportedAt ? new Date(preview.exportedAt).toLocaleString() : "an unknown date";
+  byId("settingsBundleSummary").textContent = changes.length
+    ? `Exported on ${preview.platform || "another system"}, ${exported}. ${changes.length} ${changes.length === 1 ? "change" : "changes"}:`
+    : `Exported on ${preview.platform || "another system"}, ${exported}. Nothing would change.`;
+  byId("settingsBundleChanges").replaceChildren(...changes.map(changeListItem));
+  byId("settingsBundleChanges").hidden = changes.length === 0;
+  apply.disabled = changes.length === 0;
+}
+
+function schedulePreview() {
+  clearTimeout(previewTimer);
+  previewTimer = setTimeout(refreshPreview, 250);
+}
+
+async function exportBundle() {
+  const error = byId("settingsError");
+  error.textContent = "";
+  const sections = selectedSections();
+  if (sections.length === 0) {
+    error.textContent = "Select at least one section to export.";
+    return;
+  }
+  try {
+    const path = await ExportSettingsBundle(sections);
+    if (!path) return;
+    const button = byId("exportSettingsBundleButton").getBoundingClientRect();
+    showToastAt(button.left, button.bottom, "Settings exported", 1600);
+  } catch (exportError) {
+    error.textContent = String(exportError || "Unable to export settings.");
+  }
+}
+
+async function importBundle() {
+  const error = byId("settingsError");
+  error.textContent = "";
+  if (selectedSections().length === 0) {
+    error.textContent = "Select at least one section to import.";
+    return;
+  }
+  try {
+    bundlePath = await PickSettingsBundle();
+  } catch (pickError) {
+    error.textContent = String(pickError || "Unable to choose a settings bundle.");
+    return;
+  }
+  if (!bundlePath) return;
+  byId("settingsBundleSummary").textContent = bundlePath;
+  byId("settingsBundleMappings").value = "";
+  byId("settingsBundleDialog").showModal();
+  await refreshPreview();
+}
+
+function closeBundleDialog() {
+  clearTimeout(previewTimer);
+  const dialog = byId("settingsBundleDialog");
+  if (dialog.open) dialog.close();
+  bundlePath = "";
+}
+
+async function applyBundle() {
+  const apply = byId("applySettingsBundleButton");
+  apply.disabled = true;
+  try {
+    await ImportSettingsBundle(importRequest());
+  } catch (importError) {
+    byId("settingsBundleError").textContent = String(importError || "Unable to import settings.");
+    apply.disabled = false;
+    return;
+  }
+  closeBundleDialog();
+  await onImported();
+}
+
+// initSettingsBundle wires the export and import controls of the Misc tab.
+// options.onImported runs after an import has been saved.
+export function initSettingsBundle(options) {
+  onImported = options.onImported;
+  byId("exportSettingsBundleButton").addEventListener("click", exportBundle);
+  byId("importSettingsBundleButton").addEventListener("click", importBundle);
+  byId("settingsBundleMappings").addEventListener("input", schedulePreview);
+  byId("cancelSettingsBundleButton").addEventListener("click", closeBundleDialog);
+  byId("applySettingsBundleButton").addEventListener("click", applyBundle);
+  byId("settingsBundleDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeBundleDialog();
+  });
+}
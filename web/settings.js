@@ -1,16 +1,23 @@
 import {
+  GetBuiltinProtectedPaths,
   GetDefaultProfile,
   GetDefaultSettingsPath,
+  GetPalettes,
   GetProfile,
   GetSettingsPath,
   PickSettingsPath,
   SetProfile,
   SetSettingsPath,
 } from "./wailsjs/go/main/App.js";
+import { EventsOn } from "./wailsjs/runtime/runtime.js";
 import { byId, queryAll } from "./dom.js";
-import { SIZE_UNITS, splitSizeIntoUnit } from "./format.js";
 import { addControlEventListeners, shortcutFromEvent } from "./controls.js";
 import { logError } from "./logging.js";
+import { showErrorToast, showToastAt } from "./notifications.js";
+import { updateQuarantineButton } from "./quarantine.js";
+import { collectCustomPalettes, initPalettes, populatePalettes, selectedPaletteColors, selectedPaletteId } from "./palettes.js";
+import { collectScanProfiles, initScanProfiles, populateScanProfiles } from "./scan-profiles.js";
+import { initSettingsBundle } from "./settings-bundle.js";
 import {
   AppState,
   AppearanceState,
@@ -18,6 +25,7 @@ import {
   SCALE_MAX,
   SCALE_MIN,
   getScale,
+  setBuiltinPalettes,
   setProfiles,
 } from "./state.js";
 
@@ -53,7 +61,9 @@ function defaultAppearance() {
 export function normalizedAppearance(appearance) {
   const defaults = defaultAppearance();
   const source = appearance || defaults;
-  const palette = PALETTES[source.palette] ? source.palette : defaults.palette;
+  const customPalettes = Array.isArray(source.customPalettes) ? source.customPalettes : [];
+  const known = PALETTES.has(source.palette) || customPalettes.some(custom => custom.id === source.palette);
+  const palette = known ? source.palette : defaults.palette;
   const zoom = Number(source.zoomFactor);
   const relief = Number(source.reliefStrength);
   const hoverBrightness = Number(source.hoverBrightness);
@@ -64,6 +74,8 @@ export function normalizedAppearance(appearance) {
     reliefStrength: Math.max(0, Math.min(0.5, Number.isFinite(relief) ? relief : defaults.reliefStrength)),
     hoverBrightness: Math.max(0, Math.min(0.3, Number.isFinite(hoverBrightness) ? hoverBrightness : defaults.hoverBrightness)),
     rollOverBoxes: !!source.rollOverBoxes,
+    colorTrashByAge: !!source.colorTrashByAge,
+    customPalettes,
   };
 }
 
@@ -144,8 +156,8 @@ function clearControlBinding(button) {
   byId("settingsError").textContent = "";
 }
 
-function updatePalettePreview(paletteName) {
-  byId("settingsPalettePreview").replaceChildren(...(PALETTES[paletteName] || PALETTES.default).map(color => {
+function updatePalettePreview() {
+  byId("settingsPalettePreview").replaceChildren(...selectedPaletteColors().map(color => {
     const swatch = document.createElement("span");
     swatch.style.backgroundColor = color;
     return swatch;
@@ -153,12 +165,11 @@ function updatePalettePreview(paletteName) {
 }
 
 function updateAppearanceFormOutputs() {
-  const palette = byId("settingsPalette").value;
   const zoom = Number(byId("settingsZoomFactor").value);
   const radius = Number(byId("settingsCornerRadius").value);
   const relief = Number(byId("settingsReliefStrength").value);
   const hoverBrightness = Number(byId("settingsHoverBrightness").value);
-  updatePalettePreview(palette);
+  updatePalettePreview();
   byId("settingsZoomFactorValue").textContent = `${zoom.toFixed(1)}×`;
   byId("settingsCornerRadiusValue").textContent = `${radius.toFixed(0)} px`;
   byId("settingsReliefStrengthValue").textContent = `${(1 + relief).toFixed(2)}×`;
@@ -167,29 +178,67 @@ function updateAppearanceFormOutputs() {
 
 function populateAppearanceForm(appearance, useCurrentZoom = true) {
   const values = normalizedAppearance(appearance);
-  byId("settingsPalette").value = values.palette;
   byId("settingsZoomFactor").value = String(useCurrentZoom ? (AppState.zoomFactor || values.zoomFactor) : values.zoomFactor);
   byId("settingsCornerRadius").value = String(values.cornerRadius);
   byId("settingsReliefStrength").value = String(values.reliefStrength);
   byId("settingsHoverBrightness").value = String(values.hoverBrightness);
   byId("settingsRollOverBoxes").checked = values.rollOverBoxes;
-  updateAppearanceFormOutputs();
+  byId("settingsColorTrashByAge").checked = values.colorTrashByAge;
+  populatePalettes(values);
 }
 
 function populateGeneralForm(profile) {
   byId("settingsPlatform").textContent = profile.platformSystem || "";
-  byId("settingsExcludedPaths").value = (profile.excludedPaths || []).join("\n");
-  const threshold = splitSizeIntoUnit(profile.minFileSize ?? 0);
-  byId("settingsMinFileSize").value = String(threshold.value);
-  byId("settingsMinFileSizeUnit").value = threshold.unit;
-  byId("settingsSkipHidden").checked = !!profile.skipHidden;
-  byId("settingsFollowSymlinks").checked = !!profile.followSymlinks;
-  byId("settingsSkipNetworkFS").checked = !!profile.skipNetworkFS;
+  populateScanProfiles(profile);
+  byId("settingsShowVolumeContext").checked = !!profile.showVolumeContext;
   byId("settingsShowTooltips").checked = profile.showTooltips !== false;
   byId("settingsTooltipDelay").value = String(profile.tooltipDelayMs ?? 0);
   byId("settingsAllowDelete").checked = !!profile.allowDelete;
   byId("settingsAllowPermanentDelete").checked = !!profile.allowPermanentDelete;
   byId("settingsRescanOnDelete").checked = !!profile.rescanOnDelete;
+  byId("settingsRequireAuditLog").checked = !!profile.requireAuditLog;
+  byId("settingsProtectedPaths").value = (profile.protectedPaths || []).join("\n");
+  byId("settingsDeleteStrategy").value = profile.deleteStrategy || "trash";
+  byId("settingsQuarantineDir").value = profile.quarantineDir || "";
+  const retentionDays = profile.policy?.quarantineRetentionDays || 0;
+  byId("settingsQuarantineRetention").textContent = retentionDays > 0
+    ? `The system policy removes quarantined items after ${retentionDays} days.`
+    : "Quarantined items are kept until they are restored; the system policy can set a retention period.";
+  updateQuarantineFields();
+  applyPolicyLocks(profile.policy);
+}
+
+const POLICY_FIELD_INPUTS = Object.freeze({
+  allowDelete: "settingsAllowDelete",
+  allowPermanentDelete: "settingsAllowPermanentDelete",
+  skipNetworkFS: "settingsSkipNetworkFS",
+  excludedPaths: "settingsExcludedPaths",
+  protectedPaths: "settingsProtectedPaths",
+});
+
+// applyPolicyLocks disables the inputs of settings locked by the system
+// policy and lists the paths it requires.
+function applyPolicyLocks(policy) {
+  const locked = new Set(policy?.lockedFields || []);
+  for (const [field, id] of Object.entries(POLICY_FIELD_INPUTS)) {
+    const input = byId(id);
+    input.disabled = locked.has(field);
+    input.title = locked.has(field) ? "Managed by the system policy" : "";
+  }
+  const note = byId("settingsPolicyNote");
+  note.hidden = !policy;
+  if (!policy) return;
+  note.textContent = [
+    `Some settings are managed by the system policy at ${policy.path}.`,
+    policy.excludedPaths?.length ? `Always excluded: ${policy.excludedPaths.join(", ")}.` : "",
+    policy.protectedPaths?.length ? `Always protected: ${policy.protectedPaths.join(", ")}.` : "",
+  ].filter(Boolean).join(" ");
+}
+
+function updateQuarantineFields() {
+  const quarantine = byId("settingsDeleteStrategy").value === "quarantine";
+  byId("settingsQuarantineDir").disabled = !quarantine;
+  byId("settingsQuarantineRetention").hidden = !quarantine;
 }
 
 function populateProfileForm(profile, useCurrentZoom = true) {
@@ -212,8 +261,10 @@ async function ensureDefaultProfile() {
 }
 
 export async function loadSettingsState() {
-  const [defaultProfile, profile] = await Promise.all([GetDefaultProfile(), GetProfile()]);
+  const [defaultProfile, profile, palettes] = await Promise.all([GetDefaultProfile(), GetProfile(), GetPalettes()]);
+  setBuiltinPalettes(palettes);
   setProfiles(profile, defaultProfile);
+  updateQuarantineButton();
   await applyAppearance(profile.appearance, false);
 }
 
@@ -223,14 +274,16 @@ async function openSettings() {
 
   byId("settingsError").textContent = "";
   try {
-    const [profile, , settingsPath, defaultPath] = await Promise.all([
+    const [profile, , settingsPath, defaultPath, builtinProtectedPaths] = await Promise.all([
       GetProfile(),
       ensureDefaultProfile(),
       GetSettingsPath(),
       GetDefaultSettingsPath(),
+      GetBuiltinProtectedPaths(),
     ]);
     AppState.profile = profile;
     populateProfileForm(profile);
+    byId("settingsBuiltinProtectedPaths").textContent = `Folders inside these paths are protected too. Always protected: ${(builtinProtectedPaths || []).join(", ")}`;
     populateMiscForm(settingsPath, defaultPath);
     showSettingsTab("general");
     dialog.showModal();
@@ -290,13 +343,11 @@ async function saveSettings(event) {
   stopControlBindingCapture();
   const dialog = byId("settingsDialog");
   const error = byId("settingsError");
-  const sizeValue = byId("settingsMinFileSize").valueAsNumber;
-  const sizeUnit = byId("settingsMinFileSizeUnit").value;
-  const minFileSize = sizeValue * SIZE_UNITS[sizeUnit];
+  const scan = collectScanProfiles();
   const tooltipDelayMs = byId("settingsTooltipDelay").valueAsNumber;
 
-  if (!Number.isFinite(sizeValue) || sizeValue < 0 || !Number.isSafeInteger(minFileSize)) {
-    error.textContent = "Small-file threshold must resolve to a non-negative whole number of bytes.";
+  if (scan.error) {
+    error.textContent = scan.error;
     return;
   }
   if (!Number.isInteger(tooltipDelayMs) || tooltipDelayMs < 0 || tooltipDelayMs > 1000) {
@@ -306,23 +357,32 @@ async function saveSettings(event) {
 
   const profile = {
     platformSystem: byId("settingsPlatform").textContent,
-    excludedPaths: byId("settingsExcludedPaths").value.split(/\r?\n/).map(path => path.trim()).filter(Boolean),
-    skipHidden: byId("settingsSkipHidden").checked,
-    minFileSize,
-    followSymlinks: byId("settingsFollowSymlinks").checked,
-    skipNetworkFS: byId("settingsSkipNetworkFS").checked,
+    excludedPaths: scan.defaults.excludedPaths,
+    skipHidden: scan.defaults.skipHidden,
+    minFileSize: scan.defaults.minFileSize,
+    followSymlinks: scan.defaults.followSymlinks,
+    keepSmallFileDetails: scan.defaults.keepSmallFileDetails,
+    skipNetworkFS: scan.defaults.skipNetworkFS,
+    scanProfiles: scan.scanProfiles,
+    showVolumeContext: byId("settingsShowVolumeContext").checked,
     showTooltips: byId("settingsShowTooltips").checked,
     tooltipDelayMs,
     allowDelete: byId("settingsAllowDelete").checked,
     allowPermanentDelete: byId("settingsAllowPermanentDelete").checked,
     rescanOnDelete: byId("settingsRescanOnDelete").checked,
+    requireAuditLog: byId("settingsRequireAuditLog").checked,
+    protectedPaths: byId("settingsProtectedPaths").value.split(/\r?\n/).map(path => path.trim()).filter(Boolean),
+    deleteStrategy: byId("settingsDeleteStrategy").value,
+    quarantineDir: byId("settingsQuarantineDir").value.trim(),
     appearance: {
-      palette: byId("settingsPalette").value,
+      palette: selectedPaletteId(),
       zoomFactor: Number(byId("settingsZoomFactor").value),
       cornerRadius: Number(byId("settingsCornerRadius").value),
       reliefStrength: Number(byId("settingsReliefStrength").value),
       hoverBrightness: Number(byId("settingsHoverBrightness").value),
       rollOverBoxes: byId("settingsRollOverBoxes").checked,
+      colorTrashByAge: byId("settingsColorTrashByAge").checked,
+      customPalettes: collectCustomPalettes(),
     },
     controls: normalizedControlBindings(draftControlBindings),
   };
@@ -338,6 +398,7 @@ async function saveSettings(event) {
       activeSettingsPath = requestedSettingsPath;
     }
     AppState.profile = profile;
+    updateQuarantineButton();
     dialog.close();
     await applyAppearance(profile.appearance);
   } catch (saveError) {
@@ -361,6 +422,39 @@ async function browseConfigPath() {
   }
 }
 
+// reloadImportedSettings shows settings an import has saved, replacing any
+// unsaved edits in the form.
+async function reloadImportedSettings() {
+  try {
+    const profile = await GetProfile();
+    AppState.profile = profile;
+    populateProfileForm(profile);
+    updateQuarantineButton();
+    await applyAppearance(profile.appearance);
+  } catch (error) {
+    logError("reloading imported settings failed:", error);
+  }
+}
+
+// applyReloadedSettings takes settings the backend reloaded after the
+// settings file was changed by another program. An open Settings dialog keeps
+// its edits, which would replace the reloaded settings when saved.
+async function applyReloadedSettings(profile) {
+  AppState.profile = profile;
+  updateQuarantineButton();
+  await applyAppearance(profile.appearance);
+  if (byId("settingsDialog").open) {
+    byId("settingsError").textContent = "The settings file was changed elsewhere and reloaded. Saving replaces those changes with this form.";
+    return;
+  }
+  const topbarBottom = byId("topbar").getBoundingClientRect().bottom || 38;
+  showToastAt(window.innerWidth / 2 - 100, topbarBottom, "Settings reloaded from file", 2000);
+}
+
+function reportRejectedSettings(message) {
+  showErrorToast(`Settings file change ignored: ${message}`);
+}
+
 function useDefaultConfigPath() {
   if (!defaultSettingsPath) return;
   const input = byId("settingsConfigPath");
@@ -396,7 +490,12 @@ export function initSettings(options) {
     button.addEventListener("click", () => clearControlBinding(button));
   });
   addControlEventListeners(captureControlBinding, { capture: true });
-  byId("settingsPalette").addEventListener("change", updateAppearanceFormOutputs);
+  initPalettes({ onChange: updateAppearanceFormOutputs });
+  byId("settingsDeleteStrategy").addEventListener("change", updateQuarantineFields);
+  initScanProfiles();
+  initSettingsBundle({ onImported: reloadImportedSettings });
+  EventsOn("settings-reloaded", applyReloadedSettings);
+  EventsOn("settings-reload-failed", reportRejectedSettings);
   byId("settingsZoomFactor").addEventListener("input", updateAppearanceFormOutputs);
   byId("settingsCornerRadius").addEventListener("input", updateAppearanceFormOutputs);
   byId("settingsReliefStrength").addEventListener("input", updateAppearanceFormOutputs);
@@ -25,6 +25,9 @@ export const AppState = {
   parentRectIndexes: new Int32Array(),
   selectedRectIndex: null,
   selectedNodeId: null,
+  // Additional nodes picked with Ctrl/Cmd+click for batch actions. The
+  // primary selection above is always a member while the set is non-empty.
+  selectedNodeIds: new Set(),
   profile: null,
   defaultProfile: null,
 
@@ -33,18 +36,17 @@ export const AppState = {
   pickingFolderDialogIsOpen: false,
 };
 
-export const PALETTES = Object.freeze({
-  default: ["#ff9b85", "#ffbe76", "#ffe066", "#7bed9f", "#70d6ff", "#a29bfe", "#dfe4ea"],
-  legacy: ["#ff7f7f", "#ffbf7f", "#ffff00", "#7fff7f", "#7fffff", "#bfbfff", "#bfbfbf", "#ff7fff"],
-  single: ["#9fc5d8"],
-  duotone: ["#f2c078", "#78a6c8"],
-  tricolor: ["#e8846b", "#e3bf62", "#78a88b"],
-  playful: ["#ff6b6b", "#ffd93d", "#6bcb77", "#4d96ff", "#c77dff", "#ff8fab", "#72efdd"],
-  monochrome: ["#f0f0f0", "#dedede", "#cccccc", "#bababa", "#a8a8a8", "#969696", "#848484"],
-  earth: ["#d9c7a6", "#c3a982", "#ad8b63", "#96a17b", "#7f9168", "#b98268", "#8f7559"],
-  ocean: ["#c6e8e5", "#a8dadc", "#8ecfd1", "#73c0c5", "#78b7d0", "#91a8d0", "#a7c4bc"],
-  retro: ["#e49a78", "#e5bd63", "#a8b47d", "#78a0a8", "#a58aa8", "#c9ae88", "#87949a"],
-});
+// Built-in palettes come from Go via GetPalettes, so every renderer uses the
+// same colors. The fallback only covers drawing before they have loaded.
+const FALLBACK_PALETTE = Object.freeze(["#ff9b85", "#ffbe76", "#ffe066", "#7bed9f", "#70d6ff", "#a29bfe", "#dfe4ea"]);
+export const PALETTES = new Map();
+
+export function setBuiltinPalettes(palettes) {
+  PALETTES.clear();
+  for (const palette of palettes || []) {
+    if (palette.builtin) PALETTES.set(palette.id, palette);
+  }
+}
 
 // These are safe boot values only. User-facing defaults come from Go via
 // GetDefaultProfile, so Restore defaults and first launch cannot diverge.
@@ -55,6 +57,8 @@ export const AppearanceState = {
   reliefStrength: 0,
   hoverBrightness: 0,
   rollOverBoxes: false,
+  colorTrashByAge: false,
+  customPalettes: [],
 };
 
 export const FONT_SIZE = 10;
@@ -68,7 +72,14 @@ export function setProfiles(profile, defaultProfile) {
   if (defaultProfile) AppState.defaultProfile = defaultProfile;
 }
 
+// paletteColors returns the colors of the built-in or custom palette id,
+// falling back to the default palette.
+export function paletteColors(id, customPalettes = AppearanceState.customPalettes) {
+  const palette = (customPalettes || []).find(custom => custom.id === id) || PALETTES.get(id);
+  if (palette?.colors?.length) return palette.colors;
+  return PALETTES.get("default")?.colors || FALLBACK_PALETTE;
+}
+
 export function activePalette() {
-  const palette = PALETTES[AppearanceState.palette];
-  return Array.isArray(palette) && palette.length > 0 ? palette : PALETTES.default;
+  return paletteColors(AppearanceState.palette);
 }
@@ -0,0 +1,125 @@
+import { ListTrashItems } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { formatModTime, formatSize } from "./format.js";
+import { showErrorToast } from "./notifications.js";
+
+let requestAction = () => {};
+let listedItems = [];
+const selectedPaths = new Set();
+
+function selectedItems() {
+  return listedItems.filter(item => selectedPaths.has(item.path));
+}
+
+function totalSize(items) {
+  return items.reduce((total, item) => total + item.size, 0);
+}
+
+function purgeCandidates(days) {
+  const cutoff = Date.now() / 1000 - days * 86400;
+  return listedItems.filter(item => item.deletedAt && item.deletedAt < cutoff);
+}
+
+function purgeDays() {
+  const days = Number.parseInt(byId("trashPurgeDays").value, 10);
+  return Number.isFinite(days) && days >= 0 ? days : null;
+}
+
+function updateTrashBrowserControls() {
+  const selected = selectedItems();
+  const restorable = selected.length > 0 && selected.every(item => item.originalPath);
+  byId("restoreTrashItemsButton").disabled = !restorable;
+  byId("deleteTrashItemsButton").disabled = selected.length === 0;
+  const days = purgeDays();
+  byId("purgeTrashButton").disabled = days === null || purgeCandidates(days).length === 0;
+  const count = listedItems.length;
+  let summary = count
+    ? `${count.toLocaleString()} ${count === 1 ? "item" : "items"}, ${formatSize(totalSize(listedItems))} in Trash`
+    : "Trash is empty";
+  if (selected.length) summary += `\n${selected.length.toLocaleString()} selected, ${formatSize(totalSize(selected))}`;
+  byId("trashBrowserSummary").textContent = summary;
+}
+
+function trashListItem(item) {
+  const row = document.createElement("li");
+  row.className = "trash-browser-row";
+  const checkbox = document.createElement("input");
+  checkbox.type = "checkbox";
+  checkbox.checked = selectedPaths.has(item.path);
+  checkbox.addEventListener("change", () => {
+    if (checkbox.checked) selectedPaths.add(item.path);
+    else selectedPaths.delete(item.path);
+    updateTrashBrowserControls();
+  });
+  const name = document.createElement("span");
+  name.className = "small-files-name";
+  name.textContent = item.originalPath || item.path;
+  name.title = item.originalPath ? `${item.originalPath}\nIn ${item.trashRoot}` : `${item.path}\nNo original location is recorded`;
+  const size = document.createElement("span");
+  size.className = "small-files-size";
+  size.textContent = formatSize(item.size);
+  const date = document.createElement("span");
+  date.className = "small-files-date";
+  date.textContent = item.deletedAt ? formatModTime(item.deletedAt) : "Unknown date";
+  row.append(checkbox, name, size, date);
+  return row;
+}
+
+function renderTrashBrowser(items) {
+  listedItems = items || [];
+  const listed = new Set(listedItems.map(item => item.path));
+  for (const path of selectedPaths) {
+    if (!listed.has(path)) selectedPaths.delete(path);
+  }
+  byId("trashBrowserList").replaceChildren(...listedItems.map(trashListItem));
+  byId("trashBrowserList").hidden = listedItems.length === 0;
+  updateTrashBrowserControls();
+}
+
+async function refreshTrashBrowser() {
+  byId("trashBrowserSummary").textContent = "Reading Trash...";
+  try {
+    renderTrashBrowser(await ListTrashItems());
+  } catch (error) {
+    renderTrashBrowser([]);
+    showErrorToast(error);
+  }
+}
+
+async function showTrashBrowser() {
+  const dialog = byId("trashBrowserDialog");
+  if (!dialog.open) dialog.showModal();
+  await refreshTrashBrowser();
+}
+
+function closeTrashBrowser() {
+  const dialog = byId("trashBrowserDialog");
+  if (dialog.open) dialog.close();
+}
+
+function requestSelected(kind) {
+  const items = selectedItems();
+  if (!items.length) return;
+  closeTrashBrowser();
+  requestAction({ kind, paths: items.map(item => item.path), size: totalSize(items) }, showTrashBrowser);
+}
+
+export function initTrashBrowser(options) {
+  requestAction = options.requestAction;
+  byId("trashBrowserButton").addEventListener("click", showTrashBrowser);
+  byId("closeTrashBrowserButton").addEventListener("click", closeTrashBrowser);
+  byId("trashBrowserDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeTrashBrowser();
+  });
+  byId("trashPurgeDays").addEventListener("input", updateTrashBrowserControls);
+  byId("restoreTrashItemsButton").addEventListener("click", () => requestSelected("restore"));
+  byId("deleteTrashItemsButton").addEventListener("click", () => requestSelected("delete"));
+  byId("purgeTrashButton").addEventListener("click", () => {
+    const days = purgeDays();
+    const items = days === null ? [] : purgeCandidates(days);
+    if (!items.length) return;
+    closeTrashBrowser();
+    requestAction({ kind: "purge", days, paths: items.map(item => item.path), size: totalSize(items) }, showTrashBrowser);
+  });
+}
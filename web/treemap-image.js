@@ -0,0 +1,61 @@
+import { ExportTreemapImage } from "./wailsjs/go/main/App.js";
+import { byId } from "./dom.js";
+import { AppState } from "./state.js";
+import { mousePosition, showToastAt } from "./notifications.js";
+
+// Images are laid out like the window at any size. Borders and labels keep
+// their proportion to the width, so a 2x image looks like a HiDPI screenshot.
+function imageSize() {
+  return {
+    width: Math.round(Number(byId("treemapImageWidth").value)),
+    height: Math.round(Number(byId("treemapImageHeight").value)),
+  };
+}
+
+function setImageSize(factor) {
+  byId("treemapImageWidth").value = Math.round(AppState.colorCanvas.width * factor);
+  byId("treemapImageHeight").value = Math.round(AppState.colorCanvas.height * factor);
+}
+
+function showTreemapImage() {
+  if (AppState.node_id == null) return;
+  setImageSize(2);
+  byId("treemapImageError").textContent = "";
+  const dialog = byId("treemapImageDialog");
+  if (!dialog.open) dialog.showModal();
+}
+
+function closeTreemapImage() {
+  const dialog = byId("treemapImageDialog");
+  if (dialog.open) dialog.close();
+}
+
+async function saveTreemapImage() {
+  const error = byId("treemapImageError");
+  error.textContent = "";
+  const { width, height } = imageSize();
+  const scale = AppState.scale * width / AppState.colorCanvas.width;
+  let path;
+  try {
+    path = await ExportTreemapImage(AppState.node_id, width, height, scale);
+  } catch (saveError) {
+    error.textContent = String(saveError || "Unable to save the treemap image.");
+    return;
+  }
+  if (!path) return;
+  closeTreemapImage();
+  showToastAt(mousePosition.x, mousePosition.y, `Treemap saved to ${path}`, 2400);
+}
+
+export function initTreemapImage() {
+  byId("treemapImageButton").addEventListener("click", showTreemapImage);
+  byId("cancelTreemapImageButton").addEventListener("click", closeTreemapImage);
+  byId("saveTreemapImageButton").addEventListener("click", saveTreemapImage);
+  byId("treemapImageDialog").addEventListener("cancel", event => {
+    event.preventDefault();
+    closeTreemapImage();
+  });
+  for (const button of byId("treemapImageDialog").querySelectorAll("[data-image-factor]")) {
+    button.addEventListener("click", () => setImageSize(Number(button.dataset.imageFactor)));
+  }
+}
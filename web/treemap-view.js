@@ -1,5 +1,5 @@
 import { Layout } from "./wailsjs/go/main/App.js";
-import { hideContextMenu, showContextMenu } from "./file-actions.js";
+import { hideContextMenu, showContextMenu, showSmallFiles } from "./file-actions.js";
 import { debounce, formatCompactSize, formatCount, formatModTime, formatSize } from "./format.js";
 import { navigateToSelected, updateNavButtons } from "./navigation.js";
 import { hideRectToast, initNotifications } from "./notifications.js";
@@ -235,6 +235,22 @@ function blendHexColor(hex, target, amount) {
   return `rgb(${blended[0]}, ${blended[1]}, ${blended[2]})`;
 }
 
+// Trash contents deleted today are green; the colour moves through yellow to
+// red on a logarithmic scale and stops changing after TRASH_AGE_MAX_DAYS.
+const TRASH_AGE_COLORS = [[0x9b, 0xe3, 0x9b], [0xf3, 0xd8, 0x6b], [0xe8, 0x84, 0x6b]];
+const TRASH_AGE_MAX_DAYS = 365;
+
+function trashAgeColor(deletedAt) {
+  const days = Math.max(0, (Date.now() / 1000 - deletedAt) / 86400);
+  const position = Math.min(1, Math.log1p(days) / Math.log1p(TRASH_AGE_MAX_DAYS)) * (TRASH_AGE_COLORS.length - 1);
+  const index = Math.min(TRASH_AGE_COLORS.length - 2, Math.floor(position));
+  const amount = position - index;
+  return "#" + TRASH_AGE_COLORS[index].map((channel, offset) => {
+    const value = Math.round(channel + (TRASH_AGE_COLORS[index + 1][offset] - channel) * amount);
+    return value.toString(16).padStart(2, "0");
+  }).join("");
+}
+
 function drawRectRelief(ctx, rect, fillColor, strokeWidth) {
   const brightness = 1 + AppearanceState.reliefStrength;
   if (brightness === 1.0) return;
@@ -277,10 +293,11 @@ function drawRectRelief(ctx, rect, fillColor, strokeWidth) {
 }
 
 function drawRect(rect, writeId, ctx, rectIndex) {
-  const isSelected = AppState.selectedNodeId == rect.node_id;
+  const isPrimary = AppState.selectedNodeId == rect.node_id;
+  const isSelected = isPrimary || AppState.selectedNodeIds.has(rect.node_id);
   const isRoot = rect.parent_id == null;
   const palette = activePalette();
-  if (isSelected && rectIndex >= 0) AppState.selectedRectIndex = rectIndex;
+  if (isPrimary && rectIndex >= 0) AppState.selectedRectIndex = rectIndex;
 
   //  scaled UI constants  
   const PAD          = pxI(4);            
@@ -294,7 +311,10 @@ function drawRect(rect, writeId, ctx, rectIndex) {
   // fill
   const fillColor = isSelected ? "#000000"
     : (rect.is_free_space || isRoot ? "#fff"
-      : (rect.is_small_files ? "#e6dac5" : palette[(rect.depth || 0) % palette.length]));
+      : (rect.is_small_files ? "#e6dac5"
+        : rect.is_unaccounted ? "#d6d6d6"
+          : AppearanceState.colorTrashByAge && rect.trash_deleted_at ? trashAgeColor(rect.trash_deleted_at)
+            : palette[(rect.depth || 0) % palette.length]));
   ctx.fillStyle = fillColor;
   fillRoundedRect(ctx, rect.x, rect.y, rect.w, rect.h);
 
@@ -344,12 +364,21 @@ function drawRect(rect, writeId, ctx, rectIndex) {
       { text: sizeStr, ellipsize: false },
     ], fontBounds, rect);
   }
+  else if (rect.is_unaccounted) {
+    writeCenteredLinesInRect(ctx, [
+      { text: rect.disk_path ? "Rest of volume" : "Unaccounted", ellipsize: false },
+      { text: sizeStr, ellipsize: false },
+    ], fontBounds, rect);
+  }
   else if (rect.is_folder) {
     if (rect.w > FOLDER_W_MIN && rect.h > FOLDER_H_MIN) {
-      let display = `${anonymize ? "A folder" : rect.name} (${sizeStr})`;
+      const mountLabel = rect.mount ? ` [${rect.mount.fsType || "mount"}${rect.mount_collapsed ? ", collapsed" : ""}]` : "";
+      let display = `${anonymize ? "A folder" : rect.name}${mountLabel} (${sizeStr})`;
       if (isRoot && rect.disk_total > 0) {
         const used = Math.max(0, rect.disk_total - (rect.disk_free || 0));
-        display = `${rect.name} (${formatSize(used)} / ${formatSize(rect.disk_total)})`;
+        display = rect.disk_path
+          ? `${rect.name} (${sizeStr}; volume ${formatSize(used)} / ${formatSize(rect.disk_total)})`
+          : `${rect.name} (${formatSize(used)} / ${formatSize(rect.disk_total)})`;
       }
       const label = ellipsize(ctx, display, rect.w - PAD*2);
       const y = Math.round(rect.y + PAD + fontBounds.ascent);
@@ -373,7 +402,7 @@ export function getSelectedRect() {
 }
 
 export function isPassiveRect(rect) {
-  return !!(rect?.is_free_space || rect?.is_small_files);
+  return !!(rect?.is_free_space || rect?.is_small_files || rect?.is_unaccounted);
 }
 
 export function selectRectByIndex(rectIndex, dontDeselect=false) {
@@ -417,6 +446,46 @@ export function selectRectByIndex(rectIndex, dontDeselect=false) {
   reDrawRectByIndex(rectIndex);
 }
 
+function reDrawRectByNodeId(nodeId) {
+  const index = AppState.rects?.findIndex(rect => rect.node_id === nodeId) ?? -1;
+  if (index >= 0) reDrawRectByIndex(index);
+}
+
+export function clearMultiSelection() {
+  if (!AppState.selectedNodeIds.size) return;
+  const previous = [...AppState.selectedNodeIds];
+  AppState.selectedNodeIds.clear();
+  for (const nodeId of previous) {
+    if (nodeId !== AppState.selectedNodeId) reDrawRectByNodeId(nodeId);
+  }
+}
+
+// toggleRectInSelection adds or removes a rect from the batch selection,
+// starting from the current single selection when there is one.
+export function toggleRectInSelection(rectIndex) {
+  const rect = AppState.rects?.[rectIndex];
+  if (!rect || isPassiveRect(rect) || rect.parent_id == null) return;
+  const selection = AppState.selectedNodeIds;
+  if (!selection.size && AppState.selectedNodeId != null) selection.add(AppState.selectedNodeId);
+  const previousPrimary = AppState.selectedRectIndex;
+  if (selection.has(rect.node_id)) {
+    selection.delete(rect.node_id);
+    if (AppState.selectedNodeId === rect.node_id) {
+      const remaining = [...selection].pop();
+      AppState.selectedNodeId = remaining ?? null;
+      AppState.selectedRectIndex = null;
+      if (remaining != null) reDrawRectByNodeId(remaining);
+    }
+  } else {
+    selection.add(rect.node_id);
+    AppState.selectedNodeId = rect.node_id;
+    AppState.selectedRectIndex = rectIndex;
+    if (previousPrimary != null) reDrawRectByIndex(previousPrimary);
+  }
+  if (selection.size <= 1) selection.clear();
+  reDrawRectByIndex(rectIndex);
+}
+
 function reDrawRectByIndex(idx) {
   const r = AppState.rects?.[idx];
   if (!r || r.w <= 0 || r.h <= 0) return;
@@ -526,13 +595,20 @@ export function initTreemapView() {
 
   AppState.colorCanvas.addEventListener("click", event => {
     const { x, y } = getCanvasCoords(event);
-    selectRectByIndex(rectIndexAtPoint(x, y));
+    if (event.ctrlKey || event.metaKey) {
+      toggleRectInSelection(rectIndexAtPoint(x, y));
+    } else {
+      clearMultiSelection();
+      selectRectByIndex(rectIndexAtPoint(x, y));
+    }
     hideContextMenu();
   });
   AppState.colorCanvas.addEventListener("contextmenu", event => {
     event.preventDefault();
     const { x, y } = getCanvasCoords(event);
-    selectRectByIndex(rectIndexAtPoint(x, y), true);
+    const rectIndex = rectIndexAtPoint(x, y);
+    if (!AppState.selectedNodeIds.has(AppState.rects[rectIndex]?.node_id)) clearMultiSelection();
+    selectRectByIndex(rectIndex, true);
     const rect = getSelectedRect();
     if (rect && !isPassiveRect(rect)) showContextMenu(event.clientX, event.clientY);
     else hideContextMenu();
@@ -541,6 +617,10 @@ export function initTreemapView() {
     const { x, y } = getCanvasCoords(event);
     const rectIndex = rectIndexAtPoint(x, y);
     const rect = AppState.rects[rectIndex];
+    if (rect?.is_small_files) {
+      showSmallFiles(rect);
+      return;
+    }
     if (!rect || isPassiveRect(rect)) return;
     selectRectByIndex(rectIndex);
     navigateToSelected();